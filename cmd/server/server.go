@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	nethttp "net/http"
 
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -11,17 +12,31 @@ import (
 	"github.com/todo-enjoers/backend_v1/internal/config"
 	"github.com/todo-enjoers/backend_v1/internal/controller"
 	"github.com/todo-enjoers/backend_v1/internal/controller/http"
+	"github.com/todo-enjoers/backend_v1/internal/events"
+	"github.com/todo-enjoers/backend_v1/internal/outbox"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob/local"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob/s3"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
 	"github.com/todo-enjoers/backend_v1/internal/pkg/tern/migrator"
 	"github.com/todo-enjoers/backend_v1/internal/pkg/token"
 	"github.com/todo-enjoers/backend_v1/internal/pkg/token/jwt"
+	"github.com/todo-enjoers/backend_v1/internal/purger"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/memory"
 	"github.com/todo-enjoers/backend_v1/internal/storage/pgx"
+	sqlitestorage "github.com/todo-enjoers/backend_v1/internal/storage/sqlite"
+	"github.com/todo-enjoers/backend_v1/internal/webhook"
 	"github.com/todo-enjoers/backend_v1/migrations"
+	sqlitemigrations "github.com/todo-enjoers/backend_v1/migrations/sqlite"
 	"github.com/todo-enjoers/backend_v1/pkg/postgres"
+	"github.com/todo-enjoers/backend_v1/pkg/sqlite"
 )
 
 var (
-	ErrNilReference = errors.New("nil reference")
+	ErrNilReference   = errors.New("nil reference")
+	ErrBlobBackend    = errors.New("unknown attachments backend")
+	ErrStorageBackend = errors.New("unknown storage backend")
 )
 
 func main() {
@@ -46,8 +61,15 @@ func migrate(log *zap.Logger, cfg *config.Config) error {
 	if cfg == nil {
 		return ErrNilReference
 	}
+	fsys := migrations.Migrations
+	switch storageBackend(cfg) {
+	case "memory":
+		return nil
+	case "sqlite":
+		fsys = sqlitemigrations.Migrations
+	}
 	ctx := context.Background()
-	m, err := migrator.New(ctx, cfg, log, migrations.Migrations)
+	m, err := migrator.New(ctx, cfg, log, fsys)
 	if err != nil {
 		return err
 	}
@@ -55,21 +77,89 @@ func migrate(log *zap.Logger, cfg *config.Config) error {
 	return m.MigrateUp(ctx)
 }
 
+// newStorage opens the storage backend configured in cfg together with the
+// notifier of its changes
+func newStorage(cfg *config.Config, log *zap.Logger) (storage.Interface, events.Notifier, error) {
+	if cfg == nil {
+		return nil, nil, ErrNilReference
+	}
+	switch storageBackend(cfg) {
+	case "postgres":
+		pool, err := postgres.New(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		replicas, err := postgres.NewReplicas(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		// notifications are LISTENed to on the primary, replicas don't
+		// deliver NOTIFY
+		store, err := pgx.NewWithReplicas(pool, replicas, cfg.Postgres.Sticky(), recurrence.SystemClock{}, log, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, pgx.NewNotifier(pool, log), nil
+	case "sqlite":
+		db, err := sqlite.New(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		store := sqlitestorage.New(db, log)
+		return store, sqlitestorage.NewNotifier(store), nil
+	case "memory":
+		store := memory.New(log)
+		return store, memory.NewNotifier(store), nil
+	default:
+		return nil, nil, ErrStorageBackend
+	}
+}
+
+func storageBackend(cfg *config.Config) string {
+	if cfg.Storage == nil || cfg.Storage.Backend == "" {
+		return "postgres"
+	}
+	return cfg.Storage.Backend
+}
+
+// newBlobStore picks the attachments backend configured in cfg
+func newBlobStore(cfg *config.Config, log *zap.Logger) (blob.BlobStore, error) {
+	if cfg == nil {
+		return nil, ErrNilReference
+	}
+	switch cfg.Attachments.Backend {
+	case "", "local":
+		return local.New(cfg.Attachments.LocalPath, log)
+	case "s3":
+		return s3.New(cfg.Attachments.S3, &nethttp.Client{Timeout: cfg.Attachments.S3.Timeout()}, log)
+	default:
+		return nil, ErrBlobBackend
+	}
+}
+
 func CreateApp() fx.Option {
 	return fx.Options(
 		fx.WithLogger(createLogger),
 		fx.Provide(
 			newLogger,
 			config.New,
-			postgres.New,
+			newStorage,
+			newBlobStore,
+			purger.New,
+			events.New,
+			webhook.New,
+			outbox.New,
 
 			fx.Annotate(http.New, fx.As(new(controller.Controller))),
-			fx.Annotate(pgx.New, fx.As(new(storage.Interface))),
 			fx.Annotate(jwt.NewProvider, fx.As(new(token.Provider))),
 		),
 		fx.Invoke(
 			migrate,
 			controller.RunControllerFx,
+			purger.RunPurgerFx,
+			events.RunBrokerFx,
+			webhook.RunDispatcherFx,
+			outbox.RunRelayFx,
 		),
 	)
 }
@@ -11,6 +11,7 @@ require (
 	github.com/jackc/tern/v2 v2.3.2
 	github.com/labstack/echo/v4 v4.13.3
 	github.com/labstack/gommon v0.4.2
+	github.com/mattn/go-sqlite3 v1.14.24
 	go.uber.org/fx v1.23.0
 	go.uber.org/multierr v1.11.0
 	go.uber.org/zap v1.27.0
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+type Attachments struct {
+	// Backend is either "local" or "s3"
+	Backend      string `config:"backend" toml:"backend"`
+	LocalPath    string `config:"local-path" toml:"local_path"`
+	MaxSize      int64  `config:"max-size" toml:"max_size"`
+	AllowedTypes string `config:"allowed-types" toml:"allowed_types"`
+	S3           *S3    `config:"S3" toml:"S3"`
+}
+
+type S3 struct {
+	Endpoint  string `config:"endpoint" toml:"endpoint"`
+	Region    string `config:"region" toml:"region"`
+	Bucket    string `config:"bucket" toml:"bucket"`
+	AccessKey string `config:"access-key" toml:"access_key"`
+	SecretKey string `config:"secret-key" toml:"secret_key"`
+	// TimeoutSeconds bounds a whole request to S3, reading the body of a
+	// download included
+	TimeoutSeconds int `config:"timeout-seconds" toml:"timeout_seconds"`
+}
+
+func (s S3) Timeout() time.Duration {
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+// IsAllowed reports whether the media type is in the comma separated
+// AllowedTypes list, an empty list allows everything
+func (a *Attachments) IsAllowed(mediaType string) bool {
+	if strings.TrimSpace(a.AllowedTypes) == "" {
+		return true
+	}
+	for _, t := range strings.Split(a.AllowedTypes, ",") {
+		if strings.EqualFold(strings.TrimSpace(t), mediaType) {
+			return true
+		}
+	}
+	return false
+}
@@ -18,9 +18,15 @@ func init() {
 }
 
 type Config struct {
-	JWT        *JWT            `config:"JWT" toml:"JWT"`
-	Controller *Controller     `config:"Controller" toml:"Controller"`
-	Postgres   *PostgresConfig `config:"Postgres" toml:"Postgres"`
+	JWT         *JWT            `config:"JWT" toml:"JWT"`
+	Controller  *Controller     `config:"Controller" toml:"Controller"`
+	Postgres    *PostgresConfig `config:"Postgres" toml:"Postgres"`
+	Storage     *Storage        `config:"Storage" toml:"Storage"`
+	Attachments *Attachments    `config:"Attachments" toml:"Attachments"`
+	Trash       *Trash          `config:"Trash" toml:"Trash"`
+	Idempotency *Idempotency    `config:"Idempotency" toml:"Idempotency"`
+	Webhooks    *Webhooks       `config:"Webhooks" toml:"Webhooks"`
+	Outbox      *Outbox         `config:"Outbox" toml:"Outbox"`
 }
 
 func New(log *zap.Logger) (*Config, error) {
@@ -39,11 +45,18 @@ func New(log *zap.Logger) (*Config, error) {
 			Port: 8080,
 		},
 		Postgres: &PostgresConfig{
-			Host:     "localhost",
-			Port:     5432,
-			User:     "postgres",
-			Password: "postgres",
-			Database: "postgres",
+			Host:          "localhost",
+			Port:          5432,
+			User:          "postgres",
+			Password:      "postgres",
+			Database:      "postgres",
+			StickySeconds: 5,
+		},
+		Storage: &Storage{
+			Backend: "postgres",
+			SQLite: &SQLiteConfig{
+				Path: path.Join(wd, "data", "todoer.db"),
+			},
 		},
 		JWT: &JWT{
 			AccessTokenLifeTime:  20,
@@ -51,6 +64,41 @@ func New(log *zap.Logger) (*Config, error) {
 			PublicKeyPath:        path.Join(wd, "certs", "public.pem"),
 			PrivateKeyPath:       path.Join(wd, "certs", "private.pem"),
 		},
+		Trash: &Trash{
+			RetentionHours:       30 * 24,
+			PurgeIntervalMinutes: 60,
+		},
+		Idempotency: &Idempotency{
+			TTLHours: 24,
+		},
+		Webhooks: &Webhooks{
+			PollIntervalSeconds: 5,
+			TimeoutSeconds:      10,
+			BatchSize:           20,
+			MaxAttempts:         8,
+			BackoffSeconds:      30,
+			MaxBackoffMinutes:   6 * 60,
+		},
+		Outbox: &Outbox{
+			PollIntervalSeconds: 1,
+			BatchSize:           100,
+			BackoffSeconds:      5,
+			MaxBackoffMinutes:   10,
+			RetentionHours:      7 * 24,
+			Sinks:               "log",
+			NATSSubject:         "todoer.events",
+			TimeoutSeconds:      10,
+		},
+		Attachments: &Attachments{
+			Backend:      "local",
+			LocalPath:    path.Join(wd, "data", "attachments"),
+			MaxSize:      10 << 20,
+			AllowedTypes: "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,application/zip,application/x-gzip",
+			S3: &S3{
+				Region:         "us-east-1",
+				TimeoutSeconds: 120,
+			},
+		},
 	}
 
 	loader := confita.NewLoader(
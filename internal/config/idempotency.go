@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type Idempotency struct {
+	// TTLHours is how long a stored response is replayed for its key
+	TTLHours int `config:"ttl_hours" toml:"ttl_hours"`
+}
+
+func (i Idempotency) TTL() time.Duration {
+	return time.Duration(i.TTLHours) * time.Hour
+}
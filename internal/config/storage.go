@@ -0,0 +1,28 @@
+package config
+
+import "net/url"
+
+type Storage struct {
+	// Backend is "postgres", "sqlite" or "memory". The memory backend keeps
+	// nothing across restarts
+	Backend string        `config:"backend" toml:"backend"`
+	SQLite  *SQLiteConfig `config:"SQLite" toml:"SQLite"`
+}
+
+type SQLiteConfig struct {
+	// Path is the database file, it is created when missing
+	Path string `config:"path" toml:"path"`
+}
+
+// GetDSN returns the data source name of the database file for the sqlite3
+// driver. Foreign keys are enforced and transactions take the write lock
+// when they begin, so that concurrent writers wait for each other instead of
+// failing on commit
+func (cfg *SQLiteConfig) GetDSN() string {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_txlock", "immediate")
+	return "file:" + cfg.Path + "?" + params.Encode()
+}
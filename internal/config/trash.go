@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+type Trash struct {
+	// RetentionHours is how long deleted projects and todos stay restorable
+	RetentionHours       int `config:"retention_hours" toml:"retention_hours"`
+	PurgeIntervalMinutes int `config:"purge_interval_minutes" toml:"purge_interval_minutes"`
+}
+
+func (t Trash) Retention() time.Duration {
+	return time.Duration(t.RetentionHours) * time.Hour
+}
+
+func (t Trash) PurgeInterval() time.Duration {
+	return time.Duration(t.PurgeIntervalMinutes) * time.Minute
+}
@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+type Webhooks struct {
+	// PollIntervalSeconds is how often due deliveries are looked for, zero
+	// disables delivering
+	PollIntervalSeconds int `config:"poll_interval_seconds" toml:"poll_interval_seconds"`
+	TimeoutSeconds      int `config:"timeout_seconds" toml:"timeout_seconds"`
+	BatchSize           int `config:"batch_size" toml:"batch_size"`
+	// A failed delivery is retried after BackoffSeconds, doubling up to
+	// MaxBackoffMinutes, until it made MaxAttempts attempts
+	MaxAttempts       int `config:"max_attempts" toml:"max_attempts"`
+	BackoffSeconds    int `config:"backoff_seconds" toml:"backoff_seconds"`
+	MaxBackoffMinutes int `config:"max_backoff_minutes" toml:"max_backoff_minutes"`
+	// AllowPrivateTargets lets webhooks reach loopback, private and link-local
+	// addresses, e.g. receivers on the same host in development
+	AllowPrivateTargets bool `config:"allow_private_targets" toml:"allow_private_targets"`
+}
+
+func (w Webhooks) PollInterval() time.Duration {
+	return time.Duration(w.PollIntervalSeconds) * time.Second
+}
+
+func (w Webhooks) Timeout() time.Duration {
+	return time.Duration(w.TimeoutSeconds) * time.Second
+}
+
+// Backoff returns the delay before the attempt that follows attempts failed
+// ones
+func (w Webhooks) Backoff(attempts int) time.Duration {
+	backoff := time.Duration(w.BackoffSeconds) * time.Second
+	limit := time.Duration(w.MaxBackoffMinutes) * time.Minute
+	for i := 1; i < attempts && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleGetProjectActivity(c echo.Context) error {
+	var (
+		activities    []model.ActivityDTO
+		total         int
+		limit, offset int
+		projectID     uuid.UUID
+		userID        uuid.UUID
+		err           error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetProjectActivity: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	limit, offset, err = getPagination(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	if _, err = ctrl.store.Project().GetByID(c.Request().Context(), projectID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	activities, total, err = ctrl.store.Activity().GetByProject(c.Request().Context(), projectID, limit, offset)
+	if err != nil {
+		ctrl.log.Error("error while getting activity from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	return c.JSON(http.StatusOK, model.ActivitiesResponse{
+		Activities: activities,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
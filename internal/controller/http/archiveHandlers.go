@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleArchiveProject(c echo.Context) error {
+	return ctrl.archiveProject(c, true)
+}
+
+func (ctrl *Controller) HandleUnarchiveProject(c echo.Context) error {
+	return ctrl.archiveProject(c, false)
+}
+
+func (ctrl *Controller) HandleArchiveTodo(c echo.Context) error {
+	return ctrl.archiveTodo(c, true)
+}
+
+func (ctrl *Controller) HandleUnarchiveTodo(c echo.Context) error {
+	return ctrl.archiveTodo(c, false)
+}
+
+func (ctrl *Controller) archiveProject(c echo.Context, archived bool) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("archiveProject: logged in", zap.String("user_id", userID.String()), zap.Bool("archived", archived))
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = ctrl.store.Project().Archive(c.Request().Context(), projectID, archived); err != nil {
+		return ctrl.archiveErrorResponse(c, err)
+	}
+
+	project, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID)
+	if err != nil {
+		return ctrl.archiveErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, project)
+}
+
+func (ctrl *Controller) archiveTodo(c echo.Context, archived bool) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("archiveTodo: logged in", zap.String("user_id", userID.String()), zap.Bool("archived", archived))
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	todo, err := ctrl.store.Todo().Archive(c.Request().Context(), todoID, archived)
+	if err != nil {
+		return ctrl.archiveErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, todo)
+}
+
+func (ctrl *Controller) archiveErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errPkg.ErrNotFound) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+	// the todo lost its column and the project has no column to take it
+	if errors.Is(err, errPkg.ErrColumnTarget) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrColumnTarget.Error(),
+			},
+		)
+	}
+	ctrl.log.Error("error while archiving", zap.Error(err))
+	return c.JSON(
+		http.StatusInternalServerError,
+		model.ErrorResponse{
+			Error: errPkg.ErrInternalServer.Error(),
+		},
+	)
+}
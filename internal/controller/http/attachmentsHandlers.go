@@ -0,0 +1,339 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// sniffLen is the number of leading bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// HandleUploadAttachment streams the "file" part of a multipart form to the
+// blob store. The content type is detected from the content itself, the size
+// is checked while streaming, so the file is never held in memory
+func (ctrl *Controller) HandleUploadAttachment(c echo.Context) error {
+	var (
+		todoID uuid.UUID
+		userID uuid.UUID
+		part   *multipart.Part
+		err    error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleUploadAttachment: logged in", zap.String("user_id", userID.String()))
+
+	todoID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if _, err = ctrl.store.Todo().GetByID(c.Request().Context(), todoID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	part, err = nextFilePart(c.Request())
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrNoFile.Error(),
+			},
+		)
+	}
+	defer part.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !ctrl.cfg.Attachments.IsAllowed(mediaType) {
+		return c.JSON(
+			http.StatusUnsupportedMediaType,
+			model.ErrorResponse{
+				Error: errPkg.ErrFileType.Error(),
+			},
+		)
+	}
+
+	attachment := &model.AttachmentDTO{
+		ID:          uuid.New(),
+		TodoID:      todoID,
+		UploadedBy:  userID,
+		FileName:    part.FileName(),
+		ContentType: contentType,
+	}
+	if attachment.FileName == "" {
+		attachment.FileName = attachment.ID.String()
+	}
+	attachment.StorageKey = "todos/" + todoID.String() + "/" + attachment.ID.String()
+
+	content := &blob.LimitedReader{
+		R:   io.MultiReader(bytes.NewReader(head), part),
+		Max: ctrl.cfg.Attachments.MaxSize,
+	}
+	err = ctrl.blobs.Put(c.Request().Context(), attachment.StorageKey, content, -1, contentType)
+	if errors.Is(err, blob.ErrTooLarge) {
+		return c.JSON(
+			http.StatusRequestEntityTooLarge,
+			model.ErrorResponse{
+				Error: errPkg.ErrFileTooLarge.Error(),
+			},
+		)
+	}
+	if err != nil {
+		ctrl.log.Error("error while storing attachment content", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	attachment.Size = content.N
+
+	if err = ctrl.store.Attachment().Create(c.Request().Context(), attachment); err != nil {
+		// the metadata was not saved, so nothing references the content anymore
+		if delErr := ctrl.blobs.Delete(c.Request().Context(), attachment.StorageKey); delErr != nil {
+			ctrl.log.Error("error while removing orphaned attachment content", zap.Error(delErr))
+		}
+		return ctrl.attachmentErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully uploaded attachment", zap.Any("attachment", attachment))
+	return c.JSON(http.StatusCreated, attachment)
+}
+
+func (ctrl *Controller) HandleGetAttachments(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetAttachments: logged in", zap.String("user_id", userID.String()))
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if _, err = ctrl.store.Todo().GetByID(c.Request().Context(), todoID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	attachments, err := ctrl.store.Attachment().GetAll(c.Request().Context(), todoID)
+	if err != nil {
+		ctrl.log.Error("error while getting attachments from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, attachments)
+}
+
+// HandleDownloadAttachment streams the content of the attachment with the
+// content type detected on upload
+func (ctrl *Controller) HandleDownloadAttachment(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDownloadAttachment: logged in", zap.String("user_id", userID.String()))
+
+	todoID, attachmentID, err := parseAttachmentIDs(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	attachment, err := ctrl.store.Attachment().GetByID(c.Request().Context(), attachmentID, todoID)
+	if err != nil {
+		return ctrl.attachmentErrorResponse(c, err)
+	}
+
+	content, err := ctrl.blobs.Get(c.Request().Context(), attachment.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		err = errPkg.ErrNotFound
+	}
+	if err != nil {
+		return ctrl.attachmentErrorResponse(c, err)
+	}
+	defer content.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Stream(http.StatusOK, attachment.ContentType, content)
+}
+
+func (ctrl *Controller) HandleDeleteAttachment(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDeleteAttachment: logged in", zap.String("user_id", userID.String()))
+
+	todoID, attachmentID, err := parseAttachmentIDs(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	// Only the uploader can delete the attachment
+	attachment, err := ctrl.store.Attachment().GetByID(c.Request().Context(), attachmentID, todoID)
+	if err == nil && attachment.UploadedBy != userID {
+		err = errPkg.ErrNotAccessible
+	}
+	if err == nil {
+		err = ctrl.store.Attachment().Delete(c.Request().Context(), attachmentID, todoID)
+	}
+	if err != nil {
+		return ctrl.attachmentErrorResponse(c, err)
+	}
+
+	if err = ctrl.blobs.Delete(c.Request().Context(), attachment.StorageKey); err != nil {
+		ctrl.log.Error("error while removing attachment content", zap.Error(err))
+	}
+
+	ctrl.log.Info("successfully deleted attachment", zap.String("id", attachmentID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// nextFilePart skips multipart parts until the one named "file"
+func nextFilePart(req *http.Request) (*multipart.Part, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+// parseAttachmentIDs parses ids of the todo and of the attachment from the path
+func parseAttachmentIDs(c echo.Context) (todoID, attachmentID uuid.UUID, err error) {
+	todoID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	attachmentID, err = uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return todoID, attachmentID, nil
+}
+
+func (ctrl *Controller) attachmentErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errPkg.ErrNotFound):
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	case errors.Is(err, errPkg.ErrNotAccessible):
+		return c.JSON(
+			http.StatusForbidden,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotAccessible.Error(),
+			},
+		)
+	}
+	ctrl.log.Error("error while handling attachment", zap.Error(err))
+	return c.JSON(
+		http.StatusInternalServerError,
+		model.ErrorResponse{
+			Error: errPkg.ErrInternalServer.Error(),
+		},
+	)
+}
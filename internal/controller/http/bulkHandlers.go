@@ -0,0 +1,122 @@
+package http
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// bulkItemErrors are the errors of a bulk operation reported to the client as
+// is, any other error is reported as ErrInternalServer
+var bulkItemErrors = []error{
+	errPkg.ErrNotFound,
+	errPkg.ErrNotAccessible,
+	errPkg.ErrBadPosition,
+	errPkg.ErrWipLimitReached,
+	errPkg.ErrBlocked,
+	errPkg.ErrAlreadyExists,
+	errPkg.ErrArchived,
+	errPkg.ErrColumnTarget,
+	errPkg.ErrAssigneeNotFound,
+	errPkg.ErrBadBulkOperation,
+}
+
+func (ctrl *Controller) HandleBulkTodos(c echo.Context) error {
+	var request model.TodoBulkRequest
+
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleBulkTodos: logged in", zap.String("user_id", userID.String()))
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	if request.Mode == "" {
+		request.Mode = model.BulkAllOrNothing
+	}
+	if request.Mode != model.BulkAllOrNothing && request.Mode != model.BulkBestEffort {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadBulkMode.Error(),
+			},
+		)
+	}
+	if len(request.Operations) == 0 || len(request.Operations) > model.MaxBulkOperations {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrTooManyOperations.Error(),
+			},
+		)
+	}
+	for i := range request.Operations {
+		if !request.Operations[i].IsKnown() {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadBulkOperation.Error(),
+				},
+			)
+		}
+	}
+
+	atomic := request.Mode == model.BulkAllOrNothing
+	results, err := ctrl.store.Todo().Bulk(c.Request().Context(), userID, request.Operations, atomic)
+	if err != nil {
+		ctrl.log.Error("error while applying bulk operations", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	response := model.TodoBulkResponse{Applied: true, Results: results}
+	for i := range results {
+		if results[i].Err == nil {
+			continue
+		}
+		results[i].Error = ctrl.bulkItemError(results[i].Err)
+		if atomic {
+			response.Applied = false
+		}
+	}
+
+	if !response.Applied {
+		ctrl.log.Info("bulk operations rolled back", zap.String("user_id", userID.String()))
+		return c.JSON(http.StatusConflict, response)
+	}
+	ctrl.log.Info("successfully applied bulk operations", zap.Int("operations", len(results)))
+	return c.JSON(http.StatusOK, response)
+}
+
+// bulkItemError returns the message reported for a failed bulk operation
+func (ctrl *Controller) bulkItemError(err error) string {
+	for _, known := range bulkItemErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	ctrl.log.Error("error while applying bulk operation", zap.Error(err))
+	return errPkg.ErrInternalServer.Error()
+}
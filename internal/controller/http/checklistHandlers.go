@@ -0,0 +1,287 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleCreateChecklistItem(c echo.Context) error {
+	var (
+		request   model.ChecklistItemRequest
+		todoIDStr string
+		todoID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleCreateChecklistItem: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.Text == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	item := &model.ChecklistItemDTO{
+		ID:     uuid.New(),
+		TodoID: todoID,
+		Text:   request.Text,
+		IsDone: request.IsDone,
+	}
+
+	err = ctrl.store.Checklist().Create(c.Request().Context(), item)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while creating checklist item", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully created checklist item", zap.Any("item", item))
+	return c.JSON(http.StatusCreated, item)
+}
+
+func (ctrl *Controller) HandleGetChecklist(c echo.Context) error {
+	var (
+		items     []model.ChecklistItemDTO
+		todoIDStr string
+		todoID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetChecklist: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if _, err = ctrl.store.Todo().GetByID(c.Request().Context(), todoID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	items, err = ctrl.store.Checklist().GetAll(c.Request().Context(), todoID)
+	if err != nil {
+		ctrl.log.Error("error while getting checklist from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+func (ctrl *Controller) HandleUpdateChecklistItem(c echo.Context) error {
+	var (
+		request   model.ChecklistItemRequest
+		todoIDStr string
+		todoID    uuid.UUID
+		itemIDStr string
+		itemID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleUpdateChecklistItem: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+	itemIDStr = c.Param("item_id")
+	itemID, err = uuid.Parse(itemIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.Text == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	item, err := ctrl.store.Checklist().GetByID(c.Request().Context(), itemID, todoID)
+	if err == nil {
+		item.Text = request.Text
+		item.IsDone = request.IsDone
+		err = ctrl.store.Checklist().Update(c.Request().Context(), item)
+	}
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while updating checklist item", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully updated checklist item", zap.Any("item", item))
+	return c.JSON(http.StatusOK, item)
+}
+
+func (ctrl *Controller) HandleDeleteChecklistItem(c echo.Context) error {
+	var (
+		todoIDStr string
+		todoID    uuid.UUID
+		itemIDStr string
+		itemID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDeleteChecklistItem: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+	itemIDStr = c.Param("item_id")
+	itemID, err = uuid.Parse(itemIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Checklist().Delete(c.Request().Context(), itemID, todoID)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while deleting checklist item", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully deleted checklist item", zap.String("id", itemID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
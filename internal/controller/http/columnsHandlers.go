@@ -8,6 +8,7 @@ import (
 	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 )
 
 func (ctrl *Controller) HandleCreateColumn(c echo.Context) error {
@@ -39,10 +40,21 @@ func (ctrl *Controller) HandleCreateColumn(c echo.Context) error {
 		)
 	}
 
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
 	column := &model.ColumDTO{
 		ProjectId: request.ProjectId,
 		Name:      request.Name,
 		Order:     request.Order,
+		WipLimit:  request.WipLimit,
+		IsDone:    request.IsDone,
 	}
 
 	err = ctrl.store.Column().CreateColumn(c.Request().Context(), column)
@@ -55,10 +67,30 @@ func (ctrl *Controller) HandleCreateColumn(c echo.Context) error {
 			},
 		)
 	}
+	if errors.Is(err, errPkg.ErrNotFound) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+	if err != nil {
+		ctrl.log.Error("error while creating column", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
 	response := model.ColumResponse{
+		ID:        column.ID,
 		ProjectId: column.ProjectId,
 		Name:      column.Name,
 		Order:     column.Order,
+		WipLimit:  column.WipLimit,
+		IsDone:    column.IsDone,
 	}
 	ctrl.log.Info("successfully created new todo", zap.Any("todo", response))
 	return c.JSON(http.StatusCreated, response)
@@ -69,6 +101,8 @@ func (ctrl *Controller) HandleDeleteColumn(c echo.Context) error {
 		projectID   string
 		projectUUID uuid.UUID
 		columnName  string
+		moveTo      string
+		cascade     bool
 		userID      uuid.UUID
 		err         error
 	)
@@ -97,8 +131,58 @@ func (ctrl *Controller) HandleDeleteColumn(c echo.Context) error {
 	}
 	columnName = c.Param("name")
 
-	err = ctrl.store.Column().DeleteColumn(c.Request().Context(), columnName, projectUUID)
+	// Todos of the column are either moved to "move_to" or trashed with "cascade"
+	moveTo = c.QueryParam("move_to")
+	if raw := c.QueryParam("cascade"); raw != "" {
+		cascade, err = strconv.ParseBool(raw)
+		if err != nil {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBindingRequest.Error(),
+				})
+		}
+	}
+
+	ctx, err := conditionalContext(c)
 	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Column().DeleteColumn(ctx, columnName, projectUUID, moveTo, cascade)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPkg.ErrVersionMismatch):
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				})
+		case errors.Is(err, errPkg.ErrNotFound):
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				})
+		case errors.Is(err, errPkg.ErrColumnTarget):
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrColumnTarget.Error(),
+				})
+		case errors.Is(err, errPkg.ErrColumnNotEmpty):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrColumnNotEmpty.Error(),
+				})
+		}
+		ctrl.log.Error("error while deleting column", zap.Error(err))
 		return c.JSON(
 			http.StatusInternalServerError,
 			model.ErrorResponse{
@@ -142,19 +226,20 @@ func (ctrl *Controller) HandleGetColumnByName(c echo.Context) error {
 	columnName = c.Param("name")
 	column, err := ctrl.store.Column().GetColumnByName(c.Request().Context(), columnName, projectUUID)
 	if err != nil {
-		if errors.Is(err, errPkg.ErrNotAccessible) {
+		if errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: errPkg.ErrNotFound.Error(),
 			})
 		}
 		return err
 	}
+	setETag(c, column.Version)
 	return c.JSON(http.StatusOK, column)
 }
 
 func (ctrl *Controller) HandleUpdateColumn(c echo.Context) error {
 	var (
-		request      model.ColumRequest
+		request      model.ColumnUpdateRequest
 		projectIDStr string
 		projectUUID  uuid.UUID
 		columnName   string
@@ -212,10 +297,56 @@ func (ctrl *Controller) HandleUpdateColumn(c echo.Context) error {
 		)
 	}
 
-	column.Name = request.Name
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	if request.Name != nil {
+		column.Name = *request.Name
+	}
+	if request.WipLimit != nil {
+		column.WipLimit = request.WipLimit
+		if *request.WipLimit == 0 {
+			column.WipLimit = nil
+		}
+	}
+	if request.IsDone != nil {
+		column.IsDone = *request.IsDone
+	}
+
+	ctx, err := conditionalContext(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
 
-	err = ctrl.store.Column().UpdateColumn(c.Request().Context(), column, columnName, projectUUID)
+	err = ctrl.store.Column().UpdateColumn(ctx, column, columnName, projectUUID)
 	if err != nil {
+		if errors.Is(err, errPkg.ErrVersionMismatch) {
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
+		}
+		if errors.Is(err, errPkg.ErrAlreadyExists) {
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrAlreadyExists.Error(),
+				},
+			)
+		}
 		return c.JSON(
 			http.StatusInternalServerError,
 			model.ErrorResponse{
@@ -224,6 +355,12 @@ func (ctrl *Controller) HandleUpdateColumn(c echo.Context) error {
 		)
 	}
 
+	// the stored column carries the new version
+	if updated, err := ctrl.store.Column().GetColumnByName(c.Request().Context(), column.Name, projectUUID); err == nil {
+		column = updated
+		setETag(c, column.Version)
+	}
+
 	ctrl.log.Info("successfully updated column", zap.Any("todo", column))
 	return c.JSON(http.StatusCreated, column)
 }
@@ -260,6 +397,26 @@ func (ctrl *Controller) HandleGetAllColumn(c echo.Context) error {
 			})
 	}
 
+	// columns of a project of another organization are not listed, the
+	// project is answered as missing instead of as empty
+	if _, err = ctrl.store.Project().GetByID(c.Request().Context(), projectUUID); err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while getting project by id from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
 	listColumns, err = ctrl.store.Column().GetAllColumns(c.Request().Context(), projectUUID)
 	if err != nil {
 		ctrl.log.Error("error while getting group by id from DB", zap.Error(err))
@@ -273,3 +430,87 @@ func (ctrl *Controller) HandleGetAllColumn(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, listColumns)
 }
+
+func (ctrl *Controller) HandleReorderColumns(c echo.Context) error {
+	var (
+		request      model.ColumnsReorderRequest
+		listColumns  []model.ColumDTO
+		userID       uuid.UUID
+		projectIDStr string
+		projectUUID  uuid.UUID
+		err          error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleReorderColumns: logged in", zap.String("user_id", userID.String()))
+
+	projectIDStr = c.Param("id")
+	projectUUID, err = uuid.Parse(projectIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			})
+	}
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Column().ReorderColumns(c.Request().Context(), projectUUID, request.Columns)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		if errors.Is(err, errPkg.ErrColumnsMismatch) {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrColumnsMismatch.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while reordering columns", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	listColumns, err = ctrl.store.Column().GetAllColumns(c.Request().Context(), projectUUID)
+	if err != nil {
+		ctrl.log.Error("error while getting columns from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully reordered columns", zap.String("project_id", projectUUID.String()))
+	return c.JSON(http.StatusOK, listColumns)
+}
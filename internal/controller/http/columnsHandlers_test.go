@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+func TestUpdateColumnKeepsOmittedFields(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user()
+	project := s.project(alice)
+	target := "/api/columns/" + project.ID.String() + "/"
+	update := func(name, body string) model.ColumDTO {
+		t.Helper()
+		rec := s.do(alice, http.MethodPut, target+name, body)
+		wantStatus(t, rec, http.StatusCreated)
+		var column model.ColumDTO
+		if err := json.Unmarshal(rec.Body.Bytes(), &column); err != nil {
+			t.Fatal(err)
+		}
+		return column
+	}
+
+	column := update("In%20progress", `{"wip_limit":3,"is_done":true}`)
+	if column.WipLimit == nil || *column.WipLimit != 3 || !column.IsDone {
+		t.Fatalf("got column %+v, want WIP limit 3 and done", column)
+	}
+	column = update("In%20progress", `{"name":"Doing"}`)
+	if column.Name != "Doing" || column.WipLimit == nil || *column.WipLimit != 3 || !column.IsDone {
+		t.Fatalf("got renamed column %+v, want WIP limit and done flag kept", column)
+	}
+	column = update("Doing", `{"wip_limit":0}`)
+	if column.WipLimit != nil || !column.IsDone {
+		t.Fatalf("got column %+v, want the WIP limit removed only", column)
+	}
+
+	wantStatus(t, s.do(alice, http.MethodPut, target+"Doing", `{"name":""}`), http.StatusBadRequest)
+	wantStatus(t, s.do(alice, http.MethodPut, target+"Doing", `{"wip_limit":-1}`), http.StatusBadRequest)
+}
@@ -0,0 +1,318 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleCreateComment(c echo.Context) error {
+	var (
+		request   model.CommentRequest
+		todoIDStr string
+		todoID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleCreateComment: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.Body == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	comment := &model.CommentDTO{
+		ID:       uuid.New(),
+		TodoID:   todoID,
+		AuthorID: userID,
+		Body:     request.Body,
+	}
+
+	err = ctrl.store.Comment().Create(c.Request().Context(), comment)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while creating comment", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully created comment", zap.Any("comment", comment))
+	return c.JSON(http.StatusCreated, comment)
+}
+
+func (ctrl *Controller) HandleGetComments(c echo.Context) error {
+	var (
+		comments      []model.CommentDTO
+		total         int
+		limit, offset int
+		todoIDStr     string
+		todoID        uuid.UUID
+		userID        uuid.UUID
+		err           error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetComments: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	limit, offset, err = getPagination(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	if _, err = ctrl.store.Todo().GetByID(c.Request().Context(), todoID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	comments, total, err = ctrl.store.Comment().GetAll(c.Request().Context(), todoID, limit, offset)
+	if err != nil {
+		ctrl.log.Error("error while getting comments from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	return c.JSON(http.StatusOK, model.CommentsResponse{
+		Comments: comments,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+func (ctrl *Controller) HandleGetCommentById(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetCommentById: logged in", zap.String("user_id", userID.String()))
+
+	todoID, commentID, err := parseCommentIDs(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	comment, err := ctrl.store.Comment().GetByID(c.Request().Context(), commentID, todoID)
+	if err != nil {
+		return ctrl.commentErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, comment)
+}
+
+func (ctrl *Controller) HandleUpdateComment(c echo.Context) error {
+	var request model.CommentRequest
+
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleUpdateComment: logged in", zap.String("user_id", userID.String()))
+
+	todoID, commentID, err := parseCommentIDs(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.Body == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	// Only the author can edit the comment
+	comment, err := ctrl.store.Comment().GetByID(c.Request().Context(), commentID, todoID)
+	if err == nil && comment.AuthorID != userID {
+		err = errPkg.ErrNotAccessible
+	}
+	if err == nil {
+		comment.Body = request.Body
+		err = ctrl.store.Comment().Update(c.Request().Context(), comment)
+	}
+	if err != nil {
+		return ctrl.commentErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully updated comment", zap.Any("comment", comment))
+	return c.JSON(http.StatusOK, comment)
+}
+
+func (ctrl *Controller) HandleDeleteComment(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDeleteComment: logged in", zap.String("user_id", userID.String()))
+
+	todoID, commentID, err := parseCommentIDs(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	// Only the author can delete the comment
+	comment, err := ctrl.store.Comment().GetByID(c.Request().Context(), commentID, todoID)
+	if err == nil && comment.AuthorID != userID {
+		err = errPkg.ErrNotAccessible
+	}
+	if err == nil {
+		err = ctrl.store.Comment().Delete(c.Request().Context(), commentID, todoID)
+	}
+	if err != nil {
+		return ctrl.commentErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully deleted comment", zap.String("id", commentID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseCommentIDs parses ids of the todo and of the comment from the path
+func parseCommentIDs(c echo.Context) (todoID, commentID uuid.UUID, err error) {
+	todoID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	commentID, err = uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return todoID, commentID, nil
+}
+
+func (ctrl *Controller) commentErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errPkg.ErrNotFound):
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	case errors.Is(err, errPkg.ErrNotAccessible):
+		return c.JSON(
+			http.StatusForbidden,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotAccessible.Error(),
+			},
+		)
+	}
+	ctrl.log.Error("error while changing comment", zap.Error(err))
+	return c.JSON(
+		http.StatusInternalServerError,
+		model.ErrorResponse{
+			Error: errPkg.ErrInternalServer.Error(),
+		},
+	)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleAddDependency(c echo.Context) error {
+	var (
+		request   model.TodoDependencyRequest
+		todoIDStr string
+		todoID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleAddDependency: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.BlockerID == uuid.Nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Todo().AddDependency(c.Request().Context(), todoID, request.BlockerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPkg.ErrNotFound):
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrBadDependency):
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadDependency.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrDependencyCycle):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrDependencyCycle.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrAlreadyExists):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrAlreadyExists.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while adding dependency", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	todo, err := ctrl.store.Todo().GetByID(c.Request().Context(), todoID)
+	if err != nil {
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully added dependency",
+		zap.String("blocked_id", todoID.String()),
+		zap.String("blocker_id", request.BlockerID.String()),
+	)
+	return c.JSON(http.StatusCreated, todo)
+}
+
+func (ctrl *Controller) HandleRemoveDependency(c echo.Context) error {
+	var (
+		todoIDStr    string
+		todoID       uuid.UUID
+		blockerIDStr string
+		blockerID    uuid.UUID
+		userID       uuid.UUID
+		err          error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleRemoveDependency: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+	blockerIDStr = c.Param("blocker_id")
+	blockerID, err = uuid.Parse(blockerIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Todo().RemoveDependency(c.Request().Context(), todoID, blockerID)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while removing dependency", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully removed dependency",
+		zap.String("blocked_id", todoID.String()),
+		zap.String("blocker_id", blockerID.String()),
+	)
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	eventsBatchSize    = 100
+	eventsPingInterval = 15 * time.Second
+)
+
+// HandleProjectEvents streams changes of the project as Server-Sent Events.
+// Every event is a record of the activity log, its id is the record id and
+// its name is "<entity>.<action>". A client resumes after a disconnect with
+// the Last-Event-ID header or the "last_event_id" query param, without them
+// only new changes are sent
+func (ctrl *Controller) HandleProjectEvents(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleProjectEvents: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	lastID, err := getLastEventID(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	ctx := c.Request().Context()
+	// the project is found in the organization of the request only, so every
+	// member of it may follow the changes
+	if _, err = ctrl.store.Project().GetByID(ctx, projectID); err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	// subscribing before reading the log, a change made in between wakes the
+	// loop up instead of being missed
+	sub := ctrl.events.Subscribe(projectID)
+	defer sub.Close()
+
+	if lastID < 0 {
+		if lastID, err = ctrl.store.Activity().LastID(ctx, projectID); err != nil {
+			ctrl.log.Error("error while getting last project event", zap.Error(err))
+			return c.JSON(
+				http.StatusInternalServerError,
+				model.ErrorResponse{
+					Error: errPkg.ErrInternalServer.Error(),
+				},
+			)
+		}
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ping := time.NewTicker(eventsPingInterval)
+	defer ping.Stop()
+	for {
+		activities, err := ctrl.store.Activity().GetSince(ctx, projectID, lastID, eventsBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			ctrl.log.Error("error while reading project events", zap.Error(err))
+			return nil
+		}
+		for i := range activities {
+			if err = writeEvent(res, &activities[i]); err != nil {
+				return nil
+			}
+			lastID = activities[i].ID
+		}
+		res.Flush()
+		if len(activities) == eventsBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.C:
+		case <-ping.C:
+			// a comment keeps proxies from closing an idle stream
+			if _, err = fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// getLastEventID reads the id of the last event the client got, -1 when the
+// client did not send it
+func getLastEventID(c echo.Context) (int64, error) {
+	raw := c.Request().Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = c.QueryParam("last_event_id")
+	}
+	if raw == "" {
+		return -1, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("bad last event id: %q", raw)
+	}
+	return id, nil
+}
+
+func writeEvent(res *echo.Response, activity *model.ActivityDTO) error {
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s.%s\ndata: %s\n\n", activity.ID, activity.Entity, activity.Action, data)
+	return err
+}
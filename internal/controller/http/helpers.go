@@ -1,15 +1,25 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
 func (ctrl *Controller) generateAccessAndRefreshTokenForUser(userID uuid.UUID) (accessToken string, refreshToken string, err error) {
 	//Creating accessToken token for userID if isAccess is true
 	accessToken, err = ctrl.token.CreateTokenForUser(userID, true)
@@ -86,3 +96,73 @@ func (ctrl *Controller) CompareHashes(passReq []byte, passDB []byte) (err error)
 	}
 	return nil
 }
+
+// getPagination reads "limit" and "offset" query params. Limit defaults to
+// defaultPageLimit and is capped by maxPageLimit
+func getPagination(c echo.Context) (limit int, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("bad limit: %q", raw)
+		}
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("bad offset: %q", raw)
+		}
+	}
+	return limit, offset, nil
+}
+
+// getIncludeArchived reads the "include_archived" query param of list
+// endpoints, archived entities are left out by default
+func getIncludeArchived(c echo.Context) (bool, error) {
+	raw := c.QueryParam("include_archived")
+	if raw == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("bad include_archived: %q", raw)
+	}
+	return include, nil
+}
+
+// getIfMatch reads the If-Match header of a conditional request and returns
+// the version the client expects. No header and "*" give zero, any version
+func getIfMatch(c echo.Context) (int64, error) {
+	raw := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(raw)
+	if err != nil || !strings.HasPrefix(raw, `"`) {
+		return 0, errPkg.ErrBadIfMatch
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errPkg.ErrBadIfMatch
+	}
+	return version, nil
+}
+
+// setETag sets the ETag of the response to the version of the entity
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// conditionalContext returns the request context carrying the version from
+// If-Match. Storage rejects a change of a stale entity with ErrVersionMismatch
+func conditionalContext(c echo.Context) (context.Context, error) {
+	version, err := getIfMatch(c)
+	if err != nil {
+		return nil, err
+	}
+	return storage.WithExpectedVersion(c.Request().Context(), version), nil
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -12,6 +13,10 @@ import (
 
 	"github.com/todo-enjoers/backend_v1/internal/config"
 	"github.com/todo-enjoers/backend_v1/internal/controller"
+	"github.com/todo-enjoers/backend_v1/internal/events"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"github.com/todo-enjoers/backend_v1/internal/pkg/token"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
 )
@@ -19,12 +24,20 @@ import (
 // Checking whether the interface "Controller" implements the structure "Controller"
 var _ controller.Controller = (*Controller)(nil)
 
+const (
+	headerOrgID = "X-Org-ID"
+	// orgRoleKey keeps the role of the user in the organization of the request
+	orgRoleKey = "org_role"
+)
+
 type Controller struct {
 	server *echo.Echo
 	log    *zap.Logger
 	cfg    *config.Config
 	token  token.Provider
 	store  storage.Interface
+	blobs  blob.BlobStore
+	events *events.Broker
 }
 
 func New(
@@ -32,6 +45,8 @@ func New(
 	log *zap.Logger,
 	cfg *config.Config,
 	tokenProvider token.Provider,
+	blobs blob.BlobStore,
+	broker *events.Broker,
 ) (*Controller, error) {
 	log.Info("initialize controller")
 	ctrl := &Controller{
@@ -40,6 +55,8 @@ func New(
 		cfg:    cfg,
 		log:    log,
 		token:  tokenProvider,
+		blobs:  blobs,
+		events: broker,
 	}
 	if err := ctrl.configure(); err != nil {
 		return nil, err
@@ -62,18 +79,50 @@ func (ctrl *Controller) configureRoutes() {
 			users.POST("/register", ctrl.HandleRegister)
 			users.POST("/login", ctrl.HandleLogin)
 			users.GET("/me", ctrl.HandleGetMe)
-			users.GET("/all", ctrl.HandleGetAll)
 			users.POST("/change-password", ctrl.HandleChangePassword)
 			users.POST("/refresh-token", ctrl.HandleRefreshToken)
 		}
 
+		orgs := api.Group("/orgs")
+		{
+			orgs.POST("/", ctrl.HandleCreateOrganization)
+			orgs.GET("/", ctrl.HandleGetMyOrganizations)
+			orgs.GET("/:org_id", ctrl.HandleGetOrganizationById)
+			orgs.GET("/:org_id/users", ctrl.HandleGetOrganizationMembers)
+			orgs.POST("/:org_id/users", ctrl.HandleAddOrganizationMember)
+			orgs.PUT("/:org_id/users/:user_id", ctrl.HandleUpdateOrganizationMember)
+			orgs.DELETE("/:org_id/users/:user_id", ctrl.HandleRemoveOrganizationMember)
+		}
+
 		todos := api.Group("/todos")
 		{
 			todos.GET("/", ctrl.HandleGetAllTodos)
+			todos.GET("/trash", ctrl.HandleGetTodoTrash)
 			todos.GET("/:id", ctrl.HandleGetTodosById)
 			todos.POST("/", ctrl.HandleCreateTodo)
+			todos.POST("/bulk", ctrl.HandleBulkTodos)
 			todos.PUT("/:id", ctrl.HandleChangeTodo)
+			todos.PATCH("/:id", ctrl.HandlePatchTodo)
+			todos.POST("/:id/move", ctrl.HandleMoveTodo)
 			todos.DELETE("/:id", ctrl.HandleDeleteTodo)
+			todos.POST("/:id/restore", ctrl.HandleRestoreTodo)
+			todos.POST("/:id/archive", ctrl.HandleArchiveTodo)
+			todos.POST("/:id/unarchive", ctrl.HandleUnarchiveTodo)
+			todos.POST("/:id/dependencies", ctrl.HandleAddDependency)
+			todos.DELETE("/:id/dependencies/:blocker_id", ctrl.HandleRemoveDependency)
+			todos.GET("/:id/comments", ctrl.HandleGetComments)
+			todos.POST("/:id/comments", ctrl.HandleCreateComment)
+			todos.GET("/:id/comments/:comment_id", ctrl.HandleGetCommentById)
+			todos.PUT("/:id/comments/:comment_id", ctrl.HandleUpdateComment)
+			todos.DELETE("/:id/comments/:comment_id", ctrl.HandleDeleteComment)
+			todos.GET("/:id/attachments", ctrl.HandleGetAttachments)
+			todos.POST("/:id/attachments", ctrl.HandleUploadAttachment)
+			todos.GET("/:id/attachments/:attachment_id", ctrl.HandleDownloadAttachment)
+			todos.DELETE("/:id/attachments/:attachment_id", ctrl.HandleDeleteAttachment)
+			todos.GET("/:id/checklist", ctrl.HandleGetChecklist)
+			todos.POST("/:id/checklist", ctrl.HandleCreateChecklistItem)
+			todos.PUT("/:id/checklist/:item_id", ctrl.HandleUpdateChecklistItem)
+			todos.DELETE("/:id/checklist/:item_id", ctrl.HandleDeleteChecklistItem)
 		}
 
 		projects := api.Group("/projects")
@@ -82,7 +131,26 @@ func (ctrl *Controller) configureRoutes() {
 			projects.DELETE("/delete/:id", ctrl.HandleDeleteProject)
 			projects.PUT("/update/:id", ctrl.HandleUpdateProject)
 			projects.GET("/", ctrl.HandleGetMyProject)
+			projects.GET("/trash", ctrl.HandleGetProjectTrash)
+			projects.POST("/:id/restore", ctrl.HandleRestoreProject)
+			projects.POST("/:id/archive", ctrl.HandleArchiveProject)
+			projects.POST("/:id/unarchive", ctrl.HandleUnarchiveProject)
 			projects.GET("/:id", ctrl.HandleGetMyProjectById)
+			projects.GET("/:id/activity", ctrl.HandleGetProjectActivity)
+			projects.GET("/:id/events", ctrl.HandleProjectEvents)
+			projects.POST("/:id/webhooks", ctrl.HandleCreateWebhook)
+			projects.GET("/:id/webhooks", ctrl.HandleGetWebhooks)
+			projects.DELETE("/:id/webhooks/:webhook_id", ctrl.HandleDeleteWebhook)
+			projects.GET("/:id/webhooks/:webhook_id/deliveries", ctrl.HandleGetWebhookDeliveries)
+			projects.GET("/:id/webhooks/:webhook_id/deliveries/:delivery_id", ctrl.HandleGetWebhookDelivery)
+			projects.POST("/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", ctrl.HandleRedeliverWebhook)
+		}
+		templates := api.Group("/templates")
+		{
+			templates.POST("/", ctrl.HandleCreateTemplate)
+			templates.GET("/", ctrl.HandleGetMyTemplates)
+			templates.GET("/:id", ctrl.HandleGetTemplateById)
+			templates.DELETE("/:id", ctrl.HandleDeleteTemplate)
 		}
 		columns := api.Group("/columns")
 		{
@@ -91,6 +159,7 @@ func (ctrl *Controller) configureRoutes() {
 			columns.PUT("/:id/:name", ctrl.HandleUpdateColumn)
 			columns.GET("/:id/:name", ctrl.HandleGetColumnByName)
 			columns.GET("/:id/", ctrl.HandleGetAllColumn)
+			columns.POST("/:id/reorder", ctrl.HandleReorderColumns)
 		}
 	}
 }
@@ -113,13 +182,91 @@ func (ctrl *Controller) configureMiddlewares() {
 			LogURI:        true,
 		}),
 		middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowOrigins: []string{"*"},
-			AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+			AllowOrigins:  []string{"*"},
+			AllowMethods:  []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+			ExposeHeaders: []string{"ETag", headerIdempotentReplayed},
 		}),
 	}
+	if ctrl.cfg.Postgres != nil && ctrl.cfg.Postgres.CarriesSticky() {
+		middlewares = append(middlewares, ctrl.stickyMiddleware)
+	}
+	middlewares = append(middlewares,
+		ctrl.actorMiddleware,
+		ctrl.tenantMiddleware,
+		ctrl.idempotencyMiddleware,
+	)
 	ctrl.server.Use(middlewares...)
 }
 
+// actorMiddleware puts the user of a valid access token into the request
+// context, so that storage can record who made a change. Requests without a
+// token pass through, handlers still check authorization themselves
+func (ctrl *Controller) actorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.Header.Get(echo.HeaderAuthorization) != "" {
+			if userID, err := ctrl.getUserIDFromRequest(req); err == nil {
+				c.SetRequest(req.WithContext(storage.WithActor(req.Context(), userID)))
+			}
+		}
+		return next(c)
+	}
+}
+
+// tenantMiddleware makes the organization of the request the tenant of
+// storage, so that handlers see only its projects. The organization is taken
+// from the org_id param of the route, else from the X-Org-ID header, else it
+// is the personal organization of the user. An organization the user is not a
+// member of is answered with 404, as if it did not exist
+func (ctrl *Controller) tenantMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		userID, ok := storage.ActorFromContext(req.Context())
+		if !ok {
+			return next(c)
+		}
+
+		orgID := userID
+		raw := c.Param("org_id")
+		if raw == "" {
+			raw = req.Header.Get(headerOrgID)
+		}
+		if raw != "" {
+			var err error
+			if orgID, err = uuid.Parse(raw); err != nil {
+				return c.JSON(
+					http.StatusBadRequest,
+					model.ErrorResponse{
+						Error: errPkg.ErrBadRequestId.Error(),
+					},
+				)
+			}
+		}
+
+		member, err := ctrl.store.Organization().GetMember(req.Context(), orgID, userID)
+		if err != nil {
+			if errors.Is(err, errPkg.ErrNotMember) {
+				return c.JSON(
+					http.StatusNotFound,
+					model.ErrorResponse{
+						Error: errPkg.ErrNotFound.Error(),
+					},
+				)
+			}
+			ctrl.log.Error("error while getting organization member", zap.Error(err))
+			return c.JSON(
+				http.StatusInternalServerError,
+				model.ErrorResponse{
+					Error: errPkg.ErrInternalServer.Error(),
+				},
+			)
+		}
+		c.Set(orgRoleKey, member.Role)
+		c.SetRequest(req.WithContext(storage.WithTenant(req.Context(), orgID)))
+		return next(c)
+	}
+}
+
 func (ctrl *Controller) logValuesFunc(_ echo.Context, v middleware.RequestLoggerValues) error {
 	ctrl.log.Info("Request",
 		zap.String("uri", v.URI),
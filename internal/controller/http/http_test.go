@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/memory"
+)
+
+// tokens takes the id of the user for its access token
+type tokens struct{}
+
+func (tokens) GetDataFromToken(token string) (*model.UserDataInToken, error) {
+	id, err := uuid.Parse(token)
+	if err != nil {
+		return nil, errors.New("bad token")
+	}
+	return &model.UserDataInToken{ID: id, IsAccess: true}, nil
+}
+
+func (tokens) CreateTokenForUser(userID uuid.UUID, _ bool) (string, error) {
+	return userID.String(), nil
+}
+
+// testServer serves the API on a memory storage
+type testServer struct {
+	t     *testing.T
+	ctrl  *Controller
+	store storage.Interface
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store := memory.New(zap.NewNop())
+	ctrl, err := New(store, zap.NewNop(), &config.Config{
+		Idempotency: &config.Idempotency{TTLHours: 1},
+		Attachments: &config.Attachments{MaxSize: 1 << 10},
+	}, tokens{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testServer{t: t, ctrl: ctrl, store: store}
+}
+
+// user registers a user with its personal organization
+func (s *testServer) user() model.UserDTO {
+	s.t.Helper()
+	user := model.UserDTO{ID: uuid.New(), Login: uuid.NewString() + "@example.com", Password: "secret"}
+	if err := s.store.User().Create(context.Background(), &user); err != nil {
+		s.t.Fatal(err)
+	}
+	return user
+}
+
+// org creates an organization owned by the user
+func (s *testServer) org(owner model.UserDTO) model.OrganizationDTO {
+	s.t.Helper()
+	org := model.OrganizationDTO{ID: uuid.New(), Name: "Team", CreatedBy: owner.ID}
+	if err := s.store.Organization().Create(context.Background(), &org); err != nil {
+		s.t.Fatal(err)
+	}
+	return org
+}
+
+// member adds the user to the organization with role
+func (s *testServer) member(org model.OrganizationDTO, user model.UserDTO, role string) {
+	s.t.Helper()
+	ctx := storage.WithTenant(context.Background(), org.ID)
+	if err := s.store.Organization().AddMember(ctx, &model.OrganizationMemberDTO{UserID: user.ID, Role: role}); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// project creates a project of the user in its personal organization with the
+// columns of the built-in Kanban template
+func (s *testServer) project(owner model.UserDTO) model.ProjectDTO {
+	s.t.Helper()
+	ctx := storage.WithTenant(context.Background(), owner.ID)
+	project := model.ProjectDTO{ID: uuid.New(), Name: uuid.NewString(), CreatedBy: owner.ID}
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	if err := s.store.Project().Create(ctx, &project, template.ColumnsFor(project.ID)); err != nil {
+		s.t.Fatal(err)
+	}
+	return project
+}
+
+// do sends the request of the user, headers come in pairs of name and value
+func (s *testServer) do(user model.UserDTO, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user.ID.String())
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	s.ctrl.server.ServeHTTP(rec, req)
+	return rec
+}
+
+func wantStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("got status %d with %s, want %d", rec.Code, rec.Body, want)
+	}
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// multipartOverhead is what a multipart form may add to the largest
+	// attachment
+	multipartOverhead = 1 << 20
+)
+
+// idempotencyMiddleware answers a retried POST with the response stored for
+// its Idempotency-Key instead of running it again. Keys are scoped to the
+// user and the organization of the request, so requests without a valid
+// access token are not deduplicated. The body is part of the fingerprint of
+// the request, it is read into memory up to the largest attachment. A request
+// failing with 5xx releases its key and may be retried
+func (ctrl *Controller) idempotencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		key := req.Header.Get(headerIdempotencyKey)
+		if req.Method != http.MethodPost || key == "" {
+			return next(c)
+		}
+		userID, ok := storage.ActorFromContext(req.Context())
+		if !ok {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadIdempotencyKey.Error(),
+				},
+			)
+		}
+
+		// without a tenant the request runs in the personal organization
+		orgID, ok := storage.TenantFromContext(req.Context())
+		if !ok {
+			orgID = userID
+		}
+		fingerprint, err := ctrl.fingerprint(req)
+		if errors.Is(err, errPkg.ErrIdempotentBodyTooLarge) {
+			return c.JSON(
+				http.StatusRequestEntityTooLarge,
+				model.ErrorResponse{
+					Error: errPkg.ErrIdempotentBodyTooLarge.Error(),
+				},
+			)
+		}
+		if err != nil {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBindingRequest.Error(),
+				},
+			)
+		}
+
+		record := &model.IdempotencyRecordDTO{
+			UserID:      userID,
+			OrgID:       orgID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(ctrl.cfg.Idempotency.TTL()),
+		}
+		stored, err := ctrl.store.Idempotency().Acquire(req.Context(), record)
+		switch {
+		case errors.Is(err, errPkg.ErrIdempotencyInProgress):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrIdempotencyInProgress.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrIdempotencyKeyReuse):
+			return c.JSON(
+				http.StatusUnprocessableEntity,
+				model.ErrorResponse{
+					Error: errPkg.ErrIdempotencyKeyReuse.Error(),
+				},
+			)
+		case err != nil:
+			ctrl.log.Error("error while acquiring idempotency key", zap.Error(err))
+			return c.JSON(
+				http.StatusInternalServerError,
+				model.ErrorResponse{
+					Error: errPkg.ErrInternalServer.Error(),
+				},
+			)
+		case stored != nil:
+			ctrl.log.Info("replaying idempotent response", zap.String("user_id", userID.String()), zap.String("key", key))
+			c.Response().Header().Set(headerIdempotentReplayed, "true")
+			return c.Blob(stored.Status, stored.ContentType, stored.Body)
+		}
+
+		// the key is released unless the response gets stored, also when the
+		// handler panics. The request context may be canceled by then
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := ctrl.store.Idempotency().Release(context.WithoutCancel(req.Context()), record); err != nil {
+				ctrl.log.Error("error while releasing idempotency key", zap.Error(err))
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+		if err = next(c); err != nil {
+			return err
+		}
+		if c.Response().Status >= http.StatusInternalServerError {
+			return nil
+		}
+
+		record.Status = c.Response().Status
+		record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+		record.Body = recorder.body.Bytes()
+		if err = ctrl.store.Idempotency().Complete(context.WithoutCancel(req.Context()), record); err != nil {
+			ctrl.log.Error("error while storing idempotent response", zap.Error(err))
+			return nil
+		}
+		completed = true
+		return nil
+	}
+}
+
+// fingerprint identifies the request sent with a key by its method, path and
+// the hash of its body. The body is read and put back for the handler. A
+// multipart body is hashed by its parts, since clients pick a new boundary
+// for every attempt
+func (ctrl *Controller) fingerprint(req *http.Request) (string, error) {
+	limit := ctrl.cfg.Attachments.MaxSize + multipartOverhead
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > limit {
+		return "", errPkg.ErrIdempotentBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var sum []byte
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		if sum, err = multipartSum(mediaType, params["boundary"], body); err != nil {
+			return "", err
+		}
+	} else {
+		hash := sha256.Sum256(body)
+		sum = hash[:]
+	}
+	return req.Method + " " + req.URL.Path + " " + hex.EncodeToString(sum), nil
+}
+
+// multipartSum hashes the media type and the headers and contents of the
+// parts of a multipart body, leaving its boundary out. A body without parts
+// is hashed as it is
+func multipartSum(mediaType, boundary string, body []byte) ([]byte, error) {
+	if boundary == "" {
+		return nil, http.ErrMissingBoundary
+	}
+	hash := sha256.New()
+	// every field is prefixed with its length, so that the fields of one
+	// body cannot be cut into those of another
+	field := func(b []byte) {
+		_ = binary.Write(hash, binary.BigEndian, uint64(len(b)))
+		hash.Write(b)
+	}
+	field([]byte(mediaType))
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for parts := 0; ; parts++ {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) && parts == 0 {
+			sum := sha256.Sum256(body)
+			return sum[:], nil
+		}
+		if errors.Is(err, io.EOF) {
+			return hash.Sum(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names := slices.Sorted(maps.Keys(part.Header))
+		field([]byte(strconv.Itoa(len(names))))
+		for _, name := range names {
+			field([]byte(name))
+			field([]byte(strings.Join(part.Header[name], "\n")))
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		field(content)
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+
+	first := s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Board"}`, headerIdempotencyKey, "create-board")
+	wantStatus(t, first, http.StatusCreated)
+	retried := s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Board"}`, headerIdempotencyKey, "create-board")
+	wantStatus(t, retried, http.StatusCreated)
+	if retried.Header().Get(headerIdempotentReplayed) != "true" || retried.Body.String() != first.Body.String() {
+		t.Fatalf("got %s, want the first response replayed", retried.Body)
+	}
+}
+
+func TestIdempotencyKeyReusedWithAnotherBody(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+
+	wantStatus(t, s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Board"}`, headerIdempotencyKey, "create"), http.StatusCreated)
+	rec := s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Other board"}`, headerIdempotencyKey, "create")
+	wantStatus(t, rec, http.StatusUnprocessableEntity)
+	if rec.Header().Get(headerIdempotentReplayed) != "" {
+		t.Fatal("got the response of another body replayed")
+	}
+}
+
+func TestIdempotencyKeyScopedToOrganization(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+	org := s.org(user)
+
+	wantStatus(t, s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Board"}`, headerIdempotencyKey, "create"), http.StatusCreated)
+	rec := s.do(user, http.MethodPost, "/api/projects/create", `{"name":"Board"}`,
+		headerIdempotencyKey, "create", headerOrgID, org.ID.String())
+	wantStatus(t, rec, http.StatusCreated)
+	if rec.Header().Get(headerIdempotentReplayed) != "" {
+		t.Fatal("got the response of the personal organization replayed in another one")
+	}
+	if !strings.Contains(rec.Body.String(), org.ID.String()) {
+		t.Fatalf("got %s, want the project created in organization %s", rec.Body, org.ID)
+	}
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+
+	body := `{"name":"` + strings.Repeat("x", int(s.ctrl.cfg.Attachments.MaxSize+multipartOverhead)) + `"}`
+	wantStatus(t, s.do(user, http.MethodPost, "/api/projects/create", body, headerIdempotencyKey, "large"), http.StatusRequestEntityTooLarge)
+}
+
+// multipartBody encodes the file as a multipart form with the boundary
+func multipartBody(t *testing.T, boundary, content string) (string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	part, err := w.CreateFormFile("file", "notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write([]byte(content))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), w.FormDataContentType()
+}
+
+// TestIdempotencyMultipart checks that a retried upload is recognized even
+// though its client picked another boundary for it
+func TestIdempotencyMultipart(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+	target := "/api/todos/" + uuid.NewString() + "/attachments"
+
+	body, contentType := multipartBody(t, "first-attempt", "meeting notes")
+	first := s.do(user, http.MethodPost, target, body, echo.HeaderContentType, contentType, headerIdempotencyKey, "upload")
+	if first.Code >= http.StatusInternalServerError {
+		t.Fatalf("got status %d with %s", first.Code, first.Body)
+	}
+
+	body, contentType = multipartBody(t, "second-attempt", "meeting notes")
+	retried := s.do(user, http.MethodPost, target, body, echo.HeaderContentType, contentType, headerIdempotencyKey, "upload")
+	wantStatus(t, retried, first.Code)
+	if retried.Header().Get(headerIdempotentReplayed) != "true" {
+		t.Fatalf("got %s, want the first response replayed", retried.Body)
+	}
+
+	body, contentType = multipartBody(t, "third-attempt", "other notes")
+	wantStatus(t, s.do(user, http.MethodPost, target, body, echo.HeaderContentType, contentType, headerIdempotencyKey, "upload"),
+		http.StatusUnprocessableEntity)
+}
+
+func TestIdempotencyMultipartMalformed(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user()
+	target := "/api/todos/" + uuid.NewString() + "/attachments"
+	contentType := "multipart/form-data; boundary=b"
+
+	// a body without parts is told apart by its bytes
+	wantStatus(t, s.do(user, http.MethodPost, target, "no parts here",
+		echo.HeaderContentType, contentType, headerIdempotencyKey, "upload"), http.StatusNotFound)
+	wantStatus(t, s.do(user, http.MethodPost, target, "no parts there",
+		echo.HeaderContentType, contentType, headerIdempotencyKey, "upload"), http.StatusUnprocessableEntity)
+
+	truncated := "--b\r\nContent-Disposition: form-data; name=\"file\"\r\n\r\ncut short"
+	wantStatus(t, s.do(user, http.MethodPost, target, truncated,
+		echo.HeaderContentType, contentType, headerIdempotencyKey, "truncated"), http.StatusBadRequest)
+}
@@ -0,0 +1,360 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleCreateOrganization(c echo.Context) error {
+	var (
+		request model.OrganizationRequest
+		userID  uuid.UUID
+		err     error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleCreateOrganization: logged in", zap.String("user_id", userID.String()))
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	org := &model.OrganizationDTO{
+		ID:        uuid.New(),
+		Name:      request.Name,
+		CreatedBy: userID,
+	}
+	if err = ctrl.store.Organization().Create(c.Request().Context(), org); err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully created organization", zap.Any("organization", org))
+	return c.JSON(http.StatusCreated, org)
+}
+
+func (ctrl *Controller) HandleGetMyOrganizations(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetMyOrganizations: logged in", zap.String("user_id", userID.String()))
+
+	orgs, err := ctrl.store.Organization().GetByMember(c.Request().Context(), userID)
+	if err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, orgs)
+}
+
+func (ctrl *Controller) HandleGetOrganizationById(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetOrganizationById: logged in", zap.String("user_id", userID.String()))
+
+	org, err := ctrl.store.Organization().GetByID(c.Request().Context(), getOrganizationID(c))
+	if err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+	org.Role = getOrganizationRole(c)
+	return c.JSON(http.StatusOK, org)
+}
+
+// HandleGetOrganizationMembers lists the users of the organization, every
+// member may see them
+func (ctrl *Controller) HandleGetOrganizationMembers(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetOrganizationMembers: logged in", zap.String("user_id", userID.String()))
+
+	members, err := ctrl.store.Organization().GetMembers(c.Request().Context())
+	if err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+// HandleAddOrganizationMember adds the user with the login to the
+// organization. Admins and owners add members, only owners add owners
+func (ctrl *Controller) HandleAddOrganizationMember(c echo.Context) error {
+	var (
+		request model.OrganizationMemberRequest
+		userID  uuid.UUID
+		err     error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleAddOrganizationMember: logged in", zap.String("user_id", userID.String()))
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	if err = checkManagesRole(c, request.Role); err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+
+	user, err := ctrl.store.User().GetByLogin(c.Request().Context(), request.Login)
+	if err != nil {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+
+	member := &model.OrganizationMemberDTO{
+		UserID: user.ID,
+		Login:  user.Login,
+		Role:   request.Role,
+	}
+	if err = ctrl.store.Organization().AddMember(c.Request().Context(), member); err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully added organization member", zap.Any("member", member))
+	return c.JSON(http.StatusCreated, member)
+}
+
+// HandleUpdateOrganizationMember changes the role of the member. Admins and
+// owners change roles, only owners make or unmake owners
+func (ctrl *Controller) HandleUpdateOrganizationMember(c echo.Context) error {
+	var (
+		request model.OrganizationRoleRequest
+		userID  uuid.UUID
+		err     error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleUpdateOrganizationMember: logged in", zap.String("user_id", userID.String()))
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	// the role is checked and changed in one transaction, so that a member
+	// promoted in between is not changed by someone who may not manage them
+	ctx := c.Request().Context()
+	var member *model.OrganizationMemberDTO
+	err = ctrl.store.WithTx(ctx, func(tx storage.Interface) error {
+		var err error
+		if member, err = tx.Organization().GetMember(ctx, getOrganizationID(c), memberID); err != nil {
+			return err
+		}
+		if err = checkManagesRole(c, member.Role); err != nil {
+			return err
+		}
+		if err = checkManagesRole(c, request.Role); err != nil {
+			return err
+		}
+		return tx.Organization().UpdateMemberRole(ctx, memberID, request.Role)
+	})
+	if err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+	member.Role = request.Role
+
+	ctrl.log.Info("successfully updated organization member", zap.Any("member", member))
+	return c.JSON(http.StatusOK, member)
+}
+
+// HandleRemoveOrganizationMember removes the member from the organization.
+// Every member may leave, admins and owners remove others, only owners remove
+// owners
+func (ctrl *Controller) HandleRemoveOrganizationMember(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleRemoveOrganizationMember: logged in", zap.String("user_id", userID.String()))
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	ctx := c.Request().Context()
+	err = ctrl.store.WithTx(ctx, func(tx storage.Interface) error {
+		if memberID != userID {
+			member, err := tx.Organization().GetMember(ctx, getOrganizationID(c), memberID)
+			if err != nil {
+				return err
+			}
+			if err = checkManagesRole(c, member.Role); err != nil {
+				return err
+			}
+		}
+		return tx.Organization().RemoveMember(ctx, memberID)
+	})
+	if err != nil {
+		return ctrl.organizationErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully removed organization member", zap.String("user_id", memberID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getOrganizationID returns the organization of the request set by
+// tenantMiddleware
+func getOrganizationID(c echo.Context) uuid.UUID {
+	orgID, _ := storage.TenantFromContext(c.Request().Context())
+	return orgID
+}
+
+// getOrganizationRole returns the role of the user in the organization of the
+// request set by tenantMiddleware
+func getOrganizationRole(c echo.Context) string {
+	role, _ := c.Get(orgRoleKey).(string)
+	return role
+}
+
+// checkManagesRole checks that the user may manage members with role in the
+// organization of the request
+func checkManagesRole(c echo.Context, role string) error {
+	own := getOrganizationRole(c)
+	if !model.CanManageMembers(own) || (role == model.OrgRoleOwner && own != model.OrgRoleOwner) {
+		return errPkg.ErrRoleTooLow
+	}
+	return nil
+}
+
+// organizationErrorResponse answers with the status of err
+func (ctrl *Controller) organizationErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errPkg.ErrNotFound), errors.Is(err, errPkg.ErrNotMember):
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Error: errPkg.ErrNotFound.Error()})
+	case errors.Is(err, errPkg.ErrNoOrganization):
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: errPkg.ErrNoOrganization.Error()})
+	case errors.Is(err, errPkg.ErrRoleTooLow):
+		return c.JSON(http.StatusForbidden, model.ErrorResponse{Error: errPkg.ErrRoleTooLow.Error()})
+	case errors.Is(err, errPkg.ErrAlreadyExists):
+		return c.JSON(http.StatusConflict, model.ErrorResponse{Error: errPkg.ErrAlreadyExists.Error()})
+	case errors.Is(err, errPkg.ErrLastOwner), errors.Is(err, errPkg.ErrPersonalOwner):
+		return c.JSON(http.StatusConflict, model.ErrorResponse{Error: err.Error()})
+	default:
+		ctrl.log.Error("error while handling organizations", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Error: errPkg.ErrInternalServer.Error()})
+	}
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+func TestUpdateOrganizationMember(t *testing.T) {
+	s := newTestServer(t)
+	owner, admin, member := s.user(), s.user(), s.user()
+	org := s.org(owner)
+	s.member(org, admin, model.OrgRoleAdmin)
+	s.member(org, member, model.OrgRoleMember)
+	target := func(user model.UserDTO) string {
+		return "/api/orgs/" + org.ID.String() + "/users/" + user.ID.String()
+	}
+
+	wantStatus(t, s.do(admin, http.MethodPut, target(owner), `{"role":"member"}`), http.StatusForbidden)
+	wantStatus(t, s.do(admin, http.MethodPut, target(member), `{"role":"owner"}`), http.StatusForbidden)
+	wantStatus(t, s.do(member, http.MethodPut, target(admin), `{"role":"member"}`), http.StatusForbidden)
+	wantStatus(t, s.do(owner, http.MethodPut, target(member), `{"role":"admin"}`), http.StatusOK)
+
+	got, err := s.store.Organization().GetMember(context.Background(), org.ID, member.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Role != model.OrgRoleAdmin {
+		t.Fatalf("got role %q, want %q", got.Role, model.OrgRoleAdmin)
+	}
+	got, _ = s.store.Organization().GetMember(context.Background(), org.ID, owner.ID)
+	if got.Role != model.OrgRoleOwner {
+		t.Fatalf("got owner changed to %q by an admin", got.Role)
+	}
+}
+
+func TestRemoveOrganizationMember(t *testing.T) {
+	s := newTestServer(t)
+	owner, admin, member := s.user(), s.user(), s.user()
+	org := s.org(owner)
+	s.member(org, admin, model.OrgRoleAdmin)
+	s.member(org, member, model.OrgRoleMember)
+	target := func(user model.UserDTO) string {
+		return "/api/orgs/" + org.ID.String() + "/users/" + user.ID.String()
+	}
+
+	wantStatus(t, s.do(member, http.MethodDelete, target(admin), ""), http.StatusForbidden)
+	wantStatus(t, s.do(admin, http.MethodDelete, target(owner), ""), http.StatusForbidden)
+	wantStatus(t, s.do(admin, http.MethodDelete, target(member), ""), http.StatusNoContent)
+	wantStatus(t, s.do(admin, http.MethodDelete, target(admin), ""), http.StatusNoContent)
+
+	members, err := s.store.Organization().GetByMember(context.Background(), member.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		if m.ID == org.ID {
+			t.Fatal("got removed member still in the organization")
+		}
+	}
+}
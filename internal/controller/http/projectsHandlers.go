@@ -41,7 +41,34 @@ func (ctrl *Controller) HandleCreateProject(c echo.Context) error {
 		CreatedBy: userID,
 	}
 
-	err = ctrl.store.Project().Create(c.Request().Context(), project)
+	// Columns of the template are created in the same transaction as the project
+	var columns []model.ColumDTO
+	if request.TemplateID != uuid.Nil {
+		template, err := ctrl.store.Template().GetByID(c.Request().Context(), request.TemplateID)
+		if err == nil && !template.IsBuiltin() && template.CreatedBy != userID {
+			err = errPkg.ErrTemplateNotFound
+		}
+		if err != nil {
+			if errors.Is(err, errPkg.ErrTemplateNotFound) {
+				return c.JSON(
+					http.StatusNotFound,
+					model.ErrorResponse{
+						Error: errPkg.ErrTemplateNotFound.Error(),
+					},
+				)
+			}
+			ctrl.log.Error("error while getting template", zap.Error(err))
+			return c.JSON(
+				http.StatusInternalServerError,
+				model.ErrorResponse{
+					Error: errPkg.ErrInternalServer.Error(),
+				},
+			)
+		}
+		columns = template.ColumnsFor(project.ID)
+	}
+
+	err = ctrl.store.Project().Create(c.Request().Context(), project, columns)
 	if errors.Is(err, errPkg.ErrAlreadyExists) {
 		ctrl.log.Error("project already exists", zap.Error(err))
 		return c.JSON(
@@ -51,10 +78,21 @@ func (ctrl *Controller) HandleCreateProject(c echo.Context) error {
 			},
 		)
 	}
+	if err != nil {
+		ctrl.log.Error("error while creating project", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
 	response := model.ProjectResponse{
 		ID:        project.ID,
 		Name:      project.Name,
+		OrgID:     project.OrgID,
 		CreatedBy: project.CreatedBy,
+		Columns:   columns,
 	}
 	ctrl.log.Info("successfully created new project", zap.Any("project", response))
 	return c.JSON(http.StatusCreated, response)
@@ -92,8 +130,26 @@ func (ctrl *Controller) HandleDeleteProject(c echo.Context) error {
 		)
 	}
 
-	err = ctrl.store.Project().Delete(c.Request().Context(), projectID)
+	ctx, err := conditionalContext(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Project().Delete(ctx, projectID)
 	if err != nil {
+		if errors.Is(err, errPkg.ErrVersionMismatch) {
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
+		}
 		if errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(
 				http.StatusNotFound,
@@ -155,6 +211,16 @@ func (ctrl *Controller) HandleUpdateProject(c echo.Context) error {
 		)
 	}
 
+	ctx, err := conditionalContext(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
 	gotProject, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID)
 	if err != nil {
 		return c.JSON(
@@ -166,9 +232,16 @@ func (ctrl *Controller) HandleUpdateProject(c echo.Context) error {
 	}
 	gotProject.Name = request.Name
 
-	err = ctrl.store.Project().UpdateName(c.Request().Context(), request.Name, projectID)
+	err = ctrl.store.Project().UpdateName(ctx, request.Name, projectID)
 	if err != nil {
 		switch {
+		case errors.Is(err, errPkg.ErrVersionMismatch):
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
 		case errors.Is(err, errPkg.ErrNotFound):
 			{
 				return c.JSON(
@@ -197,6 +270,12 @@ func (ctrl *Controller) HandleUpdateProject(c echo.Context) error {
 		}
 	}
 
+	// the stored project carries the new version
+	if updated, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID); err == nil {
+		gotProject = updated
+		setETag(c, gotProject.Version)
+	}
+
 	ctrl.log.Info("successfully updated project", zap.Any("project", gotProject))
 	return c.JSON(http.StatusCreated, gotProject)
 }
@@ -217,7 +296,17 @@ func (ctrl *Controller) HandleGetMyProject(c echo.Context) error {
 	}
 	ctrl.log.Info("HandleGetMyProject: logged in", zap.String("user_id", userID.String()))
 
-	myProjects, err = ctrl.store.Project().GetMyProjects(c.Request().Context(), userID)
+	includeArchived, err := getIncludeArchived(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	myProjects, err = ctrl.store.Project().GetAll(c.Request().Context(), includeArchived)
 	if err != nil {
 		if errors.Is(err, errPkg.ErrNotAccessible) {
 			return c.JSON(
@@ -263,7 +352,7 @@ func (ctrl *Controller) HandleGetMyProjectById(c echo.Context) error {
 
 	project, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID)
 	if err != nil {
-		if errors.Is(err, errPkg.ErrNotAccessible) {
+		if errors.Is(err, errPkg.ErrNotAccessible) || errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: errPkg.ErrNotFound.Error(),
 			},
@@ -271,6 +360,7 @@ func (ctrl *Controller) HandleGetMyProjectById(c echo.Context) error {
 		}
 		return err
 	}
+	setETag(c, project.Version)
 	return c.JSON(http.StatusOK, project)
 
 }
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// stickyCookie carries the end of the sticky window of a client, signed
+const stickyCookie = "read_primary_until"
+
+// stickyMiddleware keeps the reads of a client on the primary database for
+// the sticky window after its writes, whichever instance of the server made
+// them. A request that may have written gets the end of the window in a
+// signed cookie, requests bringing a cookie whose window is not over read
+// from the primary
+func (ctrl *Controller) stickyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	cfg := ctrl.cfg.Postgres
+	secret := []byte(cfg.StickySecret)
+	return func(c echo.Context) error {
+		req := c.Request()
+		if cookie, err := req.Cookie(stickyCookie); err == nil && stickyValid(secret, cookie.Value, time.Now()) {
+			c.SetRequest(req.WithContext(storage.WithPrimary(req.Context())))
+		}
+
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			resp := c.Response()
+			resp.Before(func() {
+				if resp.Status >= http.StatusBadRequest {
+					return
+				}
+				http.SetCookie(resp, &http.Cookie{
+					Name:     stickyCookie,
+					Value:    stickyValue(secret, time.Now().Add(cfg.Sticky())),
+					Path:     "/",
+					MaxAge:   cfg.StickySeconds,
+					Secure:   req.TLS != nil,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			})
+		}
+		return next(c)
+	}
+}
+
+// stickyValue returns the cookie value for a window ending at until:
+// "<unix seconds>.<hex HMAC-SHA256 of them>"
+func stickyValue(secret []byte, until time.Time) string {
+	raw := strconv.FormatInt(until.Unix(), 10)
+	return raw + "." + stickySignature(secret, raw)
+}
+
+// stickyValid reports whether value is signed with secret and its window is
+// not over at now
+func stickyValid(secret []byte, value string, now time.Time) bool {
+	raw, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(stickySignature(secret, raw)), []byte(signature)) {
+		return false
+	}
+	until, err := strconv.ParseInt(raw, 10, 64)
+	return err == nil && now.Unix() < until
+}
+
+func stickySignature(secret []byte, raw string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(raw))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+const testStickySecret = "sticky-secret"
+
+// stickyRequest runs a request with the cookie, if any, through the sticky
+// middleware. It returns whether the handler read from the primary and the
+// cookie of the response
+func stickyRequest(t *testing.T, method string, status int, cookie *http.Cookie) (bool, *http.Cookie) {
+	t.Helper()
+	ctrl := &Controller{cfg: &config.Config{Postgres: &config.PostgresConfig{
+		Replicas:      "postgres://replica:5432/postgres",
+		StickySeconds: 5,
+		StickySecret:  testStickySecret,
+	}}}
+	if !ctrl.cfg.Postgres.CarriesSticky() {
+		t.Fatal("the config does not carry the sticky window")
+	}
+
+	req := httptest.NewRequest(method, "/api/todos/", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	e := echo.New()
+	var primary bool
+	err := ctrl.stickyMiddleware(func(c echo.Context) error {
+		primary = storage.PrimaryFromContext(c.Request().Context())
+		return c.NoContent(status)
+	})(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, got := range rec.Result().Cookies() {
+		if got.Name == stickyCookie {
+			return primary, got
+		}
+	}
+	return primary, nil
+}
+
+func TestStickyCookie(t *testing.T) {
+	primary, cookie := stickyRequest(t, http.MethodPost, http.StatusCreated, nil)
+	if primary {
+		t.Fatal("read from the primary without a cookie")
+	}
+	if cookie == nil {
+		t.Fatal("got no cookie after a write")
+	}
+	if cookie.MaxAge != 5 || !cookie.HttpOnly {
+		t.Fatalf("got cookie %+v, want an http only one for the sticky window", cookie)
+	}
+
+	// another instance takes the window from the cookie
+	primary, _ = stickyRequest(t, http.MethodGet, http.StatusOK, cookie)
+	if !primary {
+		t.Fatal("read from a replica with the cookie of a write")
+	}
+}
+
+func TestStickyCookieNotSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{name: "read", method: http.MethodGet, status: http.StatusOK},
+		{name: "failed write", method: http.MethodPatch, status: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if _, cookie := stickyRequest(t, tt.method, tt.status, nil); cookie != nil {
+			t.Errorf("%s: got cookie %q, want none", tt.name, cookie.Value)
+		}
+	}
+}
+
+func TestStickyCookieRejected(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "expired", value: stickyValue([]byte(testStickySecret), now.Add(-time.Second))},
+		{name: "signed with another secret", value: stickyValue([]byte("other-secret"), now.Add(time.Minute))},
+		{name: "extended", value: "9999999999." + stickySignature([]byte(testStickySecret), "1")},
+		{name: "unsigned", value: "9999999999"},
+		{name: "empty", value: ""},
+	}
+	for _, tt := range tests {
+		primary, _ := stickyRequest(t, http.MethodGet, http.StatusOK, &http.Cookie{Name: stickyCookie, Value: tt.value})
+		if primary {
+			t.Errorf("%s: read from the primary with cookie %q", tt.name, tt.value)
+		}
+	}
+}
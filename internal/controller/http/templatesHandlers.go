@@ -0,0 +1,216 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleCreateTemplate(c echo.Context) error {
+	var request model.ProjectTemplateRequest
+
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleCreateTemplate: logged in", zap.String("user_id", userID.String()))
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+
+	if _, err = request.Validate(); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	template := &model.ProjectTemplateDTO{
+		ID:        uuid.New(),
+		Name:      request.Name,
+		CreatedBy: userID,
+		Columns:   request.Columns,
+	}
+
+	err = ctrl.store.Template().Create(c.Request().Context(), template)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrAlreadyExists) {
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrAlreadyExists.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while creating template", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully created new template", zap.Any("template", template))
+	return c.JSON(http.StatusCreated, template)
+}
+
+func (ctrl *Controller) HandleGetMyTemplates(c echo.Context) error {
+	var templates []model.ProjectTemplateDTO
+
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetMyTemplates: logged in", zap.String("user_id", userID.String()))
+
+	templates, err = ctrl.store.Template().GetMyTemplates(c.Request().Context(), userID)
+	if err != nil {
+		ctrl.log.Error("error while getting templates from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, templates)
+}
+
+func (ctrl *Controller) HandleGetTemplateById(c echo.Context) error {
+	var (
+		templateIDStr string
+		templateID    uuid.UUID
+		userID        uuid.UUID
+		err           error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetTemplateById: logged in", zap.String("user_id", userID.String()))
+
+	templateIDStr = c.Param("id")
+	templateID, err = uuid.Parse(templateIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	template, err := ctrl.store.Template().GetByID(c.Request().Context(), templateID)
+	if err == nil && !template.IsBuiltin() && template.CreatedBy != userID {
+		err = errPkg.ErrTemplateNotFound
+	}
+	if err != nil {
+		if errors.Is(err, errPkg.ErrTemplateNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrTemplateNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while getting template", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, template)
+}
+
+func (ctrl *Controller) HandleDeleteTemplate(c echo.Context) error {
+	var (
+		templateIDStr string
+		templateID    uuid.UUID
+		userID        uuid.UUID
+		err           error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDeleteTemplate: logged in", zap.String("user_id", userID.String()))
+
+	templateIDStr = c.Param("id")
+	templateID, err = uuid.Parse(templateIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	// Built-in templates are not stored, so they are never found here
+	err = ctrl.store.Template().Delete(c.Request().Context(), templateID, userID)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrTemplateNotFound) {
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrTemplateNotFound.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while deleting template", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully deleted template", zap.String("id", templateID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/todo-enjoers/backend_v1/internal/model"
 	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
 	"go.uber.org/zap"
 	"net/http"
+	"time"
 )
 
 func (ctrl *Controller) HandleCreateTodo(c echo.Context) error {
@@ -48,7 +50,45 @@ func (ctrl *Controller) HandleCreateTodo(c echo.Context) error {
 		Column:      request.Column,
 	}
 
-	err = ctrl.store.Todo().Create(c.Request().Context(), todo)
+	if request.DueDate != "" {
+		due, err := time.Parse(time.DateOnly, request.DueDate)
+		if err != nil {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadDueDate.Error(),
+				},
+			)
+		}
+		todo.DueDate = &due
+	}
+	if request.Recurrence != "" {
+		rule, err := recurrence.Parse(request.Recurrence)
+		if err != nil {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: err.Error(),
+				},
+			)
+		}
+		if todo.DueDate == nil {
+			today := recurrence.Date(time.Now())
+			todo.DueDate = &today
+		}
+		rule.Anchor(*todo.DueDate)
+		todo.Recurrence = rule.String()
+	}
+
+	err = ctrl.store.Todo().Create(c.Request().Context(), todo, request.Force)
+	if errors.Is(err, errPkg.ErrWipLimitReached) {
+		return c.JSON(
+			http.StatusConflict,
+			model.ErrorResponse{
+				Error: errPkg.ErrWipLimitReached.Error(),
+			},
+		)
+	}
 	if errors.Is(err, errPkg.ErrAlreadyExists) {
 		ctrl.log.Error("project already exists", zap.Error(err))
 		return c.JSON(
@@ -58,6 +98,23 @@ func (ctrl *Controller) HandleCreateTodo(c echo.Context) error {
 			},
 		)
 	}
+	if errors.Is(err, errPkg.ErrNotFound) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrColumnTarget.Error(),
+			},
+		)
+	}
+	if err != nil {
+		ctrl.log.Error("error while creating todo", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
 	response := model.TodoCreateResponse{
 		ID:          todo.ID,
 		Name:        todo.Name,
@@ -65,7 +122,12 @@ func (ctrl *Controller) HandleCreateTodo(c echo.Context) error {
 		IsCompleted: todo.IsCompleted,
 		ProjectID:   todo.ProjectID,
 		CreatedBy:   todo.CreatedBy,
+		ColumnID:    todo.ColumnID,
 		Column:      todo.Column,
+		Position:    todo.Position,
+		Recurrence:  todo.Recurrence,
+		DueDate:     todo.DueDate,
+		Progress:    todo.Progress,
 	}
 	ctrl.log.Info("successfully created new todo", zap.Any("todo", response))
 	return c.JSON(http.StatusCreated, response)
@@ -104,7 +166,7 @@ func (ctrl *Controller) HandleGetTodosById(c echo.Context) error {
 
 	todo, err := ctrl.store.Todo().GetByID(c.Request().Context(), todoID)
 	if err != nil {
-		if errors.Is(err, errPkg.ErrNotAccessible) {
+		if errors.Is(err, errPkg.ErrNotAccessible) || errors.Is(err, errPkg.ErrGetByID) || errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: errPkg.ErrNotFound.Error(),
 			},
@@ -112,6 +174,7 @@ func (ctrl *Controller) HandleGetTodosById(c echo.Context) error {
 		}
 		return err
 	}
+	setETag(c, todo.Version)
 	return c.JSON(http.StatusOK, todo)
 }
 
@@ -158,9 +221,19 @@ func (ctrl *Controller) HandleChangeTodo(c echo.Context) error {
 		)
 	}
 
+	ctx, err := conditionalContext(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
 	todo, err := ctrl.store.Todo().GetByID(c.Request().Context(), todoID)
 	if err != nil {
-		if errors.Is(err, errPkg.ErrNotFound) {
+		if errors.Is(err, errPkg.ErrGetByID) || errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(
 				http.StatusNotFound,
 				model.ErrorResponse{
@@ -182,8 +255,33 @@ func (ctrl *Controller) HandleChangeTodo(c echo.Context) error {
 	todo.IsCompleted = request.IsCompleted
 
 	//work with db
-	err = ctrl.store.Todo().Update(c.Request().Context(), todo, todoID)
+	err = ctrl.store.Todo().Update(ctx, todo, todoID, request.Force)
 	if err != nil {
+		if errors.Is(err, errPkg.ErrVersionMismatch) {
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
+		}
+		if errors.Is(err, errPkg.ErrBlocked) {
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrBlocked.Error(),
+				},
+			)
+		}
+		// the next occurrence of a recurring todo clashed with a todo name
+		if errors.Is(err, errPkg.ErrAlreadyExists) {
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrAlreadyExists.Error(),
+				},
+			)
+		}
 		return c.JSON(
 			http.StatusInternalServerError,
 			model.ErrorResponse{
@@ -192,10 +290,264 @@ func (ctrl *Controller) HandleChangeTodo(c echo.Context) error {
 		)
 	}
 
+	// the stored todo carries the new version
+	if updated, err := ctrl.store.Todo().GetByID(c.Request().Context(), todoID); err == nil {
+		todo = updated
+		setETag(c, todo.Version)
+	}
+
 	ctrl.log.Info("successfully updated todo", zap.Any("todo", todo))
 	return c.JSON(http.StatusCreated, todo)
 }
 
+func (ctrl *Controller) HandlePatchTodo(c echo.Context) error {
+	var (
+		request model.TodoPatchRequest
+		todoID  uuid.UUID
+		userID  uuid.UUID
+		err     error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandlePatchTodo: logged in", zap.String("user_id", userID.String()))
+
+	todoID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if request.Name != nil && *request.Name == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrEmptyName.Error(),
+			},
+		)
+	}
+
+	ctx, err := conditionalContext(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	patch := &model.TodoPatchDTO{
+		Name:        request.Name,
+		Description: request.Description,
+		IsCompleted: request.IsCompleted,
+		ProjectID:   request.ProjectID,
+		Column:      request.Column,
+		Labels:      request.Labels,
+		Force:       request.Force,
+	}
+
+	todo, err := ctrl.store.Todo().Patch(ctx, todoID, userID, patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPkg.ErrVersionMismatch):
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrNotFound):
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrProjectTarget), errors.Is(err, errPkg.ErrColumnTarget):
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: err.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrProjectColumn), errors.Is(err, errPkg.ErrCompletionConflict):
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: err.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrNotAccessible):
+			return c.JSON(
+				http.StatusForbidden,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotAccessible.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrWipLimitReached),
+			errors.Is(err, errPkg.ErrBlocked),
+			errors.Is(err, errPkg.ErrBadDependency),
+			errors.Is(err, errPkg.ErrArchived),
+			errors.Is(err, errPkg.ErrAlreadyExists):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: err.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while patching todo", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully patched todo", zap.Any("todo", todo))
+	setETag(c, todo.Version)
+	return c.JSON(http.StatusOK, todo)
+}
+
+func (ctrl *Controller) HandleMoveTodo(c echo.Context) error {
+	var (
+		request   model.TodoMoveRequest
+		todoIDStr string
+		todoID    uuid.UUID
+		userID    uuid.UUID
+		err       error
+	)
+
+	// Validate user with Token returning userID
+	userID, err = ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleMoveTodo: logged in", zap.String("user_id", userID.String()))
+
+	todoIDStr = c.Param("id")
+	todoID, err = uuid.Parse(todoIDStr)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = c.Bind(&request); err != nil || request.Column == "" {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if request.AfterID == todoID || request.BeforeID == todoID {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadPosition.Error(),
+			},
+		)
+	}
+
+	move := &model.TodoMoveDTO{
+		Column:   request.Column,
+		AfterID:  request.AfterID,
+		BeforeID: request.BeforeID,
+		Force:    request.Force,
+	}
+
+	todo, err := ctrl.store.Todo().Move(c.Request().Context(), todoID, move)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPkg.ErrNotFound):
+			return c.JSON(
+				http.StatusNotFound,
+				model.ErrorResponse{
+					Error: errPkg.ErrNotFound.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrBadPosition):
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadPosition.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrWipLimitReached):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrWipLimitReached.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrBlocked):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrBlocked.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrAlreadyExists):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrAlreadyExists.Error(),
+				},
+			)
+		case errors.Is(err, errPkg.ErrArchived):
+			return c.JSON(
+				http.StatusConflict,
+				model.ErrorResponse{
+					Error: errPkg.ErrArchived.Error(),
+				},
+			)
+		}
+		ctrl.log.Error("error while moving todo", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully moved todo", zap.Any("todo", todo))
+	return c.JSON(http.StatusOK, todo)
+}
+
 func (ctrl *Controller) HandleDeleteTodo(c echo.Context) error {
 	var (
 		todoIDStr string
@@ -228,8 +580,26 @@ func (ctrl *Controller) HandleDeleteTodo(c echo.Context) error {
 		)
 	}
 
-	err = ctrl.store.Todo().Delete(c.Request().Context(), todoID)
+	ctx, err := conditionalContext(c)
 	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	err = ctrl.store.Todo().Delete(ctx, todoID)
+	if err != nil {
+		if errors.Is(err, errPkg.ErrVersionMismatch) {
+			return c.JSON(
+				http.StatusPreconditionFailed,
+				model.ErrorResponse{
+					Error: errPkg.ErrVersionMismatch.Error(),
+				},
+			)
+		}
 		if errors.Is(err, errPkg.ErrNotFound) {
 			return c.JSON(
 				http.StatusNotFound,
@@ -270,7 +640,17 @@ func (ctrl *Controller) HandleGetAllTodos(c echo.Context) error {
 	}
 	ctrl.log.Info("HandleGetAllTodos: logged in", zap.String("user_id", userID.String()))
 
-	listTodos, err = ctrl.store.Todo().GetAll(c.Request().Context(), userID)
+	includeArchived, err := getIncludeArchived(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	listTodos, err = ctrl.store.Todo().GetAll(c.Request().Context(), userID, includeArchived)
 	if err != nil {
 		ctrl.log.Error("error while getting todos by id from DB", zap.Error(err))
 		return c.JSON(
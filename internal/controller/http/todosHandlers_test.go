@@ -0,0 +1,38 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+func TestTodosOfAnotherOrganization(t *testing.T) {
+	s := newTestServer(t)
+	alice, bob := s.user(), s.user()
+	project := s.project(alice)
+
+	rec := s.do(alice, http.MethodPost, "/api/todos/", `{"name":"Release","project_id":"`+project.ID.String()+`","column":"To do"}`)
+	wantStatus(t, rec, http.StatusCreated)
+	var todo model.TodoDTO
+	if err := json.Unmarshal(rec.Body.Bytes(), &todo); err != nil {
+		t.Fatal(err)
+	}
+	wantStatus(t, s.do(alice, http.MethodGet, "/api/todos/"+todo.ID.String(), ""), http.StatusOK)
+
+	// names of todos are unique within their project only
+	other := s.project(bob)
+	wantStatus(t, s.do(bob, http.MethodPost, "/api/todos/", `{"name":"Release","project_id":"`+other.ID.String()+`","column":"To do"}`), http.StatusCreated)
+	wantStatus(t, s.do(alice, http.MethodPost, "/api/todos/", `{"name":"Release","project_id":"`+project.ID.String()+`","column":"To do"}`), http.StatusConflict)
+
+	for _, target := range []string{"/api/todos/" + todo.ID.String(), "/api/todos/" + uuid.NewString()} {
+		wantStatus(t, s.do(bob, http.MethodGet, target, ""), http.StatusNotFound)
+		wantStatus(t, s.do(bob, http.MethodPut, target, `{"name":"Stolen"}`), http.StatusNotFound)
+	}
+	wantStatus(t, s.do(bob, http.MethodGet, "/api/projects/"+project.ID.String(), ""), http.StatusNotFound)
+	wantStatus(t, s.do(bob, http.MethodGet, "/api/columns/"+project.ID.String()+"/", ""), http.StatusNotFound)
+	wantStatus(t, s.do(alice, http.MethodGet, "/api/columns/"+project.ID.String()+"/", ""), http.StatusOK)
+}
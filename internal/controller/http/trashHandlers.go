@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (ctrl *Controller) HandleGetProjectTrash(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetProjectTrash: logged in", zap.String("user_id", userID.String()))
+
+	projects, err := ctrl.store.Project().GetTrash(c.Request().Context(), userID)
+	if err != nil {
+		ctrl.log.Error("error while getting trashed projects from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, projects)
+}
+
+func (ctrl *Controller) HandleRestoreProject(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleRestoreProject: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	if err = ctrl.store.Project().Restore(c.Request().Context(), projectID); err != nil {
+		return ctrl.restoreErrorResponse(c, err)
+	}
+
+	project, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID)
+	if err != nil {
+		return ctrl.restoreErrorResponse(c, err)
+	}
+	ctrl.log.Info("successfully restored project", zap.String("id", projectID.String()))
+	return c.JSON(http.StatusOK, project)
+}
+
+func (ctrl *Controller) HandleGetTodoTrash(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetTodoTrash: logged in", zap.String("user_id", userID.String()))
+
+	todos, err := ctrl.store.Todo().GetTrash(c.Request().Context(), userID)
+	if err != nil {
+		ctrl.log.Error("error while getting trashed todos from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, todos)
+}
+
+func (ctrl *Controller) HandleRestoreTodo(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleRestoreTodo: logged in", zap.String("user_id", userID.String()))
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadRequestId.Error(),
+			},
+		)
+	}
+
+	todo, err := ctrl.store.Todo().Restore(c.Request().Context(), todoID)
+	if err != nil {
+		return ctrl.restoreErrorResponse(c, err)
+	}
+	ctrl.log.Info("successfully restored todo", zap.Any("todo", todo))
+	return c.JSON(http.StatusOK, todo)
+}
+
+func (ctrl *Controller) restoreErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errPkg.ErrNotFound) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrNotFound.Error(),
+			},
+		)
+	}
+	// the todo lost its column and the project has no column to take it
+	if errors.Is(err, errPkg.ErrColumnTarget) {
+		return c.JSON(
+			http.StatusNotFound,
+			model.ErrorResponse{
+				Error: errPkg.ErrColumnTarget.Error(),
+			},
+		)
+	}
+	ctrl.log.Error("error while restoring from trash", zap.Error(err))
+	return c.JSON(
+		http.StatusInternalServerError,
+		model.ErrorResponse{
+			Error: errPkg.ErrInternalServer.Error(),
+		},
+	)
+}
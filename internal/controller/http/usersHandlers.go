@@ -284,36 +284,6 @@ func (ctrl *Controller) HandleGetMe(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
-func (ctrl *Controller) HandleGetAll(c echo.Context) error {
-	var list []model.UserDTO
-
-	// Validate user with Token returning userID
-	userID, err := ctrl.getUserIDFromRequest(c.Request())
-	if err != nil {
-		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
-		return c.JSON(
-			http.StatusUnauthorized,
-			model.ErrorResponse{
-				Error: errPkg.ErrValidationToken.Error(),
-			},
-		)
-	}
-	ctrl.log.Info("HandleGetAll: logged in", zap.String("user_id", userID.String()))
-
-	list, err = ctrl.store.User().GetAll(c.Request().Context())
-	if err != nil {
-		ctrl.log.Error("error while getting users by id from DB", zap.Error(err))
-		return c.JSON(
-			http.StatusNoContent,
-			model.ErrorResponse{
-				Error: errPkg.ErrGetByID.Error(),
-			},
-		)
-	}
-
-	return c.JSON(http.StatusOK, list)
-}
-
 func (ctrl *Controller) HandleRefreshToken(c echo.Context) error {
 	var (
 		request model.UserCoupleTokensRequest
@@ -0,0 +1,331 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+)
+
+// webhookSecretSize is the number of random bytes of a generated secret
+const webhookSecretSize = 32
+
+func (ctrl *Controller) HandleCreateWebhook(c echo.Context) error {
+	var request model.WebhookRequest
+
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleCreateWebhook: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err := ctrl.getOwnedProjectID(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	if err = c.Bind(&request); err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBindingRequest.Error(),
+			},
+		)
+	}
+	if !model.IsWebhookURL(request.URL) {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: errPkg.ErrBadWebhookURL.Error(),
+			},
+		)
+	}
+	events := []string{}
+	for _, event := range request.Events {
+		if !model.IsWebhookEvent(event) {
+			return c.JSON(
+				http.StatusBadRequest,
+				model.ErrorResponse{
+					Error: errPkg.ErrBadWebhookEvent.Error(),
+				},
+			)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			ctrl.log.Error("error while generating webhook secret", zap.Error(err))
+			return c.JSON(
+				http.StatusInternalServerError,
+				model.ErrorResponse{
+					Error: errPkg.ErrInternalServer.Error(),
+				},
+			)
+		}
+	}
+
+	webhook := model.WebhookDTO{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		URL:       request.URL,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: userID,
+	}
+	if err = ctrl.store.Webhook().Create(c.Request().Context(), &webhook); err != nil {
+		ctrl.log.Error("error while creating webhook", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+
+	ctrl.log.Info("successfully created webhook", zap.String("webhook_id", webhook.ID.String()))
+	return c.JSON(http.StatusCreated, model.WebhookCreateResponse{WebhookDTO: webhook, Secret: secret})
+}
+
+func (ctrl *Controller) HandleGetWebhooks(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetWebhooks: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err := ctrl.getOwnedProjectID(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	webhooks, err := ctrl.store.Webhook().GetAll(c.Request().Context(), projectID)
+	if err != nil {
+		ctrl.log.Error("error while getting webhooks from DB", zap.Error(err))
+		return c.JSON(
+			http.StatusInternalServerError,
+			model.ErrorResponse{
+				Error: errPkg.ErrInternalServer.Error(),
+			},
+		)
+	}
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+func (ctrl *Controller) HandleDeleteWebhook(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleDeleteWebhook: logged in", zap.String("user_id", userID.String()))
+
+	projectID, err := ctrl.getOwnedProjectID(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, errPkg.ErrBadRequestId)
+	}
+
+	if err = ctrl.store.Webhook().Delete(c.Request().Context(), webhookID, projectID); err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully deleted webhook", zap.String("webhook_id", webhookID.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ctrl *Controller) HandleGetWebhookDeliveries(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetWebhookDeliveries: logged in", zap.String("user_id", userID.String()))
+
+	webhook, err := ctrl.getOwnedWebhook(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	limit, offset, err := getPagination(c)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadRequest,
+			model.ErrorResponse{
+				Error: err.Error(),
+			},
+		)
+	}
+
+	deliveries, total, err := ctrl.store.Webhook().GetDeliveries(c.Request().Context(), webhook.ID, limit, offset)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+func (ctrl *Controller) HandleGetWebhookDelivery(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleGetWebhookDelivery: logged in", zap.String("user_id", userID.String()))
+
+	webhook, err := ctrl.getOwnedWebhook(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, errPkg.ErrBadRequestId)
+	}
+
+	delivery, err := ctrl.store.Webhook().GetDelivery(c.Request().Context(), deliveryID, webhook.ID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// HandleRedeliverWebhook queues the delivery again, it is sent by the
+// dispatcher on its next round
+func (ctrl *Controller) HandleRedeliverWebhook(c echo.Context) error {
+	// Validate user with Token returning userID
+	userID, err := ctrl.getUserIDFromRequest(c.Request())
+	if err != nil {
+		ctrl.log.Error("could not validate access token from headers", zap.Error(errPkg.ErrValidationToken))
+		return c.JSON(
+			http.StatusUnauthorized,
+			model.ErrorResponse{
+				Error: errPkg.ErrValidationToken.Error(),
+			},
+		)
+	}
+	ctrl.log.Info("HandleRedeliverWebhook: logged in", zap.String("user_id", userID.String()))
+
+	webhook, err := ctrl.getOwnedWebhook(c, userID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, errPkg.ErrBadRequestId)
+	}
+
+	ctx := c.Request().Context()
+	if err = ctrl.store.Webhook().Redeliver(ctx, deliveryID, webhook.ID); err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+	delivery, err := ctrl.store.Webhook().GetDelivery(ctx, deliveryID, webhook.ID)
+	if err != nil {
+		return ctrl.webhookErrorResponse(c, err)
+	}
+
+	ctrl.log.Info("successfully queued webhook redelivery", zap.String("delivery_id", deliveryID.String()))
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// getOwnedProjectID returns the project of the request when the user owns it
+// or administers its organization, webhooks are managed by them only
+func (ctrl *Controller) getOwnedProjectID(c echo.Context, userID uuid.UUID) (uuid.UUID, error) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, errPkg.ErrBadRequestId
+	}
+	project, err := ctrl.store.Project().GetByID(c.Request().Context(), projectID)
+	if err != nil {
+		return uuid.Nil, errPkg.ErrNotFound
+	}
+	if project.CreatedBy != userID && !model.CanManageMembers(getOrganizationRole(c)) {
+		return uuid.Nil, errPkg.ErrNotAccessible
+	}
+	return projectID, nil
+}
+
+// getOwnedWebhook returns the webhook of the request when the user manages
+// the webhooks of its project
+func (ctrl *Controller) getOwnedWebhook(c echo.Context, userID uuid.UUID) (*model.WebhookDTO, error) {
+	projectID, err := ctrl.getOwnedProjectID(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		return nil, errPkg.ErrBadRequestId
+	}
+	return ctrl.store.Webhook().GetByID(c.Request().Context(), webhookID, projectID)
+}
+
+// webhookErrorResponse answers with the status of err
+func (ctrl *Controller) webhookErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errPkg.ErrBadRequestId):
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: errPkg.ErrBadRequestId.Error()})
+	case errors.Is(err, errPkg.ErrNotFound):
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Error: errPkg.ErrNotFound.Error()})
+	case errors.Is(err, errPkg.ErrNotAccessible):
+		return c.JSON(http.StatusForbidden, model.ErrorResponse{Error: errPkg.ErrNotAccessible.Error()})
+	default:
+		ctrl.log.Error("error while handling webhooks", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Error: errPkg.ErrInternalServer.Error()})
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/poller"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Notifier reports projects whose activity log got new records. Every server
+// instance listens on its own, so changes made through one instance reach
+// subscribers of all of them. Listen blocks until ctx is done or the
+// connection breaks
+type Notifier interface {
+	Listen(ctx context.Context, notify func(projectID uuid.UUID)) error
+}
+
+// Broker fans project notifications out to the subscribers of the project.
+// A notification only wakes subscribers up, they read the new records from
+// the activity log themselves, so missed or merged notifications lose nothing
+type Broker struct {
+	notifier Notifier
+	log      *zap.Logger
+
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[*Subscription]struct{}
+
+	poller poller.Poller
+}
+
+// Subscription wakes its subscriber up through C whenever the project
+// changes. C has room for one wake-up, further ones are merged into it
+type Subscription struct {
+	C         chan struct{}
+	projectID uuid.UUID
+	broker    *Broker
+}
+
+func New(notifier Notifier, log *zap.Logger) *Broker {
+	return &Broker{
+		notifier: notifier,
+		log:      log.Named("events"),
+		subs:     make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+func RunBrokerFx(lc fx.Lifecycle, b *Broker) {
+	lc.Append(fx.Hook{
+		OnStart: b.Run,
+		OnStop:  b.Shutdown,
+	})
+}
+
+// Subscribe starts waking the caller up on changes of the project. The
+// subscription must be closed
+func (b *Broker) Subscribe(projectID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		C:         make(chan struct{}, 1),
+		projectID: projectID,
+		broker:    b,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[projectID] == nil {
+		b.subs[projectID] = make(map[*Subscription]struct{})
+	}
+	b.subs[projectID][sub] = struct{}{}
+	return sub
+}
+
+func (s *Subscription) Close() {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs[s.projectID], s)
+	if len(b.subs[s.projectID]) == 0 {
+		delete(b.subs, s.projectID)
+	}
+}
+
+// Run starts listening in the background until Shutdown
+func (b *Broker) Run(_ context.Context) error {
+	b.poller.Go(func(ctx context.Context) {
+		delay := minReconnectDelay
+		for {
+			started := time.Now()
+			err := b.notifier.Listen(ctx, b.notify)
+			if ctx.Err() != nil {
+				return
+			}
+			b.log.Error("listening for project events stopped", zap.Error(err))
+			// notifications sent while reconnecting are lost, wake everybody
+			// up to read what they missed
+			b.notifyAll()
+
+			if time.Since(started) > maxReconnectDelay {
+				delay = minReconnectDelay
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, maxReconnectDelay)
+		}
+	})
+	return nil
+}
+
+func (b *Broker) Shutdown(ctx context.Context) error {
+	return b.poller.Shutdown(ctx)
+}
+
+func (b *Broker) notify(projectID uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[projectID] {
+		sub.wake()
+	}
+}
+
+func (b *Broker) notifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subs := range b.subs {
+		for sub := range subs {
+			sub.wake()
+		}
+	}
+}
+
+func (s *Subscription) wake() {
+	select {
+	case s.C <- struct{}{}:
+	default:
+	}
+}
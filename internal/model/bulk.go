@@ -0,0 +1,55 @@
+package model
+
+import "strings"
+
+// Operations of a bulk request
+const (
+	BulkComplete = "complete"
+	BulkMove     = "move"
+	BulkDelete   = "delete"
+	BulkRelabel  = "relabel"
+	BulkAssign   = "assign"
+)
+
+// Modes of a bulk request. All or nothing is the default
+const (
+	BulkAllOrNothing = "all_or_nothing"
+	BulkBestEffort   = "best_effort"
+)
+
+// Statuses of a bulk result. Rolled back operations succeeded but were undone
+// by a later failure, skipped operations were not tried
+const (
+	BulkStatusOK         = "ok"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back"
+	BulkStatusSkipped    = "skipped"
+)
+
+// MaxBulkOperations limits the size of a bulk request
+const MaxBulkOperations = 100
+
+// IsKnown reports whether the operation is one of the bulk operations
+func (op *TodoBulkOperation) IsKnown() bool {
+	switch op.Op {
+	case BulkComplete, BulkMove, BulkDelete, BulkRelabel, BulkAssign:
+		return true
+	}
+	return false
+}
+
+// NormalizeLabels trims the labels and drops empty and repeated ones keeping
+// the order
+func NormalizeLabels(labels []string) []string {
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	return normalized
+}
@@ -0,0 +1,50 @@
+package model
+
+import "strings"
+
+// Types of domain events that are not named after their activity action
+const (
+	ProjectRenamed = "ProjectRenamed"
+	TodoCompleted  = "TodoCompleted"
+	TodoReopened   = "TodoReopened"
+)
+
+// domainEventVerbs name the domain events of activity actions,
+// e.g. "todo" "move" is TodoMoved
+var domainEventVerbs = map[string]string{
+	"create":    "Created",
+	"update":    "Updated",
+	"delete":    "Deleted",
+	"move":      "Moved",
+	"reorder":   "Reordered",
+	"restore":   "Restored",
+	"archive":   "Archived",
+	"unarchive": "Unarchived",
+}
+
+// DomainEventType returns the type of the domain event of a change recorded
+// in the activity log. Renaming a project and completing or reopening a todo
+// have types of their own, other changes are "<Entity><Action>ed"
+func DomainEventType(entity, action string, changes map[string]FieldChange) string {
+	if action == "update" {
+		switch {
+		case entity == "project" && len(changes) == 1 && hasChange(changes, "name"):
+			return ProjectRenamed
+		case entity == "todo" && len(changes) == 1 && hasChange(changes, "is_completed"):
+			if completed, _ := changes["is_completed"].After.(bool); completed {
+				return TodoCompleted
+			}
+			return TodoReopened
+		}
+	}
+	verb, ok := domainEventVerbs[action]
+	if !ok {
+		verb = strings.ToUpper(action[:1]) + action[1:]
+	}
+	return strings.ToUpper(entity[:1]) + entity[1:] + verb
+}
+
+func hasChange(changes map[string]FieldChange, field string) bool {
+	_, ok := changes[field]
+	return ok
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -13,29 +15,243 @@ type (
 	}
 	// TodoDTO : Todos data transfer object
 	TodoDTO struct {
+		ID          uuid.UUID   `json:"id"`
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		IsCompleted bool        `json:"is_completed"`
+		ProjectID   uuid.UUID   `json:"project_id"`
+		CreatedBy   uuid.UUID   `json:"created_by"`
+		ColumnID    uuid.UUID   `json:"column_id"`
+		Column      string      `json:"column"`
+		Position    string      `json:"position"`
+		Recurrence  string      `json:"recurrence,omitempty"`
+		DueDate     *time.Time  `json:"due_date,omitempty"`
+		Progress    int         `json:"progress"`
+		BlockedBy   []uuid.UUID `json:"blocked_by"`
+		Blocks      []uuid.UUID `json:"blocks"`
+		Labels      []string    `json:"labels"`
+		AssigneeID  *uuid.UUID  `json:"assignee_id,omitempty"`
+		DeletedAt   *time.Time  `json:"deleted_at,omitempty"`
+		ArchivedAt  *time.Time  `json:"archived_at,omitempty"`
+		Version     int64       `json:"version"`
+	}
+	// ChecklistItemDTO : Checklist item of todo data transfer object
+	ChecklistItemDTO struct {
+		ID       uuid.UUID `json:"id"`
+		TodoID   uuid.UUID `json:"todo_id"`
+		Text     string    `json:"text"`
+		IsDone   bool      `json:"is_done"`
+		Position string    `json:"position"`
+	}
+	// CommentDTO : Comment on todo data transfer object. Mentions are ids of
+	// the users mentioned in the body with @login
+	CommentDTO struct {
+		ID        uuid.UUID   `json:"id"`
+		TodoID    uuid.UUID   `json:"todo_id"`
+		AuthorID  uuid.UUID   `json:"author_id"`
+		Body      string      `json:"body"`
+		Mentions  []uuid.UUID `json:"mentions"`
+		CreatedAt time.Time   `json:"created_at"`
+		UpdatedAt time.Time   `json:"updated_at"`
+	}
+	// AttachmentDTO : Metadata of a file attached to a todo. The content
+	// itself lives in the blob store under StorageKey
+	AttachmentDTO struct {
 		ID          uuid.UUID `json:"id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		IsCompleted bool      `json:"is_completed"`
-		ProjectID   uuid.UUID `json:"project_id"`
-		CreatedBy   uuid.UUID `json:"created_by"`
-		Column      string    `json:"column"`
+		TodoID      uuid.UUID `json:"todo_id"`
+		UploadedBy  uuid.UUID `json:"uploaded_by"`
+		FileName    string    `json:"file_name"`
+		ContentType string    `json:"content_type"`
+		Size        int64     `json:"size"`
+		StorageKey  string    `json:"-"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+	// ActivityDTO : Activity log record of a change on a project board.
+	// Changes maps changed fields to their values before and after the change
+	ActivityDTO struct {
+		ID        int64                  `json:"id"`
+		ProjectID uuid.UUID              `json:"project_id"`
+		ActorID   *uuid.UUID             `json:"actor_id"`
+		Entity    string                 `json:"entity"`
+		EntityID  uuid.UUID              `json:"entity_id"`
+		Action    string                 `json:"action"`
+		Changes   map[string]FieldChange `json:"changes"`
+		CreatedAt time.Time              `json:"created_at"`
+	}
+	// DomainEventDTO : Domain event of the outbox, e.g. TodoMoved. Aggregate
+	// is the changed entity, State is its state after the change, nil when it
+	// was deleted
+	DomainEventDTO struct {
+		ID            int64                  `json:"id"`
+		Type          string                 `json:"type"`
+		AggregateType string                 `json:"aggregate_type"`
+		AggregateID   uuid.UUID              `json:"aggregate_id"`
+		ProjectID     uuid.UUID              `json:"project_id"`
+		ActorID       *uuid.UUID             `json:"actor_id"`
+		Changes       map[string]FieldChange `json:"changes"`
+		State         map[string]any         `json:"state"`
+		OccurredAt    time.Time              `json:"occurred_at"`
+		Attempts      int                    `json:"-"`
+		NextAttemptAt time.Time              `json:"-"`
+	}
+	// FieldChange : Values of a field before and after a change
+	FieldChange struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	// TodoMoveDTO : Target place of a todo being moved. Zero AfterID and
+	// BeforeID put the todo at the end of the column, Force ignores the WIP
+	// limit of the column and incomplete blockers of the todo
+	TodoMoveDTO struct {
+		Column   string    `json:"column"`
+		AfterID  uuid.UUID `json:"after_id"`
+		BeforeID uuid.UUID `json:"before_id"`
+		Force    bool      `json:"force"`
+	}
+	// TodoPatchDTO : Partial update of todo, nil fields are left as is. Moving
+	// the todo to another project needs a column of that project
+	TodoPatchDTO struct {
+		Name        *string
+		Description *string
+		IsCompleted *bool
+		ProjectID   *uuid.UUID
+		Column      *string
+		Labels      *[]string
+		Force       bool
+	}
+	// TodoBulkOperation : One operation of a bulk request. Column is used by
+	// move, Labels by relabel and AssigneeID by assign, a nil AssigneeID
+	// unassigns the todo
+	TodoBulkOperation struct {
+		TodoID     uuid.UUID  `json:"todo_id"`
+		Op         string     `json:"op"`
+		Column     string     `json:"column,omitempty"`
+		Labels     []string   `json:"labels,omitempty"`
+		AssigneeID *uuid.UUID `json:"assignee_id,omitempty"`
+		Force      bool       `json:"force,omitempty"`
+	}
+	// TodoBulkResult : Outcome of one operation of a bulk request
+	TodoBulkResult struct {
+		TodoID uuid.UUID `json:"todo_id"`
+		Op     string    `json:"op"`
+		Status string    `json:"status"`
+		Error  string    `json:"error,omitempty"`
+		Todo   *TodoDTO  `json:"todo,omitempty"`
+		Err    error     `json:"-"`
+	}
+	// IdempotencyRecordDTO : Response stored for an Idempotency-Key of the
+	// user in an organization. Status is zero while the first request is
+	// still running
+	IdempotencyRecordDTO struct {
+		UserID      uuid.UUID
+		OrgID       uuid.UUID
+		Key         string
+		Fingerprint string
+		Status      int
+		ContentType string
+		Body        []byte
+		ExpiresAt   time.Time
+	}
+	// WebhookDTO : Webhook subscription of project data transfer object. An
+	// empty Events subscribes to every event
+	WebhookDTO struct {
+		ID        uuid.UUID `json:"id"`
+		ProjectID uuid.UUID `json:"project_id"`
+		URL       string    `json:"url"`
+		Secret    string    `json:"-"`
+		Events    []string  `json:"events"`
+		CreatedBy uuid.UUID `json:"created_by"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	// WebhookDeliveryDTO : Delivery of one event to a webhook, with its
+	// attempts when it is read on its own
+	WebhookDeliveryDTO struct {
+		ID             uuid.UUID           `json:"id"`
+		WebhookID      uuid.UUID           `json:"webhook_id"`
+		ActivityID     int64               `json:"activity_id"`
+		Event          string              `json:"event"`
+		Payload        []byte              `json:"-"`
+		Status         string              `json:"status"`
+		Attempts       int                 `json:"attempts"`
+		NextAttemptAt  *time.Time          `json:"next_attempt_at,omitempty"`
+		LastStatusCode *int                `json:"last_status_code,omitempty"`
+		LastError      string              `json:"last_error,omitempty"`
+		CreatedAt      time.Time           `json:"created_at"`
+		DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+		History        []WebhookAttemptDTO `json:"history,omitempty"`
+	}
+	// WebhookAttemptDTO : One attempt to deliver an event
+	WebhookAttemptDTO struct {
+		DeliveryID  uuid.UUID `json:"-"`
+		AttemptedAt time.Time `json:"attempted_at"`
+		StatusCode  *int      `json:"status_code,omitempty"`
+		Error       string    `json:"error,omitempty"`
+		DurationMs  int64     `json:"duration_ms"`
+	}
+	// WebhookJobDTO : Claimed delivery together with where to send it
+	WebhookJobDTO struct {
+		Delivery WebhookDeliveryDTO
+		URL      string
+		Secret   string
+	}
+	// WebhookPayload : Body of a webhook request
+	WebhookPayload struct {
+		Event    string      `json:"event"`
+		Activity ActivityDTO `json:"activity"`
 	}
 	// GroupDTO : Group data transfer object
 	GroupDTO struct {
 		UserID    uuid.UUID `json:"user_id"`
 		ProjectID uuid.UUID `json:"project_id"`
 	}
-	// ProjectDTO : Projects data transfer object
-	ProjectDTO struct {
+	// OrganizationDTO : Organization (workspace) data transfer object. Role
+	// is the role of the user the organization was read for, if any
+	OrganizationDTO struct {
 		ID        uuid.UUID `json:"id"`
 		Name      string    `json:"name"`
 		CreatedBy uuid.UUID `json:"created_by"`
+		CreatedAt time.Time `json:"created_at"`
+		Role      string    `json:"role,omitempty"`
+	}
+	// OrganizationMemberDTO : Member of organization data transfer object
+	OrganizationMemberDTO struct {
+		OrgID    uuid.UUID `json:"org_id"`
+		UserID   uuid.UUID `json:"user_id"`
+		Login    string    `json:"login"`
+		Role     string    `json:"role"`
+		JoinedAt time.Time `json:"joined_at"`
+	}
+	// ProjectDTO : Projects data transfer object
+	ProjectDTO struct {
+		ID         uuid.UUID  `json:"id"`
+		Name       string     `json:"name"`
+		OrgID      uuid.UUID  `json:"org_id"`
+		CreatedBy  uuid.UUID  `json:"created_by"`
+		DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+		ArchivedAt *time.Time `json:"archived_at,omitempty"`
+		Version    int64      `json:"version"`
 	}
 	// ColumDTO : Column data transfer object
 	ColumDTO struct {
+		ID        uuid.UUID `json:"id"`
 		ProjectId uuid.UUID `json:"project_id"`
 		Name      string    `json:"name"`
 		Order     int       `json:"order"`
+		WipLimit  *int      `json:"wip_limit"`
+		IsDone    bool      `json:"is_done"`
+		Version   int64     `json:"version"`
+	}
+	// ProjectTemplateDTO : Project template data transfer object
+	ProjectTemplateDTO struct {
+		ID        uuid.UUID           `json:"id"`
+		Name      string              `json:"name"`
+		CreatedBy uuid.UUID           `json:"created_by"`
+		Columns   []TemplateColumnDTO `json:"columns"`
+	}
+	// TemplateColumnDTO : Column of project template data transfer object
+	TemplateColumnDTO struct {
+		Name     string `json:"name"`
+		WipLimit *int   `json:"wip_limit"`
+		IsDone   bool   `json:"is_done"`
 	}
 )
@@ -0,0 +1,25 @@
+package model
+
+import "slices"
+
+// Roles of a member of an organization, from the most to the least
+// privileged. Every member works on the projects of the organization, admins
+// also manage its members and owners also manage its owners
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+var orgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleMember}
+
+// IsOrgRole reports whether role is a role of an organization member
+func IsOrgRole(role string) bool {
+	return slices.Contains(orgRoles, role)
+}
+
+// CanManageMembers reports whether a member with role may add, change and
+// remove members of the organization. Only owners may touch owners
+func CanManageMembers(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"errors"
 	"github.com/google/uuid"
+	errPkg "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"strings"
 )
 
@@ -38,24 +39,106 @@ type (
 		CreatedBy   uuid.UUID `json:"created_by"`
 		ProjectID   uuid.UUID `json:"project_id"`
 		Column      string    `json:"column"`
+		Force       bool      `json:"force"`
+		// Recurrence is an RRULE subset, e.g. "FREQ=WEEKLY;BYDAY=MO,TH"
+		Recurrence string `json:"recurrence"`
+		// DueDate is a date like "2026-01-31", recurring todos default to today
+		DueDate string `json:"due_date"`
 	}
 	// TodoUpdateRequest :Updating TodoType Request from user
 	TodoUpdateRequest struct {
 		Name        string `json:"name"`
 		Description string `json:"description"`
 		IsCompleted bool   `json:"is_completed"`
+		Force       bool   `json:"force"`
+	}
+	// TodoPatchRequest :Partially updating TodoType Request from user, only
+	// the fields present in the body are changed
+	TodoPatchRequest struct {
+		Name        *string    `json:"name"`
+		Description *string    `json:"description"`
+		IsCompleted *bool      `json:"is_completed"`
+		ProjectID   *uuid.UUID `json:"project_id"`
+		Column      *string    `json:"column"`
+		Labels      *[]string  `json:"labels"`
+		Force       bool       `json:"force"`
+	}
+	// TodoDependencyRequest :Linking blocker todo Request from user
+	TodoDependencyRequest struct {
+		BlockerID uuid.UUID `json:"blocker_id"`
+	}
+	// TodoMoveRequest :Moving TodoType Request from user
+	TodoMoveRequest struct {
+		Column   string    `json:"column"`
+		AfterID  uuid.UUID `json:"after_id"`
+		BeforeID uuid.UUID `json:"before_id"`
+		Force    bool      `json:"force"`
+	}
+	// TodoBulkRequest :Applying operations to many todos Request from user
+	TodoBulkRequest struct {
+		Mode       string              `json:"mode"`
+		Operations []TodoBulkOperation `json:"operations"`
+	}
+	// ChecklistItemRequest :Creating or updating checklist item Request from user
+	ChecklistItemRequest struct {
+		Text   string `json:"text"`
+		IsDone bool   `json:"is_done"`
+	}
+	// CommentRequest :Creating or editing comment Request from user
+	CommentRequest struct {
+		Body string `json:"body"`
 	}
 	// ColumRequest :Updating ColumnType Request from user
 	ColumRequest struct {
 		ProjectId uuid.UUID `json:"project_id"`
 		Name      string    `json:"name"`
 		Order     int       `json:"order"`
+		WipLimit  *int      `json:"wip_limit"`
+		IsDone    bool      `json:"is_done"`
+	}
+	// ColumnUpdateRequest :Updating ColumnType Request from user. Only the
+	// fields present are changed, a wip_limit of 0 removes the limit
+	ColumnUpdateRequest struct {
+		Name     *string `json:"name"`
+		WipLimit *int    `json:"wip_limit"`
+		IsDone   *bool   `json:"is_done"`
+	}
+	// ColumnsReorderRequest :Reordering all columns of project Request from user
+	ColumnsReorderRequest struct {
+		Columns []string `json:"columns"`
+	}
+	// WebhookRequest :Creating webhook of project Request from user. A secret
+	// is generated when it is empty
+	WebhookRequest struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
 	}
 	// ProjectRequest :Updating ProjectType Request from user
 	ProjectRequest struct {
-		ID        uuid.UUID `json:"id"`
-		Name      string    `json:"name"`
-		CreatedBy uuid.UUID `json:"created_by"`
+		ID         uuid.UUID `json:"id"`
+		Name       string    `json:"name"`
+		CreatedBy  uuid.UUID `json:"created_by"`
+		TemplateID uuid.UUID `json:"template_id"`
+	}
+	// OrganizationRequest :Creating organization Request from user
+	OrganizationRequest struct {
+		Name string `json:"name"`
+	}
+	// OrganizationMemberRequest :Adding member to organization Request from
+	// user. Role defaults to member
+	OrganizationMemberRequest struct {
+		Login string `json:"login"`
+		Role  string `json:"role"`
+	}
+	// OrganizationRoleRequest :Changing role of member Request from user
+	OrganizationRoleRequest struct {
+		Role string `json:"role"`
+	}
+	// ProjectTemplateRequest :Creating project template Request from user
+	ProjectTemplateRequest struct {
+		Name    string              `json:"name"`
+		Columns []TemplateColumnDTO `json:"columns"`
 	}
 )
 
@@ -72,3 +155,86 @@ func (req *UserRegisterRequest) Validate() (ok bool, err error) {
 
 	return true, nil
 }
+
+func (req *ColumRequest) Validate() (ok bool, err error) {
+	if req.WipLimit != nil && *req.WipLimit <= 0 {
+		err = errPkg.ErrBadWipLimit
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (req *ColumnUpdateRequest) Validate() (ok bool, err error) {
+	if req.Name != nil && *req.Name == "" {
+		err = errPkg.ErrEmptyName
+		return false, err
+	}
+
+	if req.WipLimit != nil && *req.WipLimit < 0 {
+		err = errPkg.ErrBadWipLimit
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (req *ProjectTemplateRequest) Validate() (ok bool, err error) {
+	if req.Name == "" {
+		err = errors.New("template name is required")
+		return false, err
+	}
+
+	if len(req.Columns) == 0 {
+		err = errors.New("template must have at least one column")
+		return false, err
+	}
+
+	names := make(map[string]bool, len(req.Columns))
+	for _, column := range req.Columns {
+		if column.Name == "" || names[column.Name] {
+			err = errors.New("template column names must be unique and not empty")
+			return false, err
+		}
+		names[column.Name] = true
+
+		if column.WipLimit != nil && *column.WipLimit <= 0 {
+			err = errPkg.ErrBadWipLimit
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (req *OrganizationRequest) Validate() (ok bool, err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return false, errPkg.ErrEmptyName
+	}
+
+	return true, nil
+}
+
+func (req *OrganizationMemberRequest) Validate() (ok bool, err error) {
+	if req.Login == "" {
+		err = errors.New("login is required")
+		return false, err
+	}
+
+	if req.Role == "" {
+		req.Role = OrgRoleMember
+	}
+	if !IsOrgRole(req.Role) {
+		return false, errPkg.ErrBadRole
+	}
+
+	return true, nil
+}
+
+func (req *OrganizationRoleRequest) Validate() (ok bool, err error) {
+	if !IsOrgRole(req.Role) {
+		return false, errPkg.ErrBadRole
+	}
+
+	return true, nil
+}
@@ -1,6 +1,10 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type (
 	// UserRegisterResponse :Registration Response from server
@@ -37,24 +41,67 @@ type (
 	}
 	// TodoCreateResponse : Todos Response
 	TodoCreateResponse struct {
-		ID          uuid.UUID `json:"id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		IsCompleted bool      `json:"is_complete"`
-		ProjectID   uuid.UUID `json:"project_id"`
-		CreatedBy   uuid.UUID `json:"created_by"`
-		Column      string    `json:"column"`
+		ID          uuid.UUID  `json:"id"`
+		Name        string     `json:"name"`
+		Description string     `json:"description"`
+		IsCompleted bool       `json:"is_complete"`
+		ProjectID   uuid.UUID  `json:"project_id"`
+		CreatedBy   uuid.UUID  `json:"created_by"`
+		ColumnID    uuid.UUID  `json:"column_id"`
+		Column      string     `json:"column"`
+		Position    string     `json:"position"`
+		Recurrence  string     `json:"recurrence,omitempty"`
+		DueDate     *time.Time `json:"due_date,omitempty"`
+		Progress    int        `json:"progress"`
 	}
 	// ColumResponse : Column Response from server
 	ColumResponse struct {
+		ID        uuid.UUID `json:"id"`
 		ProjectId uuid.UUID `json:"project_id"`
 		Name      string    `json:"name"`
 		Order     int       `json:"order"`
+		WipLimit  *int      `json:"wip_limit"`
+		IsDone    bool      `json:"is_done"`
 	}
 	// ProjectResponse : Project Response from server
 	ProjectResponse struct {
-		ID        uuid.UUID `json:"id"`
-		Name      string    `json:"name"`
-		CreatedBy uuid.UUID `json:"created_by"`
+		ID        uuid.UUID  `json:"id"`
+		Name      string     `json:"name"`
+		OrgID     uuid.UUID  `json:"org_id"`
+		CreatedBy uuid.UUID  `json:"created_by"`
+		Columns   []ColumDTO `json:"columns,omitempty"`
+	}
+	// CommentsResponse : Page of comments Response from server
+	CommentsResponse struct {
+		Comments []CommentDTO `json:"comments"`
+		Total    int          `json:"total"`
+		Limit    int          `json:"limit"`
+		Offset   int          `json:"offset"`
+	}
+	// ActivitiesResponse : Page of project activity, newest first
+	ActivitiesResponse struct {
+		Activities []ActivityDTO `json:"activities"`
+		Total      int           `json:"total"`
+		Limit      int           `json:"limit"`
+		Offset     int           `json:"offset"`
+	}
+	// TodoBulkResponse : Per operation results of a bulk request. Applied is
+	// false when nothing was committed
+	TodoBulkResponse struct {
+		Applied bool             `json:"applied"`
+		Results []TodoBulkResult `json:"results"`
+	}
+	// WebhookCreateResponse : Created webhook, the only response with its
+	// secret
+	WebhookCreateResponse struct {
+		WebhookDTO
+		Secret string `json:"secret"`
+	}
+	// WebhookDeliveriesResponse : Page of webhook deliveries, newest first
+	WebhookDeliveriesResponse struct {
+		Deliveries []WebhookDeliveryDTO `json:"deliveries"`
+		Total      int                  `json:"total"`
+		Limit      int                  `json:"limit"`
+		Offset     int                  `json:"offset"`
 	}
 )
@@ -0,0 +1,49 @@
+package model
+
+import "github.com/google/uuid"
+
+// BuiltinTemplates are project templates available to every user. They are
+// not stored in the database and have fixed ids, CreatedBy is uuid.Nil
+var BuiltinTemplates = []ProjectTemplateDTO{
+	{
+		ID:   uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Name: "Kanban",
+		Columns: []TemplateColumnDTO{
+			{Name: "To do"},
+			{Name: "In progress"},
+			{Name: "Done", IsDone: true},
+		},
+	},
+}
+
+// GetBuiltinTemplate returns the built-in template with the given id
+func GetBuiltinTemplate(id uuid.UUID) (*ProjectTemplateDTO, bool) {
+	for i := range BuiltinTemplates {
+		if BuiltinTemplates[i].ID == id {
+			template := BuiltinTemplates[i]
+			return &template, true
+		}
+	}
+	return nil, false
+}
+
+// IsBuiltin reports whether the template is one of BuiltinTemplates
+func (t *ProjectTemplateDTO) IsBuiltin() bool {
+	return t.CreatedBy == uuid.Nil
+}
+
+// ColumnsFor builds the columns the template creates in the project
+func (t *ProjectTemplateDTO) ColumnsFor(projectID uuid.UUID) []ColumDTO {
+	columns := make([]ColumDTO, 0, len(t.Columns))
+	for i, column := range t.Columns {
+		columns = append(columns, ColumDTO{
+			ID:        uuid.New(),
+			ProjectId: projectID,
+			Name:      column.Name,
+			Order:     i,
+			WipLimit:  column.WipLimit,
+			IsDone:    column.IsDone,
+		})
+	}
+	return columns
+}
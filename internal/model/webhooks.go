@@ -0,0 +1,33 @@
+package model
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Statuses of a webhook delivery
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+// webhookEntities and webhookActions make up the event types "<entity>.<action>"
+// of the activity log a webhook can subscribe to
+var (
+	webhookEntities = []string{"project", "column", "todo"}
+	webhookActions  = []string{"create", "update", "delete", "move", "reorder", "restore", "archive", "unarchive"}
+)
+
+// IsWebhookEvent reports whether event is an event type of the activity log
+func IsWebhookEvent(event string) bool {
+	entity, action, ok := strings.Cut(event, ".")
+	return ok && slices.Contains(webhookEntities, entity) && slices.Contains(webhookActions, action)
+}
+
+// IsWebhookURL reports whether raw is an absolute http or https URL
+func IsWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,228 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+// natsServer accepts connections on the loopback and hands each of them to
+// the next of its handlers, which play the server side of the protocol
+type natsServer struct {
+	t        *testing.T
+	listener net.Listener
+	done     chan struct{}
+}
+
+func newNATSServer(t *testing.T, handlers ...func(c *natsConn)) *natsServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &natsServer{t: t, listener: listener, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		for _, handle := range handlers {
+			conn, err := listener.Accept()
+			if err != nil {
+				t.Errorf("accept: %v", err)
+				return
+			}
+			_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+			handle(&natsConn{t: t, conn: conn, reader: bufio.NewReader(conn)})
+			conn.Close()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		<-s.done
+	})
+	return s
+}
+
+func (s *natsServer) url(userinfo string) string {
+	return "nats://" + userinfo + s.listener.Addr().String()
+}
+
+// wait waits until every handler has run
+func (s *natsServer) wait() {
+	s.t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		s.t.Fatal("the nats server did not see every connection through")
+	}
+}
+
+type natsConn struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *natsConn) send(s string) {
+	if _, err := io.WriteString(c.conn, s); err != nil {
+		c.t.Errorf("send %q: %v", s, err)
+	}
+}
+
+// expect reads exactly want from the client
+func (c *natsConn) expect(want string) bool {
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(c.reader, got); err != nil {
+		c.t.Errorf("read %q: %v", want, err)
+		return false
+	}
+	if string(got) != want {
+		c.t.Errorf("client sent\n%q\nwant\n%q", got, want)
+		return false
+	}
+	return true
+}
+
+// handshake plays INFO, then checks CONNECT and the PING that confirms it
+func (c *natsConn) handshake(info, connect string) bool {
+	c.send("INFO " + info + "\r\n")
+	if !c.expect("CONNECT " + connect + "\r\nPING\r\n") {
+		return false
+	}
+	c.send("PONG\r\n")
+	return true
+}
+
+func testEvents(t *testing.T) ([]model.DomainEventDTO, [][]byte) {
+	t.Helper()
+	events := []model.DomainEventDTO{
+		{ID: 7, Type: "TodoCreated", AggregateType: "todo", AggregateID: uuid.New(), ProjectID: uuid.New(), OccurredAt: time.Unix(1700000000, 0).UTC()},
+		{ID: 12, Type: "TodoMoved", AggregateType: "todo", AggregateID: uuid.New(), ProjectID: uuid.New(), OccurredAt: time.Unix(1700000060, 0).UTC()},
+	}
+	payloads := make([][]byte, len(events))
+	for i := range events {
+		var err error
+		if payloads[i], err = json.Marshal(events[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return events, payloads
+}
+
+func newTestNATSSink(t *testing.T, rawURL string) *NATSSink {
+	t.Helper()
+	sink, err := NewNATSSink(rawURL, "todoer", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	return sink
+}
+
+func TestNATSPublishWithHeaders(t *testing.T) {
+	events, payloads := testEvents(t)
+	srv := newNATSServer(t, func(c *natsConn) {
+		if !c.handshake(`{"server_id":"test","headers":true,"max_payload":1048576}`,
+			`{"verbose":false,"pedantic":false,"name":"todoer-outbox","lang":"go","version":"1.0.0","headers":true,"user":"alice","pass":"secret"}`) {
+			return
+		}
+		// "NATS/1.0\r\nNats-Msg-Id: 7\r\n\r\n" is 28 bytes, one more for id 12
+		if !c.expect("HPUB todoer.TodoCreated 28 " + strconv.Itoa(28+len(payloads[0])) + "\r\n" +
+			"NATS/1.0\r\nNats-Msg-Id: 7\r\n\r\n" + string(payloads[0]) + "\r\n" +
+			"HPUB todoer.TodoMoved 29 " + strconv.Itoa(29+len(payloads[1])) + "\r\n" +
+			"NATS/1.0\r\nNats-Msg-Id: 12\r\n\r\n" + string(payloads[1]) + "\r\n" +
+			"PING\r\n") {
+			return
+		}
+		// a PING of the server is answered before the batch is confirmed
+		c.send("+OK\r\nPING\r\n")
+		if !c.expect("PONG\r\n") {
+			return
+		}
+		c.send("PONG\r\n")
+	})
+
+	sink := newTestNATSSink(t, srv.url("alice:secret@"))
+	if err := sink.Publish(context.Background(), events); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	srv.wait()
+}
+
+func TestNATSPublishWithoutHeaders(t *testing.T) {
+	events, payloads := testEvents(t)
+	srv := newNATSServer(t, func(c *natsConn) {
+		if !c.handshake(`{"server_id":"test"}`,
+			`{"verbose":false,"pedantic":false,"name":"todoer-outbox","lang":"go","version":"1.0.0","headers":false,"auth_token":"s3cret"}`) {
+			return
+		}
+		if !c.expect("PUB todoer.TodoCreated " + strconv.Itoa(len(payloads[0])) + "\r\n" + string(payloads[0]) + "\r\n" +
+			"PUB todoer.TodoMoved " + strconv.Itoa(len(payloads[1])) + "\r\n" + string(payloads[1]) + "\r\n" +
+			"PING\r\n") {
+			return
+		}
+		c.send("PONG\r\n")
+	})
+
+	sink := newTestNATSSink(t, srv.url("s3cret@"))
+	if err := sink.Publish(context.Background(), events); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	srv.wait()
+}
+
+// TestNATSReconnect checks that an error of the server fails the batch and
+// that the next publish comes on a new connection
+func TestNATSReconnect(t *testing.T) {
+	events, payloads := testEvents(t)
+	info := `{"server_id":"test"}`
+	connect := `{"verbose":false,"pedantic":false,"name":"todoer-outbox","lang":"go","version":"1.0.0","headers":false}`
+	batch := "PUB todoer.TodoCreated " + strconv.Itoa(len(payloads[0])) + "\r\n" + string(payloads[0]) + "\r\nPING\r\n"
+	srv := newNATSServer(t,
+		func(c *natsConn) {
+			if c.handshake(info, connect) && c.expect(batch) {
+				c.send("-ERR 'Maximum Payload Violation'\r\n")
+			}
+		},
+		func(c *natsConn) {
+			if c.handshake(info, connect) && c.expect(batch) {
+				c.send("PONG\r\n")
+			}
+		},
+	)
+
+	sink := newTestNATSSink(t, srv.url(""))
+	err := sink.Publish(context.Background(), events[:1])
+	if err == nil || !strings.Contains(err.Error(), "Maximum Payload Violation") {
+		t.Fatalf("Publish error = %v, want the error of the server", err)
+	}
+	if err = sink.Publish(context.Background(), events[:1]); err != nil {
+		t.Fatalf("Publish after an error: %v", err)
+	}
+	srv.wait()
+}
+
+func TestNATSRefusesTLS(t *testing.T) {
+	srv := newNATSServer(t, func(c *natsConn) {
+		c.send(`INFO {"server_id":"test","tls_required":true}` + "\r\n")
+		// the client hangs up without sending CONNECT
+		if b, err := c.reader.ReadByte(); err == nil {
+			t.Errorf("client sent %q to a server requiring TLS", b)
+		}
+	})
+
+	sink := newTestNATSSink(t, srv.url(""))
+	events, _ := testEvents(t)
+	if err := sink.Publish(context.Background(), events); err == nil || !strings.Contains(err.Error(), "TLS") {
+		t.Fatalf("Publish error = %v, want TLS to be refused", err)
+	}
+	srv.wait()
+}
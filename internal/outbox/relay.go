@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/poller"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Relay publishes the domain events of the outbox to the sinks, oldest first.
+// A batch that failed on any sink is retried with exponential backoff, and
+// nothing after it is published until it goes through
+type Relay struct {
+	store  storage.Interface
+	cfg    *config.Outbox
+	log    *zap.Logger
+	sinks  []Sink
+	poller poller.Poller
+}
+
+// New builds a relay publishing to the sinks listed in the config
+func New(store storage.Interface, cfg *config.Config, log *zap.Logger) (*Relay, error) {
+	log = log.Named("outbox")
+	sinks, err := NewSinks(cfg.Outbox, log)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithSinks(store, cfg.Outbox, log, sinks...), nil
+}
+
+// NewWithSinks builds a relay publishing to the given sinks
+func NewWithSinks(store storage.Interface, cfg *config.Outbox, log *zap.Logger, sinks ...Sink) *Relay {
+	return &Relay{
+		store: store,
+		cfg:   cfg,
+		log:   log,
+		sinks: sinks,
+	}
+}
+
+func RunRelayFx(lc fx.Lifecycle, r *Relay) {
+	lc.Append(fx.Hook{
+		OnStart: r.Run,
+		OnStop:  r.Shutdown,
+	})
+}
+
+// Run starts publishing in the background until Shutdown
+func (r *Relay) Run(_ context.Context) error {
+	if r.cfg.PollInterval() <= 0 || len(r.sinks) == 0 {
+		r.log.Info("publishing of domain events is disabled")
+		return nil
+	}
+
+	r.poller.Every(r.cfg.PollInterval(), func(ctx context.Context) {
+		if err := poller.Drain(ctx, r.cfg.BatchSize, r.PublishOnce); err != nil {
+			r.log.Error("error while publishing domain events", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (r *Relay) Shutdown(ctx context.Context) error {
+	if err := r.poller.Shutdown(ctx); err != nil {
+		return err
+	}
+	for _, sink := range r.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				r.log.Error("error while closing sink", zap.String("sink", sink.Name()), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// PublishOnce publishes one batch of events to every sink and returns how
+// many events were published. On failure the oldest event of the batch is
+// put off by the backoff of its attempts
+func (r *Relay) PublishOnce(ctx context.Context) (int, error) {
+	var head *model.DomainEventDTO
+	n, err := r.store.Outbox().Publish(ctx, r.cfg.BatchSize, func(ctx context.Context, events []model.DomainEventDTO) error {
+		head = &events[0]
+		for _, sink := range r.sinks {
+			if err := r.publish(ctx, sink, events); err != nil {
+				return fmt.Errorf("error while publishing to %s: %w", sink.Name(), err)
+			}
+		}
+		return nil
+	})
+	if err != nil && head != nil {
+		next := time.Now().Add(r.cfg.Backoff(head.Attempts + 1))
+		if failErr := r.store.Outbox().Fail(ctx, head.ID, err.Error(), next); failErr != nil {
+			r.log.Error("error while recording outbox failure", zap.Error(failErr))
+		}
+	}
+	return n, err
+}
+
+func (r *Relay) publish(ctx context.Context, sink Sink, events []model.DomainEventDTO) error {
+	if r.cfg.Timeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout())
+		defer cancel()
+	}
+	return sink.Publish(ctx, events)
+}
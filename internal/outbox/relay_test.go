@@ -0,0 +1,252 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/memory"
+)
+
+var errSinkDown = errors.New("sink is down")
+
+// recordingSink records the ids of every batch it is given and fails the
+// first failures of them
+type recordingSink struct {
+	name     string
+	mu       sync.Mutex
+	failures int
+	batches  [][]int64
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Publish(_ context.Context, events []model.DomainEventDTO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(events))
+	for i := range events {
+		ids = append(ids, events[i].ID)
+	}
+	s.batches = append(s.batches, ids)
+	if s.failures > 0 {
+		s.failures--
+		return errSinkDown
+	}
+	return nil
+}
+
+// rescheduling records the backoff the relay asks for and makes the failed
+// event due right away, so that every attempt can be made without waiting
+type rescheduling struct {
+	storage.Interface
+	outbox *schedule
+}
+
+func (s *rescheduling) Outbox() storage.OutboxStorage {
+	return s.outbox
+}
+
+type schedule struct {
+	storage.OutboxStorage
+	failed []int64
+	delays []time.Duration
+}
+
+func (s *schedule) Fail(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	s.failed = append(s.failed, id)
+	s.delays = append(s.delays, time.Until(nextAttemptAt).Round(time.Second))
+	return s.OutboxStorage.Fail(ctx, id, lastError, time.Now())
+}
+
+func testConfig() *config.Outbox {
+	return &config.Outbox{
+		BatchSize:         2,
+		BackoffSeconds:    30,
+		MaxBackoffMinutes: 1,
+		TimeoutSeconds:    5,
+	}
+}
+
+// newStore returns a memory storage with a project and todos todos in it,
+// every change of which is waiting in the outbox
+func newStore(t *testing.T, todos int) storage.Interface {
+	t.Helper()
+	mem := memory.New(zap.NewNop())
+	user := model.UserDTO{ID: uuid.New(), Login: "owner@example.com", Password: "secret"}
+	if err := mem.User().Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	ctx := storage.WithTenant(context.Background(), user.ID)
+	project := model.ProjectDTO{ID: uuid.New(), Name: "Board", CreatedBy: user.ID}
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	if err := mem.Project().Create(ctx, &project, template.ColumnsFor(project.ID)); err != nil {
+		t.Fatal(err)
+	}
+	for i := range todos {
+		todo := model.TodoDTO{ID: uuid.New(), Name: fmt.Sprintf("Todo %d", i+1), ProjectID: project.ID, CreatedBy: user.ID, Column: "To do"}
+		if err := mem.Todo().Create(ctx, &todo, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mem
+}
+
+// publishAll runs the relay until it publishes nothing more and returns how
+// many events went out
+func publishAll(t *testing.T, r *Relay) int {
+	t.Helper()
+	var total int
+	for {
+		n, err := r.PublishOnce(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			return total
+		}
+		total += n
+	}
+}
+
+func TestPublishInOrder(t *testing.T) {
+	first, second := &recordingSink{name: "first"}, &recordingSink{name: "second"}
+	r := NewWithSinks(newStore(t, 3), testConfig(), zap.NewNop(), first, second)
+
+	total := publishAll(t, r)
+	if total < 3 {
+		t.Fatalf("published %d events, want at least one per todo", total)
+	}
+	for _, sink := range []*recordingSink{first, second} {
+		var next int64 = 1
+		for _, batch := range sink.batches {
+			if len(batch) == 0 || len(batch) > testConfig().BatchSize {
+				t.Fatalf("%s got a batch of %d events, want 1 to %d", sink.name, len(batch), testConfig().BatchSize)
+			}
+			for _, id := range batch {
+				if id != next {
+					t.Fatalf("%s got event %d, want %d: batches %v", sink.name, id, next, sink.batches)
+				}
+				next++
+			}
+		}
+		if int(next-1) != total {
+			t.Fatalf("%s got %d events, want %d", sink.name, next-1, total)
+		}
+	}
+
+	// published events are not handed out again
+	if n := publishAll(t, r); n != 0 {
+		t.Fatalf("published %d events again", n)
+	}
+}
+
+// TestFailHoldsTheHead checks that nothing is published past a failed batch
+// until its backoff runs out
+func TestFailHoldsTheHead(t *testing.T) {
+	sink := &recordingSink{name: "sink", failures: 1}
+	r := NewWithSinks(newStore(t, 3), testConfig(), zap.NewNop(), sink)
+
+	if _, err := r.PublishOnce(context.Background()); !errors.Is(err, errSinkDown) {
+		t.Fatalf("PublishOnce error = %v, want %v", err, errSinkDown)
+	}
+	n, err := r.PublishOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || len(sink.batches) != 1 {
+		t.Fatalf("published %d events in %d batches while the head backs off, want none after the failed one", n, len(sink.batches))
+	}
+}
+
+// TestFailBackoff checks that a failed batch is retried from its oldest event
+// with a growing backoff, and that one failing sink makes every sink get the
+// batch again
+func TestFailBackoff(t *testing.T) {
+	healthy, failing := &recordingSink{name: "healthy"}, &recordingSink{name: "failing", failures: 3}
+	mem := newStore(t, 3)
+	store := &rescheduling{Interface: mem, outbox: &schedule{OutboxStorage: mem.Outbox()}}
+	r := NewWithSinks(store, testConfig(), zap.NewNop(), healthy, failing)
+
+	for range 3 {
+		if _, err := r.PublishOnce(context.Background()); !errors.Is(err, errSinkDown) {
+			t.Fatalf("PublishOnce error = %v, want %v", err, errSinkDown)
+		}
+	}
+	n, err := r.PublishOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != testConfig().BatchSize {
+		t.Fatalf("published %d events after the failures, want %d", n, testConfig().BatchSize)
+	}
+
+	wantDelays := []time.Duration{30 * time.Second, time.Minute, time.Minute}
+	if len(store.outbox.delays) != len(wantDelays) {
+		t.Fatalf("got %d failures recorded, want %d", len(store.outbox.delays), len(wantDelays))
+	}
+	for i, want := range wantDelays {
+		if store.outbox.failed[i] != 1 {
+			t.Errorf("failure %d recorded on event %d, want the oldest one", i+1, store.outbox.failed[i])
+		}
+		if store.outbox.delays[i] != want {
+			t.Errorf("failure %d backs off for %s, want %s", i+1, store.outbox.delays[i], want)
+		}
+	}
+	for _, sink := range []*recordingSink{healthy, failing} {
+		if len(sink.batches) != 4 {
+			t.Fatalf("%s got %d batches, want 4", sink.name, len(sink.batches))
+		}
+		for _, batch := range sink.batches {
+			if batch[0] != 1 {
+				t.Errorf("%s got a batch starting at event %d, want every attempt to start at 1", sink.name, batch[0])
+			}
+		}
+	}
+}
+
+// TestRunDrainsFullBatches checks that the first poll goes on while batches
+// come full, rather than publishing one batch per tick
+func TestRunDrainsFullBatches(t *testing.T) {
+	want := publishAll(t, NewWithSinks(newStore(t, 3), testConfig(), zap.NewNop(), &recordingSink{name: "count"}))
+
+	sink := &recordingSink{name: "sink"}
+	cfg := testConfig()
+	cfg.PollIntervalSeconds = 3600
+	r := NewWithSinks(newStore(t, 3), cfg, zap.NewNop(), sink)
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		sink.mu.Lock()
+		var got int
+		for _, batch := range sink.batches {
+			got += len(batch)
+		}
+		sink.mu.Unlock()
+		if got == want {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("the relay published %d events before the next tick, want %d", got, want)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
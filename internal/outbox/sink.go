@@ -0,0 +1,73 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+var ErrUnknownSink = errors.New("unknown outbox sink")
+
+// Sink publishes domain events somewhere. Events come oldest first and are
+// published at least once: a batch that failed on any sink is published
+// again to every sink, so consumers deduplicate by event id
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, events []model.DomainEventDTO) error
+}
+
+// NewSinks builds the sinks listed in the config
+func NewSinks(cfg *config.Outbox, log *zap.Logger) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.SinkNames() {
+		switch name {
+		case "log":
+			sinks = append(sinks, NewLogSink(log))
+		case "webhook":
+			sink, err := NewWebhookSink(cfg.WebhookURL, cfg.WebhookSecret, cfg.Timeout())
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "nats":
+			sink, err := NewNATSSink(cfg.NATSURL, cfg.NATSSubject, cfg.Timeout())
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSink, name)
+		}
+	}
+	return sinks, nil
+}
+
+// LogSink writes events to the log, it is meant for development
+type LogSink struct {
+	log *zap.Logger
+}
+
+func NewLogSink(log *zap.Logger) *LogSink {
+	return &LogSink{log: log}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(_ context.Context, events []model.DomainEventDTO) error {
+	for i := range events {
+		s.log.Info("domain event",
+			zap.Int64("id", events[i].ID),
+			zap.String("type", events[i].Type),
+			zap.String("aggregate_id", events[i].AggregateID.String()),
+			zap.String("project_id", events[i].ProjectID.String()),
+		)
+	}
+	return nil
+}
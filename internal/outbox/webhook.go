@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/webhook"
+)
+
+var ErrBadSinkURL = errors.New("outbox sink url is not set")
+
+// WebhookSink posts every batch as {"events": [...]} to one URL. Requests are
+// signed like project webhooks, see webhook.Sign
+type WebhookSink struct {
+	url    string
+	secret string
+	// Client sends the requests, it may be replaced before the first publish
+	Client *http.Client
+}
+
+func NewWebhookSink(url, secret string, timeout time.Duration) (*WebhookSink, error) {
+	if !model.IsWebhookURL(url) {
+		return nil, fmt.Errorf("%w: webhook", ErrBadSinkURL)
+	}
+	return &WebhookSink{
+		url:    url,
+		secret: secret,
+		Client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, events []model.DomainEventDTO) error {
+	body, err := json.Marshal(struct {
+		Events []model.DomainEventDTO `json:"events"`
+	}{events})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(s.secret, timestamp, body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
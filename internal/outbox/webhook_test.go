@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/webhook"
+)
+
+const testSecret = "outbox-secret"
+
+// sinkReceiver checks the signature of every request with secret and
+// answers it with status
+type sinkReceiver struct {
+	t      *testing.T
+	secret string
+	status int
+
+	mu     sync.Mutex
+	events [][]model.DomainEventDTO
+}
+
+func (r *sinkReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("read body: %v", err)
+	}
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		r.t.Errorf("got %s with content type %q, want a POST of JSON", req.Method, req.Header.Get("Content-Type"))
+	}
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)).Abs() > time.Minute {
+		r.t.Errorf("got timestamp %q, want the current time", req.Header.Get(webhook.HeaderTimestamp))
+	}
+	if !webhook.Verify(r.secret, timestamp, body, req.Header.Get(webhook.HeaderSignature)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var batch struct {
+		Events []model.DomainEventDTO `json:"events"`
+	}
+	if err = json.Unmarshal(body, &batch); err != nil {
+		r.t.Errorf("decode body: %v", err)
+	}
+	r.mu.Lock()
+	r.events = append(r.events, batch.Events)
+	r.mu.Unlock()
+	w.WriteHeader(r.status)
+}
+
+func newTestWebhookSink(t *testing.T, rcv *sinkReceiver) *WebhookSink {
+	t.Helper()
+	srv := httptest.NewServer(rcv)
+	t.Cleanup(srv.Close)
+	sink, err := NewWebhookSink(srv.URL, testSecret, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func TestWebhookPublish(t *testing.T) {
+	rcv := &sinkReceiver{t: t, secret: testSecret, status: http.StatusNoContent}
+	sink := newTestWebhookSink(t, rcv)
+
+	events, _ := testEvents(t)
+	if err := sink.Publish(context.Background(), events); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(rcv.events) != 1 || len(rcv.events[0]) != len(events) {
+		t.Fatalf("receiver got batches %v, want one of %d events", rcv.events, len(events))
+	}
+	for i, got := range rcv.events[0] {
+		want := events[i]
+		if got.ID != want.ID || got.Type != want.Type || got.AggregateID != want.AggregateID || !got.OccurredAt.Equal(want.OccurredAt) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestWebhookPublishFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		status int
+	}{
+		{name: "rejected", secret: testSecret, status: http.StatusServiceUnavailable},
+		{name: "signed with another secret", secret: "other-secret", status: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		rcv := &sinkReceiver{t: t, secret: tt.secret, status: tt.status}
+		sink := newTestWebhookSink(t, rcv)
+
+		events, _ := testEvents(t)
+		if err := sink.Publish(context.Background(), events); err == nil {
+			t.Errorf("%s: Publish succeeded, want an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound error
+	ErrNotFound = errors.New("blob not found")
+
+	// ErrTooLarge error
+	ErrTooLarge = errors.New("blob is too large")
+
+	// ErrBadKey error
+	ErrBadKey = errors.New("bad blob key")
+)
+
+// BlobStore keeps binary objects by key. Keys are slash separated relative
+// paths such as "todos/<todo id>/<attachment id>"
+type BlobStore interface {
+	// Put stores everything read from r under key. size is the length of the
+	// content or -1 when it is not known in advance
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object, the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object, deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// LimitedReader reads from R and fails with ErrTooLarge once more than Max
+// bytes were read. N is the number of bytes read so far
+type LimitedReader struct {
+	R   io.Reader
+	Max int64
+	N   int64
+}
+
+func (l *LimitedReader) Read(p []byte) (int, error) {
+	n, err := l.R.Read(p)
+	l.N += int64(n)
+	if l.N > l.Max {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
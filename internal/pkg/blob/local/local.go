@@ -0,0 +1,96 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+)
+
+// Checking whether the interface "BlobStore" implements the structure "Store"
+var _ blob.BlobStore = (*Store)(nil)
+
+// Store keeps blobs as files under the root directory
+type Store struct {
+	root string
+	log  *zap.Logger
+}
+
+func New(root string, log *zap.Logger) (*Store, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("error while creating blob directory: %w", err)
+	}
+	return &Store{
+		root: root,
+		log:  log.Named("local-blob"),
+	}, nil
+}
+
+// Put writes the blob to a temporary file first, so that a failed or too
+// large upload never leaves a partial file under the key
+func (s *Store) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("error while creating blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("error while creating blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("error while writing blob file: %w", err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *Store) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blob.ErrNotFound
+	}
+	return f, err
+}
+
+func (s *Store) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps the key to a file under root, keys escaping root are rejected
+func (s *Store) path(key string) (string, error) {
+	clean := path.Clean(key)
+	if key == "" || clean != key || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", blob.ErrBadKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
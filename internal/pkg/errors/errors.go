@@ -68,6 +68,117 @@ var (
 	//ErrBadRequestId error
 	ErrBadRequestId = errors.New("got error while validating id")
 
+	// ErrBadPosition error
+	ErrBadPosition = errors.New("neighbour todos must belong to the target column")
+
+	// ErrColumnsMismatch error
+	ErrColumnsMismatch = errors.New("columns must list every column of the project exactly once")
+
+	// ErrColumnNotEmpty error
+	ErrColumnNotEmpty = errors.New("column still has todos, move them to another column or delete them with cascade")
+
+	// ErrColumnTarget error
+	ErrColumnTarget = errors.New("target column for todos was not found")
+
+	// ErrWipLimitReached error
+	ErrWipLimitReached = errors.New("column reached its WIP limit")
+
+	// ErrBadWipLimit error
+	ErrBadWipLimit = errors.New("wip limit must be positive")
+
+	// ErrTemplateNotFound error
+	ErrTemplateNotFound = errors.New("project template was not found")
+
+	// ErrBlocked error
+	ErrBlocked = errors.New("todo has incomplete blockers")
+
+	// ErrDependencyCycle error
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+	// ErrBadDependency error
+	ErrBadDependency = errors.New("todos must be different and belong to the same project")
+
+	// ErrBadDueDate error
+	ErrBadDueDate = errors.New("due date must look like 2006-01-02")
+
+	// ErrArchived error
+	ErrArchived = errors.New("todo is archived")
+
+	// ErrNoFile error
+	ErrNoFile = errors.New("multipart form has no \"file\" part")
+
+	// ErrFileTooLarge error
+	ErrFileTooLarge = errors.New("file is too large")
+
+	// ErrFileType error
+	ErrFileType = errors.New("file type is not allowed")
+
+	// ErrBadBulkOperation error
+	ErrBadBulkOperation = errors.New("unknown bulk operation")
+
+	// ErrBadBulkMode error
+	ErrBadBulkMode = errors.New("bulk mode must be all_or_nothing or best_effort")
+
+	// ErrTooManyOperations error
+	ErrTooManyOperations = errors.New("bulk request must have from 1 to 100 operations")
+
+	// ErrAssigneeNotFound error
+	ErrAssigneeNotFound = errors.New("assignee was not found")
+
+	// ErrEmptyName error
+	ErrEmptyName = errors.New("name must not be empty")
+
+	// ErrProjectTarget error
+	ErrProjectTarget = errors.New("target project for todo was not found")
+
+	// ErrProjectColumn error
+	ErrProjectColumn = errors.New("moving todo to another project needs a column of that project")
+
+	// ErrCompletionConflict error
+	ErrCompletionConflict = errors.New("is_completed contradicts the target column")
+
+	// ErrVersionMismatch error
+	ErrVersionMismatch = errors.New("version does not match If-Match")
+
+	// ErrBadIfMatch error
+	ErrBadIfMatch = errors.New("If-Match must be \"*\" or one strong ETag")
+
+	// ErrIdempotencyInProgress error
+	ErrIdempotencyInProgress = errors.New("a request with this Idempotency-Key is in progress")
+
+	// ErrIdempotencyKeyReuse error
+	ErrIdempotencyKeyReuse = errors.New("Idempotency-Key was used for another request")
+
+	// ErrBadIdempotencyKey error
+	ErrBadIdempotencyKey = errors.New("Idempotency-Key must be at most 255 characters")
+
+	// ErrIdempotentBodyTooLarge error
+	ErrIdempotentBodyTooLarge = errors.New("request body is too large to be sent with an Idempotency-Key")
+
+	// ErrBadWebhookURL error
+	ErrBadWebhookURL = errors.New("webhook url must be an absolute http or https URL")
+
+	// ErrBadWebhookEvent error
+	ErrBadWebhookEvent = errors.New("webhook events must look like \"todo.create\"")
+
+	// ErrNoOrganization error
+	ErrNoOrganization = errors.New("organization is not selected")
+
+	// ErrNotMember error
+	ErrNotMember = errors.New("user is not a member of the organization")
+
+	// ErrBadRole error
+	ErrBadRole = errors.New("role must be owner, admin or member")
+
+	// ErrRoleTooLow error
+	ErrRoleTooLow = errors.New("role in the organization does not allow this")
+
+	// ErrLastOwner error
+	ErrLastOwner = errors.New("organization must keep at least one owner")
+
+	// ErrPersonalOwner error
+	ErrPersonalOwner = errors.New("user can not leave or stop owning their personal organization")
+
 	//ErrInternalServer error
 	ErrInternalServer = errors.New("failed to retrieve todo")
 )
@@ -0,0 +1,29 @@
+// Package mention finds @login mentions in free text.
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pattern matches "@login" at the start of the text or after a whitespace.
+// Logins are e-mail addresses, so "@user@example.com" is a single mention
+var pattern = regexp.MustCompile(`(?:^|\s)@([\w.+-]+(?:@[\w-]+(?:\.[\w-]+)*)?)`)
+
+// Parse returns the distinct logins mentioned in text, in order of first
+// appearance. Trailing punctuation is not part of a login
+func Parse(text string) []string {
+	var (
+		logins []string
+		seen   = make(map[string]bool)
+	)
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		login := strings.TrimRight(match[1], ".")
+		if login == "" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		logins = append(logins, login)
+	}
+	return logins
+}
@@ -0,0 +1,38 @@
+package mention
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "no mentions here", want: nil},
+		{text: "@alice", want: []string{"alice"}},
+		{text: "ping @alice@example.com please", want: []string{"alice@example.com"}},
+		{text: "@bob.smith+todo@mail.example.org", want: []string{"bob.smith+todo@mail.example.org"}},
+		// an e-mail address in the text is not a mention
+		{text: "write to alice@example.com", want: nil},
+		{text: "mail:@alice", want: nil},
+		{text: "done, @alice@example.com.", want: []string{"alice@example.com"}},
+		// a mention starts the text or follows a whitespace
+		{text: "@alice, @bob! (@carol)", want: []string{"alice", "bob"}},
+		{text: "@alice...", want: []string{"alice"}},
+		{text: "@ alone", want: nil},
+		{text: "@.", want: nil},
+		{text: "@bob @alice @bob\n@alice", want: []string{"bob", "alice"}},
+		{text: "@Alice @alice", want: []string{"Alice", "alice"}},
+		// logins are not checked here, unknown ones are dropped by the storage
+		{text: "@nobody@example.com", want: []string{"nobody@example.com"}},
+		{text: "tab\t@alice\n@bob", want: []string{"alice", "bob"}},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package poller runs the background loops of the server: they start with
+// the application, get their own context and are waited for on shutdown
+package poller
+
+import (
+	"context"
+	"time"
+)
+
+// Poller runs one loop in the background until it is shut down. The zero
+// value is ready to use
+type Poller struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Go starts loop in a goroutine. The start context of the application only
+// bounds the startup, so the loop gets its own context, done on Shutdown
+func (p *Poller) Go(loop func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		loop(ctx)
+	}()
+}
+
+// Every calls poll right away and then on every tick of interval
+func (p *Poller) Every(interval time.Duration, poll func(ctx context.Context)) {
+	p.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			poll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Shutdown stops the loop and waits until it returns or ctx is done. A loop
+// that was never started is left alone
+func (p *Poller) Shutdown(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain calls once until it handles fewer than size items or fails. A full
+// batch means more work may be waiting, so it is not put off until the next
+// tick
+func Drain(ctx context.Context, size int, once func(ctx context.Context) (int, error)) error {
+	for {
+		n, err := once(ctx)
+		if err != nil || n < size || ctx.Err() != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	errBroken := errors.New("broken")
+	tests := []struct {
+		name      string
+		batches   []int
+		failAt    int
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "short batch", batches: []int{3}, failAt: -1, wantCalls: 1},
+		{name: "full batches", batches: []int{10, 10, 4}, failAt: -1, wantCalls: 3},
+		{name: "empty after full", batches: []int{10, 0}, failAt: -1, wantCalls: 2},
+		{name: "error stops", batches: []int{10, 10, 10}, failAt: 1, wantCalls: 2, wantErr: errBroken},
+	}
+	for _, tt := range tests {
+		var calls int
+		err := Drain(context.Background(), 10, func(context.Context) (int, error) {
+			calls++
+			if calls-1 == tt.failAt {
+				return 0, errBroken
+			}
+			return tt.batches[calls-1], nil
+		})
+		if !errors.Is(err, tt.wantErr) || calls != tt.wantCalls {
+			t.Errorf("%s: Drain made %d calls with error %v, want %d calls with error %v",
+				tt.name, calls, err, tt.wantCalls, tt.wantErr)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	var p Poller
+	polled := make(chan struct{}, 1)
+	p.Every(time.Hour, func(ctx context.Context) {
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+	})
+
+	// the first poll does not wait for a tick
+	select {
+	case <-polled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Every did not poll right away")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestShutdownWaits(t *testing.T) {
+	var p Poller
+	release := make(chan struct{})
+	p.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown of a stuck loop = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown after the loop returned: %v", err)
+	}
+}
+
+func TestShutdownNotStarted(t *testing.T) {
+	var p Poller
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown of a poller never started: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package rank implements fractional indexing: string keys that can always be
+// placed between two other keys, so reordering one item never rewrites its
+// neighbours.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits is the alphabet of a rank key. Its order matches byte order, so keys
+// compare correctly with plain string comparison (and with COLLATE "C" in SQL).
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidKey error
+	ErrInvalidKey = errors.New("invalid rank key")
+
+	// ErrKeysOutOfOrder error
+	ErrKeysOutOfOrder = errors.New("rank keys are out of order")
+)
+
+// First returns the key for the very first item of an empty list.
+func First() string {
+	return Between("", "")
+}
+
+// After returns a key ordered after prev. Appending is the most common
+// operation, so unlike Between it bumps the first digit that can still grow
+// instead of halving the gap, which keeps keys short.
+func After(prev string) string {
+	for i := 0; i < len(prev); i++ {
+		d := strings.IndexByte(digits, prev[i])
+		if d < len(digits)-1 {
+			return prev[:i] + string(digits[d+1])
+		}
+	}
+	return prev + string(digits[1])
+}
+
+// Between returns a key strictly between prev and next. An empty prev means
+// "start of the list", an empty next means "end of the list". Callers must
+// pass valid keys with prev < next; otherwise the result is undefined, use
+// BetweenChecked to validate them first.
+func Between(prev, next string) string {
+	return midpoint(prev, next)
+}
+
+// BetweenChecked validates both keys before calling Between.
+func BetweenChecked(prev, next string) (string, error) {
+	if !Valid(prev) || !Valid(next) {
+		return "", ErrInvalidKey
+	}
+	if next != "" && prev >= next {
+		return "", ErrKeysOutOfOrder
+	}
+	return midpoint(prev, next), nil
+}
+
+// Valid reports whether key is a well-formed rank key. The empty key is
+// valid and stands for an open bound.
+func Valid(key string) bool {
+	if key == "" {
+		return true
+	}
+	if key[len(key)-1] == digits[0] {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// midpoint is the algorithm from "fractional-indexing" by David Greenspan:
+// keys are base-62 fractions without trailing zeroes.
+func midpoint(a, b string) string {
+	if b != "" {
+		// skip the common prefix, treating missing digits of a as zeroes
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(digits, a[0])
+	}
+	digitB := len(digits)
+	if b != "" {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(digits[(digitA+digitB+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[digitA]) + midpoint(tail(a, 1), "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
@@ -0,0 +1,123 @@
+package rank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		prev, next string
+	}{
+		{prev: "", next: ""},
+		{prev: "", next: "V"},
+		{prev: "V", next: ""},
+		{prev: "a", next: "b"},
+		{prev: "a", next: "a1"},
+		{prev: "a0V", next: "a1"},
+		{prev: "a", next: "a01"},
+		{prev: "az", next: "b"},
+		{prev: "y", next: "z"},
+		{prev: "z", next: ""},
+		{prev: "", next: "01"},
+		{prev: "0000000001V", next: "0000000002V"},
+	}
+	for _, tt := range tests {
+		got := Between(tt.prev, tt.next)
+		if !Valid(got) {
+			t.Errorf("Between(%q, %q) = %q, not a valid key", tt.prev, tt.next, got)
+		}
+		if got <= tt.prev || (tt.next != "" && got >= tt.next) {
+			t.Errorf("Between(%q, %q) = %q, not strictly between", tt.prev, tt.next, got)
+		}
+	}
+}
+
+// TestBetweenRepeated inserts again and again at both ends of a shrinking gap
+func TestBetweenRepeated(t *testing.T) {
+	prev, next := First(), After(First())
+	for i := 0; i < 200; i++ {
+		mid := Between(prev, next)
+		if !(prev < mid && mid < next) || !Valid(mid) {
+			t.Fatalf("step %d: Between(%q, %q) = %q", i, prev, next, mid)
+		}
+		if i%2 == 0 {
+			prev = mid
+		} else {
+			next = mid
+		}
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		prev string
+		want string
+	}{
+		{prev: "", want: "1"},
+		{prev: "V", want: "W"},
+		{prev: "a1", want: "b"},
+		{prev: "z", want: "z1"},
+		{prev: "zz", want: "zz1"},
+		{prev: "zy", want: "zz"},
+		// keys backfilled by the pgx migration
+		{prev: "0000000001V", want: "1"},
+		{prev: "0000000012V", want: "1"},
+	}
+	for _, tt := range tests {
+		got := After(tt.prev)
+		if got != tt.want {
+			t.Errorf("After(%q) = %q, want %q", tt.prev, got, tt.want)
+		}
+		if got <= tt.prev || !Valid(got) {
+			t.Errorf("After(%q) = %q, not a valid key after it", tt.prev, got)
+		}
+	}
+}
+
+func TestBetweenChecked(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		wantErr    error
+	}{
+		{prev: "", next: ""},
+		{prev: "a", next: "b"},
+		{prev: "a", next: ""},
+		{prev: "a0", next: "b", wantErr: ErrInvalidKey},
+		{prev: "a", next: "b0", wantErr: ErrInvalidKey},
+		{prev: "a-", next: "b", wantErr: ErrInvalidKey},
+		{prev: "b", next: "a", wantErr: ErrKeysOutOfOrder},
+		{prev: "a", next: "a", wantErr: ErrKeysOutOfOrder},
+	}
+	for _, tt := range tests {
+		got, err := BetweenChecked(tt.prev, tt.next)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("BetweenChecked(%q, %q) error = %v, want %v", tt.prev, tt.next, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (got <= tt.prev || (tt.next != "" && got >= tt.next)) {
+			t.Errorf("BetweenChecked(%q, %q) = %q, not strictly between", tt.prev, tt.next, got)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "", want: true},
+		{key: "V", want: true},
+		{key: "a01", want: true},
+		{key: "0000000001V", want: true},
+		{key: "0", want: false},
+		{key: "a0", want: false},
+		{key: "a b", want: false},
+		{key: "é", want: false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.key); got != tt.want {
+			t.Errorf("Valid(%q) = %t, want %t", tt.key, got, tt.want)
+		}
+	}
+}
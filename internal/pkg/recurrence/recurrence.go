@@ -0,0 +1,245 @@
+// Package recurrence implements the subset of iCalendar RRULE used by
+// recurring todos: daily, weekly on given weekdays and monthly by day.
+//
+// Occurrences are calendar dates, times of day are ignored and every date is
+// normalized to midnight UTC.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBadRule = errors.New("unsupported recurrence rule")
+
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+)
+
+const maxInterval = 1000
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Clock tells the current time. Storage takes it as a dependency, so that
+// occurrence generation can be driven by a fake clock
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Rule is a parsed recurrence rule. Empty Weekdays of a weekly rule and zero
+// MonthDay of a monthly rule repeat the weekday or the day of the previous
+// occurrence
+type Rule struct {
+	Freq     Freq
+	Interval int
+	Weekdays []time.Weekday
+	MonthDay int
+}
+
+// Parse parses rules like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" or
+// "RRULE:FREQ=MONTHLY;BYMONTHDAY=15"
+func Parse(raw string) (*Rule, error) {
+	raw = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(raw)), "RRULE:")
+	rule := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(raw, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrBadRule, part)
+		}
+		switch key {
+		case "FREQ":
+			switch f := Freq(value); f {
+			case Daily, Weekly, Monthly:
+				rule.Freq = f
+			default:
+				return nil, fmt.Errorf("%w: frequency %q", ErrBadRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxInterval {
+				return nil, fmt.Errorf("%w: interval %q", ErrBadRule, value)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, err := parseWeekday(code)
+				if err != nil {
+					return nil, err
+				}
+				if !containsWeekday(rule.Weekdays, day) {
+					rule.Weekdays = append(rule.Weekdays, day)
+				}
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 31 {
+				return nil, fmt.Errorf("%w: month day %q", ErrBadRule, value)
+			}
+			rule.MonthDay = n
+		default:
+			return nil, fmt.Errorf("%w: part %q", ErrBadRule, key)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrBadRule)
+	case len(rule.Weekdays) > 0 && rule.Freq != Weekly:
+		return nil, fmt.Errorf("%w: BYDAY needs FREQ=WEEKLY", ErrBadRule)
+	case rule.MonthDay != 0 && rule.Freq != Monthly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY needs FREQ=MONTHLY", ErrBadRule)
+	}
+	return rule, nil
+}
+
+// Anchor fills the weekday of a weekly rule and the day of a monthly rule
+// from the first occurrence, so that later occurrences do not drift when a
+// month is too short for the day
+func (r *Rule) Anchor(first time.Time) {
+	switch {
+	case r.Freq == Weekly && len(r.Weekdays) == 0:
+		r.Weekdays = []time.Weekday{first.Weekday()}
+	case r.Freq == Monthly && r.MonthDay == 0:
+		r.MonthDay = first.Day()
+	}
+}
+
+// String formats the rule in its canonical form, which is what gets stored
+func (r *Rule) String() string {
+	var b strings.Builder
+	b.WriteString("FREQ=")
+	b.WriteString(string(r.Freq))
+	if r.Interval > 1 {
+		b.WriteString(";INTERVAL=")
+		b.WriteString(strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		codes := make([]string, 0, len(r.Weekdays))
+		// monday first, like in the rest of the week math
+		for i := 1; i <= 7; i++ {
+			if day := time.Weekday(i % 7); containsWeekday(r.Weekdays, day) {
+				codes = append(codes, weekdayCodes[day])
+			}
+		}
+		b.WriteString(";BYDAY=")
+		b.WriteString(strings.Join(codes, ","))
+	}
+	if r.MonthDay != 0 {
+		b.WriteString(";BYMONTHDAY=")
+		b.WriteString(strconv.Itoa(r.MonthDay))
+	}
+	return b.String()
+}
+
+// Next returns the first occurrence strictly after prev
+func (r *Rule) Next(prev time.Time) time.Time {
+	prev = Date(prev)
+	switch r.Freq {
+	case Weekly:
+		return r.nextWeekly(prev)
+	case Monthly:
+		return r.nextMonthly(prev)
+	default:
+		return prev.AddDate(0, 0, r.Interval)
+	}
+}
+
+// NextAfter returns the occurrence following prev that is not in the past
+// relative to now. Occurrences missed while the todo stayed open are skipped
+func (r *Rule) NextAfter(prev, now time.Time) time.Time {
+	today := Date(now)
+	next := r.Next(prev)
+	for next.Before(today) {
+		next = r.Next(next)
+	}
+	return next
+}
+
+// nextWeekly walks day by day, weeks are counted from the week of prev and
+// start on monday, so INTERVAL=2 means every other week
+func (r *Rule) nextWeekly(prev time.Time) time.Time {
+	days := r.Weekdays
+	if len(days) == 0 {
+		days = []time.Weekday{prev.Weekday()}
+	}
+	start := weekStart(prev)
+	for d := prev.AddDate(0, 0, 1); ; d = d.AddDate(0, 0, 1) {
+		week := int(weekStart(d).Sub(start).Hours()) / (24 * 7)
+		if week%r.Interval == 0 && containsWeekday(days, d.Weekday()) {
+			return d
+		}
+	}
+}
+
+// nextMonthly picks the day in the month of prev or in the months Interval
+// apart from it. Days past the end of a short month fall on its last day
+func (r *Rule) nextMonthly(prev time.Time) time.Time {
+	day := r.MonthDay
+	if day == 0 {
+		day = prev.Day()
+	}
+	for k := 0; ; k += r.Interval {
+		first := time.Date(prev.Year(), prev.Month()+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+		candidate := first.AddDate(0, 0, min(day, daysIn(first))-1)
+		if candidate.After(prev) {
+			return candidate
+		}
+	}
+}
+
+// Date truncates t to midnight UTC of its calendar date
+func Date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+var occurrenceSuffix = regexp.MustCompile(` \(\d{4}-\d{2}-\d{2}\)$`)
+
+// OccurrenceName names the occurrence due on the date. Todo names are unique,
+// so the date is appended in place of the date of the previous occurrence
+func OccurrenceName(name string, due time.Time) string {
+	return occurrenceSuffix.ReplaceAllString(name, "") + " (" + due.Format(time.DateOnly) + ")"
+}
+
+func parseWeekday(code string) (time.Weekday, error) {
+	for i, c := range weekdayCodes {
+		if c == code {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: weekday %q", ErrBadRule, code)
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+func daysIn(first time.Time) int {
+	return first.AddDate(0, 1, -1).Day()
+}
@@ -0,0 +1,266 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock standing still at now
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no time zone %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{raw: "rrule:freq=daily;interval=1", want: "FREQ=DAILY"},
+		{raw: "FREQ=WEEKLY;BYDAY=TH,MO,TH", want: "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{raw: "FREQ=WEEKLY;BYDAY=SU,MO;INTERVAL=2", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU"},
+		{raw: "FREQ=MONTHLY;BYMONTHDAY=31", want: "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{raw: "", wantErr: true},
+		{raw: "FREQ=YEARLY", wantErr: true},
+		{raw: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{raw: "FREQ=DAILY;INTERVAL=1001", wantErr: true},
+		{raw: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{raw: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{raw: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{raw: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{raw: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{raw: "INTERVAL=2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			rule, err := Parse(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrBadRule) {
+					t.Fatalf("got %v, want %v", err, ErrBadRule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	newYork := location(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		rule string
+		prev time.Time
+		want []time.Time
+	}{
+		{
+			name: "daily",
+			rule: "FREQ=DAILY;INTERVAL=3",
+			prev: date(2024, time.December, 30),
+			want: []time.Time{date(2025, time.January, 2), date(2025, time.January, 5)},
+		},
+		{
+			name: "weekly by day",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			prev: date(2024, time.January, 1),
+			want: []time.Time{date(2024, time.January, 4), date(2024, time.January, 8), date(2024, time.January, 11)},
+		},
+		{
+			name: "every other week by day",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			prev: date(2024, time.January, 1),
+			want: []time.Time{date(2024, time.January, 5), date(2024, time.January, 15), date(2024, time.January, 19)},
+		},
+		{
+			name: "weekly on sunday closes the week",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU",
+			prev: date(2024, time.January, 1),
+			want: []time.Time{date(2024, time.January, 7), date(2024, time.January, 15), date(2024, time.January, 21)},
+		},
+		{
+			name: "month end in a leap year",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31",
+			prev: date(2024, time.January, 31),
+			want: []time.Time{
+				date(2024, time.February, 29), date(2024, time.March, 31),
+				date(2024, time.April, 30), date(2024, time.May, 31),
+			},
+		},
+		{
+			name: "month end in a common year",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=30",
+			prev: date(2023, time.January, 30),
+			want: []time.Time{date(2023, time.February, 28), date(2023, time.March, 30)},
+		},
+		{
+			name: "month end over the new year",
+			rule: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31",
+			prev: date(2024, time.November, 30),
+			want: []time.Time{date(2025, time.January, 31), date(2025, time.March, 31)},
+		},
+		{
+			name: "leap day yearly",
+			rule: "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=29",
+			prev: date(2024, time.February, 29),
+			want: []time.Time{date(2025, time.February, 28), date(2026, time.February, 28)},
+		},
+		{
+			name: "daily over spring forward",
+			rule: "FREQ=DAILY",
+			prev: time.Date(2024, time.March, 30, 23, 30, 0, 0, berlin),
+			want: []time.Time{date(2024, time.March, 31), date(2024, time.April, 1)},
+		},
+		{
+			name: "daily over fall back",
+			rule: "FREQ=DAILY",
+			prev: time.Date(2024, time.November, 2, 23, 59, 0, 0, newYork),
+			want: []time.Time{date(2024, time.November, 3), date(2024, time.November, 4)},
+		},
+		{
+			// 00:30 on monday in Berlin is still sunday in UTC, the local
+			// date is the one that counts
+			name: "weekly from local midnight after spring forward",
+			rule: "FREQ=WEEKLY;BYDAY=MO",
+			prev: time.Date(2024, time.April, 1, 0, 30, 0, 0, berlin),
+			want: []time.Time{date(2024, time.April, 8), date(2024, time.April, 15)},
+		},
+		{
+			name: "monthly over fall back",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=3",
+			prev: time.Date(2024, time.October, 3, 22, 0, 0, 0, newYork),
+			want: []time.Time{date(2024, time.November, 3), date(2024, time.December, 3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prev := tt.prev
+			for i, want := range tt.want {
+				got := rule.Next(prev)
+				if !got.Equal(want) || got.Location() != time.UTC {
+					t.Fatalf("occurrence %d: got %s, want %s", i+1, got, want)
+				}
+				prev = got
+			}
+		})
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	weekly, _ := Parse("FREQ=WEEKLY")
+	weekly.Anchor(date(2024, time.January, 3))
+	if got := weekly.String(); got != "FREQ=WEEKLY;BYDAY=WE" {
+		t.Fatalf("got %q, want the weekday of the first occurrence", got)
+	}
+
+	// without the anchor the day drifts to the end of february for good
+	monthly, _ := Parse("FREQ=MONTHLY")
+	monthly.Anchor(date(2024, time.January, 31))
+	if got := monthly.Next(monthly.Next(date(2024, time.January, 31))); !got.Equal(date(2024, time.March, 31)) {
+		t.Fatalf("got %s, want 2024-03-31", got)
+	}
+}
+
+func TestNextAfter(t *testing.T) {
+	newYork := location(t, "America/New_York")
+
+	tests := []struct {
+		name  string
+		rule  string
+		due   time.Time
+		clock Clock
+		want  time.Time
+	}{
+		{
+			name:  "due in the future",
+			rule:  "FREQ=DAILY",
+			due:   date(2024, time.June, 1),
+			clock: fakeClock{now: time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)},
+			want:  date(2024, time.June, 2),
+		},
+		{
+			name:  "missed days are skipped up to today",
+			rule:  "FREQ=DAILY",
+			due:   date(2024, time.January, 1),
+			clock: fakeClock{now: time.Date(2024, time.January, 10, 23, 59, 0, 0, time.UTC)},
+			want:  date(2024, time.January, 10),
+		},
+		{
+			name:  "missed weeks are skipped",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TH",
+			due:   date(2024, time.January, 1),
+			clock: fakeClock{now: time.Date(2024, time.January, 17, 8, 0, 0, 0, time.UTC)},
+			want:  date(2024, time.January, 18),
+		},
+		{
+			name:  "missed month ends are skipped",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			due:   date(2024, time.January, 31),
+			clock: fakeClock{now: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)},
+			want:  date(2024, time.May, 31),
+		},
+		{
+			// late in the evening of the day of the clock change it is
+			// already tomorrow in UTC, today is the date of the clock
+			name:  "today of the clock on the day of spring forward",
+			rule:  "FREQ=DAILY",
+			due:   date(2024, time.March, 1),
+			clock: fakeClock{now: time.Date(2024, time.March, 10, 23, 30, 0, 0, newYork)},
+			want:  date(2024, time.March, 10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.NextAfter(tt.due, tt.clock.Now()); !got.Equal(tt.want) {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrenceName(t *testing.T) {
+	due := date(2024, time.March, 4)
+	tests := []struct{ name, want string }{
+		{name: "Standup", want: "Standup (2024-03-04)"},
+		{name: "Standup (2024-02-26)", want: "Standup (2024-03-04)"},
+		{name: "Report (draft)", want: "Report (draft) (2024-03-04)"},
+	}
+	for _, tt := range tests {
+		if got := OccurrenceName(tt.name, due); got != tt.want {
+			t.Errorf("got %q for %q, want %q", got, tt.name, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package migrator
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"io/fs"
 
@@ -11,16 +12,23 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/tern"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/tern/sqlite"
+	sqlitedb "github.com/todo-enjoers/backend_v1/pkg/sqlite"
 )
 
 var versionTableName = "versions"
 
+// Migrator runs the migrations of the storage backend configured in cfg:
+// tern on a pgx connection for Postgres, or its SQLite counterpart on the
+// database file
 type Migrator struct {
 	cfg        *config.Config
 	log        *zap.Logger
-	migrator   *migrate.Migrator
+	migrator   tern.Tern
 	migrations fs.FS
 	conn       *pgx.Conn
+	sqlite     *sql.DB
 }
 
 func New(ctx context.Context, cfg *config.Config, log *zap.Logger, migrations fs.FS) (*Migrator, error) {
@@ -39,6 +47,7 @@ func New(ctx context.Context, cfg *config.Config, log *zap.Logger, migrations fs
 	m := &Migrator{
 		migrator:   nil,
 		conn:       nil,
+		sqlite:     nil,
 		migrations: migrations,
 		cfg:        cfg,
 		log:        log.Named("migrator"),
@@ -72,11 +81,16 @@ func (m *Migrator) GetVersion(ctx context.Context) (int32, error) {
 }
 
 func (m *Migrator) Close(ctx context.Context) {
-	if m == nil || m.conn == nil {
+	if m == nil {
 		return
 	}
 
-	_ = m.conn.Close(ctx)
+	if m.conn != nil {
+		_ = m.conn.Close(ctx)
+	}
+	if m.sqlite != nil {
+		_ = m.sqlite.Close()
+	}
 }
 
 func (m *Migrator) loadMigrations() (err error) {
@@ -89,6 +103,16 @@ func (m *Migrator) loadMigrations() (err error) {
 }
 
 func (m *Migrator) initConn(ctx context.Context) (err error) {
+	if m.isSQLite() {
+		m.sqlite, err = sqlitedb.New(m.cfg, m.log)
+		if err != nil {
+			m.log.Error("failed to open database", zap.Error(err))
+			return fmt.Errorf("sqlite.New: %w", err)
+		}
+		m.log.Debug("opened database")
+		return nil
+	}
+
 	m.conn, err = pgx.Connect(ctx, m.cfg.Postgres.GetURI())
 	if err != nil {
 		m.log.Error("failed to connect to database", zap.Error(err))
@@ -99,9 +123,21 @@ func (m *Migrator) initConn(ctx context.Context) (err error) {
 }
 
 func (m *Migrator) initMigrator(ctx context.Context) (err error) {
+	if m.isSQLite() {
+		m.migrator, err = sqlite.NewMigrator(ctx, m.sqlite, versionTableName)
+		if err != nil {
+			return fmt.Errorf("sqlite.NewMigrator: %w", err)
+		}
+		return nil
+	}
+
 	m.migrator, err = migrate.NewMigrator(ctx, m.conn, versionTableName)
 	if err != nil {
 		return fmt.Errorf("migrate.NewMigrator: %w", err)
 	}
 	return nil
 }
+
+func (m *Migrator) isSQLite() bool {
+	return m.cfg.Storage != nil && m.cfg.Storage.Backend == "sqlite"
+}
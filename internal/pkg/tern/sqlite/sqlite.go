@@ -0,0 +1,191 @@
+// Package sqlite implements tern.Tern for SQLite. Migrations are read in the
+// format of tern: files named like 001_users.sql holding the up statements
+// above the "---- create above / drop below ----" line and the down
+// statements below it. Templates of tern are not supported
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/jackc/tern/v2/migrate"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/tern"
+)
+
+const separator = "---- create above / drop below ----"
+
+// Checking whether the interface "tern.Tern" implements the structure "Migrator"
+var _ tern.Tern = (*Migrator)(nil)
+
+var (
+	ErrNoMigrations = errors.New("migrations not found")
+	ErrBadVersion   = errors.New("bad migration version")
+	ErrIrreversible = errors.New("irreversible migration")
+	ErrForeignKey   = errors.New("migration breaks a foreign key")
+)
+
+type Migrator struct {
+	db           *sql.DB
+	versionTable string
+	Migrations   []*migrate.Migration
+}
+
+// NewMigrator returns a migrator keeping the current version in
+// versionTable, the table is created when missing
+func NewMigrator(ctx context.Context, db *sql.DB, versionTable string) (*Migrator, error) {
+	m := &Migrator{
+		db:           db,
+		versionTable: versionTable,
+	}
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+versionTable+` (version INTEGER NOT NULL);
+INSERT INTO `+versionTable+` (version) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM `+versionTable+`);`)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating version table: %w", err)
+	}
+	return m, nil
+}
+
+func (m *Migrator) LoadMigrations(migrations fs.FS) error {
+	paths, err := migrate.FindMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return ErrNoMigrations
+	}
+
+	for _, p := range paths {
+		body, err := fs.ReadFile(migrations, p)
+		if err != nil {
+			return err
+		}
+		up, down, _ := strings.Cut(string(body), separator)
+		m.Migrations = append(m.Migrations, &migrate.Migration{
+			Sequence: int32(len(m.Migrations)) + 1,
+			Name:     path.Base(p),
+			UpSQL:    strings.TrimSpace(up),
+			DownSQL:  strings.TrimSpace(down),
+		})
+	}
+	return nil
+}
+
+// Migrate runs pending migrations
+func (m *Migrator) Migrate(ctx context.Context) error {
+	return m.MigrateTo(ctx, int32(len(m.Migrations)))
+}
+
+// MigrateTo migrates up or down to version, every migration runs in a
+// transaction of its own together with the update of the version
+func (m *Migrator) MigrateTo(ctx context.Context, version int32) error {
+	if version < 0 || int(version) > len(m.Migrations) {
+		return fmt.Errorf("%w: %d is outside of 0..%d", ErrBadVersion, version, len(m.Migrations))
+	}
+
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if int(current) > len(m.Migrations) {
+		return fmt.Errorf("%w: current version %d is past the last migration", ErrBadVersion, current)
+	}
+
+	for current != version {
+		var (
+			migration *migrate.Migration
+			query     string
+			next      int32
+		)
+		if current < version {
+			migration, next = m.Migrations[current], current+1
+			query = migration.UpSQL
+		} else {
+			migration, next = m.Migrations[current-1], current-1
+			query = migration.DownSQL
+			if query == "" {
+				return fmt.Errorf("%w: %s", ErrIrreversible, migration.Name)
+			}
+		}
+		if err = m.run(ctx, query, next); err != nil {
+			return fmt.Errorf("%s: %w", migration.Name, err)
+		}
+		current = next
+	}
+	return nil
+}
+
+// run applies one migration with foreign keys off, so that it may rebuild a
+// table the way SQLite documents it: a dropped table does not take the rows
+// referencing it along. The foreign keys are checked before the commit
+func (m *Migrator) run(ctx context.Context, query string, version int32) error {
+	// the pragma is a no-op within a transaction and holds for the connection
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = ON`); err != nil {
+			// a connection without foreign keys must not go back to the pool
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = conn.Close()
+	}()
+	if _, err = conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	if err = checkForeignKeys(ctx, tx); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE `+m.versionTable+` SET version = ?1`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkForeignKeys fails when a row references a missing one
+func checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var (
+			table  string
+			rowID  sql.NullInt64
+			parent string
+			fkID   int
+		)
+		if err = rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: a row of %s references a missing row of %s", ErrForeignKey, table, parent)
+	}
+	return rows.Err()
+}
+
+func (m *Migrator) GetCurrentVersion(ctx context.Context) (int32, error) {
+	var version int32
+	err := m.db.QueryRowContext(ctx, `SELECT version FROM `+m.versionTable).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error while getting version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,124 @@
+package purger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/poller"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Purger periodically deletes for good the projects and todos that stayed in
+// the trash longer than the configured retention together with the blobs of
+// their attachments, expired idempotency keys and published domain events
+// past their retention
+type Purger struct {
+	store  storage.Interface
+	blobs  blob.BlobStore
+	cfg    *config.Trash
+	outbox *config.Outbox
+	log    *zap.Logger
+	poller poller.Poller
+}
+
+func New(store storage.Interface, blobs blob.BlobStore, cfg *config.Config, log *zap.Logger) *Purger {
+	return &Purger{
+		store:  store,
+		blobs:  blobs,
+		cfg:    cfg.Trash,
+		outbox: cfg.Outbox,
+		log:    log.Named("purger"),
+	}
+}
+
+func RunPurgerFx(lc fx.Lifecycle, p *Purger) {
+	lc.Append(fx.Hook{
+		OnStart: p.Run,
+		OnStop:  p.Shutdown,
+	})
+}
+
+// Run starts purging in the background until Shutdown
+func (p *Purger) Run(_ context.Context) error {
+	if p.cfg.PurgeInterval() <= 0 {
+		p.log.Info("trash purging is disabled")
+		return nil
+	}
+
+	p.poller.Every(p.cfg.PurgeInterval(), func(ctx context.Context) {
+		p.PurgeOnce(ctx, time.Now())
+	})
+	return nil
+}
+
+func (p *Purger) Shutdown(ctx context.Context) error {
+	return p.poller.Shutdown(ctx)
+}
+
+// PurgeOnce deletes everything that was trashed before now minus retention.
+// Attachments go first, so that the keys of their blobs are known before the
+// rows vanish with their todos. Then projects go, their todos are removed
+// with them. Expired idempotency keys and old published events go last
+func (p *Purger) PurgeOnce(ctx context.Context, now time.Time) {
+	cutoff := now.Add(-p.cfg.Retention())
+
+	keys, err := p.store.Attachment().Purge(ctx, cutoff)
+	if err != nil {
+		p.log.Error("error while purging attachments", zap.Error(err))
+		return
+	}
+	p.deleteBlobs(ctx, keys)
+
+	projects, err := p.store.Project().Purge(ctx, cutoff)
+	if err != nil {
+		p.log.Error("error while purging projects", zap.Error(err))
+		return
+	}
+	todos, err := p.store.Todo().Purge(ctx, cutoff)
+	if err != nil {
+		p.log.Error("error while purging todos", zap.Error(err))
+		return
+	}
+	if projects > 0 || todos > 0 {
+		p.log.Info("purged trash", zap.Int64("projects", projects), zap.Int64("todos", todos))
+	}
+
+	expired, err := p.store.Idempotency().Purge(ctx, now)
+	if err != nil {
+		p.log.Error("error while purging idempotency keys", zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		p.log.Info("purged idempotency keys", zap.Int64("keys", expired))
+	}
+
+	events, err := p.store.Outbox().Purge(ctx, now.Add(-p.outbox.Retention()))
+	if err != nil {
+		p.log.Error("error while purging domain events", zap.Error(err))
+		return
+	}
+	if events > 0 {
+		p.log.Info("purged domain events", zap.Int64("events", events))
+	}
+}
+
+// deleteBlobs deletes the blobs of purged attachments. Their rows are gone
+// already, so a blob failing to delete is only logged and stays orphaned
+func (p *Purger) deleteBlobs(ctx context.Context, keys []string) {
+	var deleted int
+	for _, key := range keys {
+		if err := p.blobs.Delete(ctx, key); err != nil {
+			p.log.Error("error while deleting attachment blob", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		deleted++
+	}
+	if len(keys) > 0 {
+		p.log.Info("purged attachments", zap.Int("attachments", len(keys)), zap.Int("blobs", deleted))
+	}
+}
@@ -0,0 +1,106 @@
+package purger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/blob"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/memory"
+)
+
+// blobs records the keys it is asked to delete, deleting failing fails
+type blobs struct {
+	mu      sync.Mutex
+	deleted []string
+	failing string
+}
+
+func (b *blobs) Put(context.Context, string, io.Reader, int64, string) error {
+	return nil
+}
+
+func (b *blobs) Get(context.Context, string) (io.ReadCloser, error) {
+	return nil, blob.ErrNotFound
+}
+
+func (b *blobs) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if key == b.failing {
+		return errors.New("bucket is unavailable")
+	}
+	b.deleted = append(b.deleted, key)
+	return nil
+}
+
+func TestPurgeOnceDeletesBlobs(t *testing.T) {
+	store := memory.New(zap.NewNop())
+	user := model.UserDTO{ID: uuid.New(), Login: "owner@example.com", Password: "secret"}
+	if err := store.User().Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	ctx := storage.WithTenant(context.Background(), user.ID)
+	project := model.ProjectDTO{ID: uuid.New(), Name: "Board", CreatedBy: user.ID}
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	if err := store.Project().Create(ctx, &project, template.ColumnsFor(project.ID)); err != nil {
+		t.Fatal(err)
+	}
+	attach := func(name string) (model.TodoDTO, model.AttachmentDTO) {
+		todo := model.TodoDTO{ID: uuid.New(), Name: name, ProjectID: project.ID, CreatedBy: user.ID, Column: "To do"}
+		if err := store.Todo().Create(ctx, &todo, false); err != nil {
+			t.Fatal(err)
+		}
+		attachment := model.AttachmentDTO{
+			ID:          uuid.New(),
+			TodoID:      todo.ID,
+			UploadedBy:  user.ID,
+			FileName:    name + ".txt",
+			ContentType: "text/plain",
+			StorageKey:  "todos/" + todo.ID.String() + "/" + name,
+		}
+		if err := store.Attachment().Create(ctx, &attachment); err != nil {
+			t.Fatal(err)
+		}
+		return todo, attachment
+	}
+	trashed, purged := attach("trashed")
+	_, failing := attach("failing")
+	_, kept := attach("kept")
+	for _, id := range []uuid.UUID{trashed.ID, failing.TodoID} {
+		if err := store.Todo().Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := &blobs{failing: failing.StorageKey}
+	p := New(store, b, &config.Config{
+		Trash:  &config.Trash{RetentionHours: 1},
+		Outbox: &config.Outbox{RetentionHours: 1},
+	}, zap.NewNop())
+	p.PurgeOnce(context.Background(), time.Now().Add(2*time.Hour))
+
+	if !slices.Equal(b.deleted, []string{purged.StorageKey}) {
+		t.Fatalf("got deleted blobs %q, want %q", b.deleted, purged.StorageKey)
+	}
+	trash, err := store.Todo().GetTrash(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 0 {
+		t.Fatalf("got %d todos in the trash, want the trash purged even if a blob failed to delete", len(trash))
+	}
+	if _, err = store.Attachment().GetByID(ctx, kept.ID, kept.TodoID); err != nil {
+		t.Fatalf("getting attachment of todo left alone: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+// Entities and actions of activity records
+const (
+	EntityProject = "project"
+	EntityColumn  = "column"
+	EntityTodo    = "todo"
+
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionMove      = "move"
+	ActionReorder   = "reorder"
+	ActionRestore   = "restore"
+	ActionArchive   = "archive"
+	ActionUnarchive = "unarchive"
+)
+
+// DiffSnapshots compares the JSON representations of two snapshots of an
+// entity field by field. before is nil for created entities and after is nil
+// for deleted ones
+func DiffSnapshots(before, after any) (map[string]model.FieldChange, error) {
+	b, err := Snapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := Snapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.FieldChange)
+	for field, value := range b {
+		if other, ok := a[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes[field] = model.FieldChange{Before: value, After: a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok {
+			changes[field] = model.FieldChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// Snapshot turns an entity into a map of its JSON fields, nil pointers give
+// an empty map
+func Snapshot(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	// the version changes with every update, it is not an activity
+	delete(fields, "version")
+	return fields, nil
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the id of the user on whose behalf
+// storage operations run. Mutating operations record it in the activity log
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the user set by WithActor, ok is false for
+// operations not started by a user
+func ActorFromContext(ctx context.Context) (userID uuid.UUID, ok bool) {
+	userID, ok = ctx.Value(actorKey{}).(uuid.UUID)
+	return userID, ok
+}
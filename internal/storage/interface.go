@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/todo-enjoers/backend_v1/internal/model"
+	"time"
 )
 
 type UserStorage interface {
@@ -11,36 +12,149 @@ type UserStorage interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*model.UserDTO, error)
 	GetByLogin(ctx context.Context, login string) (*model.UserDTO, error)
 	ChangePassword(ctx context.Context, password string, id uuid.UUID) error
-	GetAll(ctx context.Context) ([]model.UserDTO, error)
+}
+
+// OrganizationStorage keeps organizations and their members. Every user has
+// a personal organization with the id of the user, created together with the
+// user, and stays its owner. Members are listed and changed in the
+// organization of the context, see WithTenant
+type OrganizationStorage interface {
+	Create(ctx context.Context, org *model.OrganizationDTO) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.OrganizationDTO, error)
+	GetByMember(ctx context.Context, userID uuid.UUID) ([]model.OrganizationDTO, error)
+	GetMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (*model.OrganizationMemberDTO, error)
+	GetMembers(ctx context.Context) ([]model.OrganizationMemberDTO, error)
+	AddMember(ctx context.Context, member *model.OrganizationMemberDTO) error
+	UpdateMemberRole(ctx context.Context, userID uuid.UUID, role string) error
+	RemoveMember(ctx context.Context, userID uuid.UUID) error
 }
 
 type TodoStorage interface {
-	Create(ctx context.Context, todo *model.TodoDTO) error
+	Create(ctx context.Context, todo *model.TodoDTO, force bool) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error)
-	GetAll(ctx context.Context, createdBy uuid.UUID) ([]model.TodoDTO, error)
-	Update(ctx context.Context, todo *model.TodoDTO, id uuid.UUID) error
+	GetAll(ctx context.Context, createdBy uuid.UUID, includeArchived bool) ([]model.TodoDTO, error)
+	Update(ctx context.Context, todo *model.TodoDTO, id uuid.UUID, force bool) error
+	Move(ctx context.Context, id uuid.UUID, move *model.TodoMoveDTO) (*model.TodoDTO, error)
+	Patch(ctx context.Context, id uuid.UUID, userID uuid.UUID, patch *model.TodoPatchDTO) (*model.TodoDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	Bulk(ctx context.Context, userID uuid.UUID, ops []model.TodoBulkOperation, atomic bool) ([]model.TodoBulkResult, error)
+	AddDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error
+	RemoveDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error
+	Archive(ctx context.Context, id uuid.UUID, archived bool) (*model.TodoDTO, error)
+	GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.TodoDTO, error)
+	Restore(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+type ChecklistStorage interface {
+	Create(ctx context.Context, item *model.ChecklistItemDTO) error
+	GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.ChecklistItemDTO, error)
+	GetAll(ctx context.Context, todoID uuid.UUID) ([]model.ChecklistItemDTO, error)
+	Update(ctx context.Context, item *model.ChecklistItemDTO) error
+	Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error
+}
+
+type CommentStorage interface {
+	Create(ctx context.Context, comment *model.CommentDTO) error
+	GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.CommentDTO, error)
+	GetAll(ctx context.Context, todoID uuid.UUID, limit, offset int) ([]model.CommentDTO, int, error)
+	Update(ctx context.Context, comment *model.CommentDTO) error
+	Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error
+}
+
+type AttachmentStorage interface {
+	Create(ctx context.Context, attachment *model.AttachmentDTO) error
+	GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.AttachmentDTO, error)
+	GetAll(ctx context.Context, todoID uuid.UUID) ([]model.AttachmentDTO, error)
+	Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error
+	// Purge deletes the attachments of the todos and projects trashed before
+	// deletedBefore, which are about to be purged, and returns the keys of
+	// their blobs
+	Purge(ctx context.Context, deletedBefore time.Time) ([]string, error)
+}
+
+type ActivityStorage interface {
+	GetByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]model.ActivityDTO, int, error)
+	GetSince(ctx context.Context, projectID uuid.UUID, afterID int64, limit int) ([]model.ActivityDTO, error)
+	LastID(ctx context.Context, projectID uuid.UUID) (int64, error)
 }
 
 type ProjectStorage interface {
 	GetMyByName(ctx context.Context, name string, createdBy uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.ProjectDTO, error)
-	GetMyProjects(ctx context.Context, createdByID uuid.UUID) ([]model.ProjectDTO, error)
+	GetAll(ctx context.Context, includeArchived bool) ([]model.ProjectDTO, error)
+	Archive(ctx context.Context, id uuid.UUID, archived bool) error
 	UpdateName(ctx context.Context, name string, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	Create(ctx context.Context, project *model.ProjectDTO) error
+	Create(ctx context.Context, project *model.ProjectDTO, columns []model.ColumDTO) error
+	GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.ProjectDTO, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+type TemplateStorage interface {
+	Create(ctx context.Context, template *model.ProjectTemplateDTO) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ProjectTemplateDTO, error)
+	GetMyTemplates(ctx context.Context, createdBy uuid.UUID) ([]model.ProjectTemplateDTO, error)
+	Delete(ctx context.Context, id uuid.UUID, createdBy uuid.UUID) error
 }
 type ColumnStorage interface {
 	CreateColumn(ctx context.Context, column *model.ColumDTO) error
-	DeleteColumn(ctx context.Context, name string, projectId uuid.UUID) error
+	DeleteColumn(ctx context.Context, name string, projectId uuid.UUID, moveTo string, cascade bool) error
 	GetColumnByName(ctx context.Context, name string, projectId uuid.UUID) (*model.ColumDTO, error)
 	UpdateColumn(ctx context.Context, column *model.ColumDTO, name string, projectId uuid.UUID) error
 	GetAllColumns(ctx context.Context, projectId uuid.UUID) ([]model.ColumDTO, error)
+	ReorderColumns(ctx context.Context, projectId uuid.UUID, names []string) error
+}
+
+// IdempotencyStorage keeps responses of requests sent with an
+// Idempotency-Key, so that a retried request is answered without running it
+// again. Keys are scoped to the user and the organization of the record
+type IdempotencyStorage interface {
+	Acquire(ctx context.Context, record *model.IdempotencyRecordDTO) (*model.IdempotencyRecordDTO, error)
+	Complete(ctx context.Context, record *model.IdempotencyRecordDTO) error
+	Release(ctx context.Context, record *model.IdempotencyRecordDTO) error
+	Purge(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+// WebhookStorage keeps webhooks of projects and the outbox of their
+// deliveries. Deliveries are queued by the other storages together with the
+// activity record of a change
+type WebhookStorage interface {
+	Create(ctx context.Context, webhook *model.WebhookDTO) error
+	GetByID(ctx context.Context, id uuid.UUID, projectID uuid.UUID) (*model.WebhookDTO, error)
+	GetAll(ctx context.Context, projectID uuid.UUID) ([]model.WebhookDTO, error)
+	Delete(ctx context.Context, id uuid.UUID, projectID uuid.UUID) error
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]model.WebhookDeliveryDTO, int, error)
+	GetDelivery(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) (*model.WebhookDeliveryDTO, error)
+	Redeliver(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) error
+	ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]model.WebhookJobDTO, error)
+	RecordAttempt(ctx context.Context, attempt *model.WebhookAttemptDTO, status string, nextAttemptAt *time.Time) error
+}
+
+// OutboxStorage keeps domain events until a relay publishes them. Events are
+// written by the other storages in the transaction of the change
+type OutboxStorage interface {
+	Publish(ctx context.Context, limit int, publish func(ctx context.Context, events []model.DomainEventDTO) error) (int, error)
+	Fail(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	Purge(ctx context.Context, publishedBefore time.Time) (int64, error)
 }
 
 type Interface interface {
 	User() UserStorage
+	Organization() OrganizationStorage
 	Todo() TodoStorage
+	Checklist() ChecklistStorage
+	Comment() CommentStorage
+	Attachment() AttachmentStorage
 	Project() ProjectStorage
 	Column() ColumnStorage
+	Template() TemplateStorage
+	Activity() ActivityStorage
+	Idempotency() IdempotencyStorage
+	Webhook() WebhookStorage
+	Outbox() OutboxStorage
+	// WithTx runs fn in one transaction: every storage of tx works in it and
+	// it is committed when fn returns nil. fn may be run again when the
+	// transaction fails to serialize, so it must have no other side effects
+	WithTx(ctx context.Context, fn func(tx Interface) error) error
 }
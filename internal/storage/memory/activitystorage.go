@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ActivityStorage" implements the structure "activityStorage"
+var _ storage.ActivityStorage = (*activityStorage)(nil)
+
+// activityStorage reads the activity log. Records are written by the other
+// storages with state.logActivity
+type activityStorage struct {
+	*session
+}
+
+// GetByProject returns a page of activity of the project, newest first, and
+// the total number of its records
+func (store *activityStorage) GetByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]model.ActivityDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res []model.ActivityDTO
+	err = store.read(func(st *state) error {
+		res = st.projectActivity(t, projectID, 0)
+		return nil
+	})
+	slices.Reverse(res)
+	return page(res, limit, offset), len(res), err
+}
+
+// GetSince returns up to limit records of the project added after the record
+// afterID, oldest first
+func (store *activityStorage) GetSince(ctx context.Context, projectID uuid.UUID, afterID int64, limit int) ([]model.ActivityDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ActivityDTO
+	err = store.read(func(st *state) error {
+		res = st.projectActivity(t, projectID, afterID)
+		return nil
+	})
+	return page(res, limit, 0), err
+}
+
+// LastID returns the id of the newest record of the project, zero when it
+// has none
+func (store *activityStorage) LastID(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = store.read(func(st *state) error {
+		if !st.ownsProject(t, projectID) {
+			return nil
+		}
+		for i := len(st.activity) - 1; i >= 0; i-- {
+			if st.activity[i].ProjectID == projectID {
+				id = st.activity[i].ID
+				break
+			}
+		}
+		return nil
+	})
+	return id, err
+}
+
+// projectActivity returns the records of the project of the organization of
+// t after afterID, oldest first
+func (st *state) projectActivity(t tenant, projectID uuid.UUID, afterID int64) []model.ActivityDTO {
+	var res []model.ActivityDTO
+	if !st.ownsProject(t, projectID) {
+		return nil
+	}
+	for _, activity := range st.activity {
+		if activity.ProjectID == projectID && activity.ID > afterID {
+			res = append(res, activity)
+		}
+	}
+	return res
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "AttachmentStorage" implements the structure "attachmentStorage"
+var _ storage.AttachmentStorage = (*attachmentStorage)(nil)
+
+type attachmentStorage struct {
+	*session
+}
+
+func (store *attachmentStorage) Create(ctx context.Context, attachment *model.AttachmentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if !st.ownsTodo(t, attachment.TodoID) {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.users[attachment.UploadedBy]; !ok {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.attachments[attachment.ID]; ok {
+			return errors2.ErrInserting
+		}
+		for _, other := range st.attachments {
+			if other.StorageKey == attachment.StorageKey {
+				return errors2.ErrInserting
+			}
+		}
+
+		attachment.CreatedAt = time.Now()
+		st.attachments[attachment.ID] = *attachment
+		return nil
+	})
+}
+
+func (store *attachmentStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachment *model.AttachmentDTO
+	err = store.read(func(st *state) error {
+		found, ok := st.attachments[id]
+		if !ok || found.TodoID != todoID || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		attachment = &found
+		return nil
+	})
+	return attachment, err
+}
+
+func (store *attachmentStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.AttachmentDTO
+	err = store.read(func(st *state) error {
+		if !st.ownsTodo(t, todoID) {
+			return nil
+		}
+		for _, attachment := range st.attachments {
+			if attachment.TodoID == todoID {
+				res = append(res, attachment)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.AttachmentDTO) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), compareUUID(a.ID, b.ID))
+	})
+	return res, err
+}
+
+func (store *attachmentStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		found, ok := st.attachments[id]
+		if !ok || found.TodoID != todoID || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		delete(st.attachments, id)
+		return nil
+	})
+}
+
+// Purge deletes the attachments of the todos and projects trashed before
+// deletedBefore and returns the keys of their blobs
+func (store *attachmentStorage) Purge(_ context.Context, deletedBefore time.Time) ([]string, error) {
+	var keys []string
+	err := store.write(func(st *state) error {
+		for id, attachment := range st.attachments {
+			todo := st.todos[attachment.TodoID]
+			project := st.projects[todo.ProjectID]
+			if trashedBefore(todo.DeletedAt, deletedBefore) || trashedBefore(project.DeletedAt, deletedBefore) {
+				keys = append(keys, attachment.StorageKey)
+				delete(st.attachments, id)
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func trashedBefore(deletedAt *time.Time, before time.Time) bool {
+	return deletedAt != nil && deletedAt.Before(before)
+}
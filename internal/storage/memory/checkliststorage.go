@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/rank"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ChecklistStorage" implements the structure "checklistStorage"
+var _ storage.ChecklistStorage = (*checklistStorage)(nil)
+
+type checklistStorage struct {
+	*session
+}
+
+// Create appends the item to the end of the checklist of its todo
+func (store *checklistStorage) Create(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if todo, ok := st.tenantTodo(t, item.TodoID); !ok || todo.DeletedAt != nil {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.items[item.ID]; ok {
+			return errors2.ErrInserting
+		}
+
+		var last string
+		for _, other := range st.items {
+			if other.TodoID == item.TodoID {
+				last = max(last, other.Position)
+			}
+		}
+		item.Position = rank.After(last)
+		st.items[item.ID] = *item
+		return nil
+	})
+}
+
+func (store *checklistStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *model.ChecklistItemDTO
+	err = store.read(func(st *state) error {
+		found, ok := st.items[id]
+		if !ok || found.TodoID != todoID || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		item = &found
+		return nil
+	})
+	return item, err
+}
+
+func (store *checklistStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ChecklistItemDTO
+	err = store.read(func(st *state) error {
+		if !st.ownsTodo(t, todoID) {
+			return nil
+		}
+		for _, item := range st.items {
+			if item.TodoID == todoID {
+				res = append(res, item)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.ChecklistItemDTO) int {
+		return strings.Compare(a.Position, b.Position)
+	})
+	return res, err
+}
+
+func (store *checklistStorage) Update(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		found, ok := st.items[item.ID]
+		if !ok || found.TodoID != item.TodoID || !st.ownsTodo(t, item.TodoID) {
+			return errors2.ErrNotFound
+		}
+		found.Text, found.IsDone = item.Text, item.IsDone
+		st.items[item.ID] = found
+		return nil
+	})
+}
+
+func (store *checklistStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		found, ok := st.items[id]
+		if !ok || found.TodoID != todoID || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		delete(st.items, id)
+		return nil
+	})
+}
@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ColumnStorage" implements the structure "columnStorage"
+var _ storage.ColumnStorage = (*columnStorage)(nil)
+
+type columnStorage struct {
+	*session
+}
+
+// CreateColumn inserts the column at column.Order, shifting the following
+// columns to the right. An order past the end appends the column
+func (store *columnStorage) CreateColumn(ctx context.Context, column *model.ColumDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if _, err := st.activeProject(t, column.ProjectId); err != nil {
+			return err
+		}
+		columns := st.projectColumns(column.ProjectId)
+		if column.Order < 0 || column.Order > len(columns) {
+			column.Order = len(columns)
+		}
+
+		for _, other := range columns {
+			if other.Order >= column.Order {
+				other.Order++
+				st.putColumn(other)
+			}
+		}
+		if column.ID == uuid.Nil {
+			column.ID = uuid.New()
+		}
+		if err := st.insertColumn(*column, column.ProjectId); err != nil {
+			return err
+		}
+		return st.logActivity(ctx, column.ProjectId, storage.EntityColumn, column.ID, storage.ActionCreate, nil, column)
+	})
+}
+
+// DeleteColumn removes the column and closes the gap it leaves in the order.
+// Todos of the column are appended to the moveTo column when it is set, or
+// moved to the trash one by one when cascade is true. Otherwise a column that
+// still has todos is not deleted and ErrColumnNotEmpty is returned. Archived
+// and trashed todos are detached from the column, they go back to a column of
+// the project when they are unarchived or restored
+func (store *columnStorage) DeleteColumn(ctx context.Context, name string, projectId uuid.UUID, moveTo string, cascade bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		column, err := st.findColumn(t, name, projectId)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(ctx, column.Version); err != nil {
+			return err
+		}
+		// the expected version is the one of the column, not of its todos
+		todoCtx := storage.WithExpectedVersion(ctx, 0)
+		todos := &todoStorage{store.session}
+
+		// the column leaves the order before its todos move, so that
+		// occurrences of recurring todos completed by the move are spawned in
+		// the new first column
+		columns := st.projectColumns(projectId)
+		for _, other := range columns {
+			if other.Order > column.Order {
+				other.Order--
+				st.putColumn(other)
+			}
+		}
+		last := *column
+		last.Order = len(columns) - 1
+		st.putColumn(last)
+
+		switch {
+		case moveTo != "":
+			target, err := st.findColumn(t, moveTo, projectId)
+			if err != nil || target.ID == column.ID {
+				return errors2.ErrColumnTarget
+			}
+			if err = todos.moveTodos(todoCtx, t, st, column, target); err != nil {
+				return err
+			}
+		case cascade:
+			for _, todo := range st.columnTodos(column.ID) {
+				if err = todos.delete(todoCtx, t, st, todo.ID); err != nil {
+					return err
+				}
+			}
+		}
+		for _, todo := range st.todos {
+			if todo.ColumnID == column.ID && (todo.ArchivedAt != nil || todo.DeletedAt != nil) {
+				todo.ColumnID, todo.LastColumn = uuid.Nil, column.Name
+				st.putTodo(todo)
+			}
+		}
+
+		for _, todo := range st.todos {
+			if todo.ColumnID == column.ID {
+				return errors2.ErrColumnNotEmpty
+			}
+		}
+		delete(st.columns, column.ID)
+		return st.logActivity(ctx, projectId, storage.EntityColumn, column.ID, storage.ActionDelete, column, nil)
+	})
+}
+
+// moveTodos appends the live todos of the column to the end of the target
+// column one by one, keeping their relative order. Like Move they are logged
+// and completed when they cross the done boundary, but the WIP limit of the
+// target and open blockers are not checked, deleting a column must not be
+// blocked by them
+func (store *todoStorage) moveTodos(ctx context.Context, t tenant, st *state, column, target *model.ColumDTO) error {
+	for _, todo := range st.columnTodos(column.ID) {
+		move := &model.TodoMoveDTO{Column: target.Name, Force: true}
+		if _, err := store.move(ctx, t, st, todo.ID, move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnTodos returns the live todos of the column in their order
+func (st *state) columnTodos(columnID uuid.UUID) []todoRow {
+	var todos []todoRow
+	for _, todo := range st.todos {
+		if todo.ColumnID == columnID && todo.DeletedAt == nil && todo.ArchivedAt == nil {
+			todos = append(todos, todo)
+		}
+	}
+	slices.SortFunc(todos, func(a, b todoRow) int {
+		return strings.Compare(a.Position, b.Position)
+	})
+	return todos
+}
+
+// ReorderColumns rewrites the order of all columns of the project at once.
+// names must list every column of the project exactly once
+func (store *columnStorage) ReorderColumns(ctx context.Context, projectId uuid.UUID, names []string) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if _, err := st.activeProject(t, projectId); err != nil {
+			return err
+		}
+		columns := st.projectColumns(projectId)
+		current := make([]string, 0, len(columns))
+		for _, column := range columns {
+			current = append(current, column.Name)
+		}
+		if !sameColumnSet(current, names) {
+			return errors2.ErrColumnsMismatch
+		}
+
+		for _, column := range columns {
+			column.Order = slices.Index(names, column.Name)
+			st.putColumn(column)
+		}
+
+		type columnOrder struct {
+			Columns []string `json:"columns"`
+		}
+		return st.logActivity(ctx, projectId, storage.EntityProject, projectId, storage.ActionReorder, columnOrder{current}, columnOrder{names})
+	})
+}
+
+func sameColumnSet(current, names []string) bool {
+	if len(current) != len(names) {
+		return false
+	}
+	seen := make(map[string]bool, len(current))
+	for _, name := range current {
+		seen[name] = true
+	}
+	for _, name := range names {
+		if !seen[name] {
+			return false
+		}
+		delete(seen, name)
+	}
+	return true
+}
+
+func (store *columnStorage) GetColumnByName(ctx context.Context, name string, projectId uuid.UUID) (*model.ColumDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var column *model.ColumDTO
+	err = store.read(func(st *state) (err error) {
+		column, err = st.findColumn(t, name, projectId)
+		return err
+	})
+	return column, err
+}
+
+// UpdateColumn renames the column. Todos reference columns by id, so they
+// follow the rename without being touched
+func (store *columnStorage) UpdateColumn(ctx context.Context, column *model.ColumDTO, name string, projectId uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		before, err := st.findColumn(t, name, projectId)
+		if err != nil {
+			return err
+		}
+		if column.Name != name {
+			if _, err = st.findColumn(t, column.Name, projectId); err == nil {
+				return errors2.ErrAlreadyExists
+			}
+		}
+		if err = checkVersion(ctx, before.Version); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Name, after.WipLimit, after.IsDone = column.Name, column.WipLimit, column.IsDone
+		st.putColumn(after)
+		return st.logActivity(ctx, projectId, storage.EntityColumn, before.ID, storage.ActionUpdate, before, &after)
+	})
+}
+
+func (store *columnStorage) GetAllColumns(ctx context.Context, projectId uuid.UUID) ([]model.ColumDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ColumDTO
+	err = store.read(func(st *state) error {
+		if _, err := st.activeProject(t, projectId); err != nil {
+			return nil
+		}
+		res = st.projectColumns(projectId)
+		return nil
+	})
+	return res, err
+}
+
+// insertColumn adds a new column to the project. Names of columns are unique
+// within their project
+func (st *state) insertColumn(column model.ColumDTO, projectID uuid.UUID) error {
+	if _, ok := st.columns[column.ID]; ok {
+		return errors2.ErrAlreadyExists
+	}
+	for _, other := range st.columns {
+		if other.ProjectId == projectID && other.Name == column.Name {
+			return errors2.ErrAlreadyExists
+		}
+	}
+	if column.WipLimit != nil && *column.WipLimit <= 0 {
+		return errors2.ErrInserting
+	}
+	column.ProjectId = projectID
+	column.Version = 1
+	st.columns[column.ID] = column
+	return nil
+}
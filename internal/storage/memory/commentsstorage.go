@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/mention"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "CommentStorage" implements the structure "commentStorage"
+var _ storage.CommentStorage = (*commentStorage)(nil)
+
+type commentStorage struct {
+	*session
+}
+
+// Create inserts the comment and records the users mentioned in its body
+func (store *commentStorage) Create(ctx context.Context, comment *model.CommentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if todo, ok := st.tenantTodo(t, comment.TodoID); !ok || todo.DeletedAt != nil {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.users[comment.AuthorID]; !ok {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.comments[comment.ID]; ok {
+			return errors2.ErrInserting
+		}
+
+		now := time.Now()
+		comment.CreatedAt, comment.UpdatedAt = now, now
+		comment.Mentions = st.resolveMentions(t, comment.Body)
+		st.comments[comment.ID] = commentRow{CommentDTO: *comment}
+		return nil
+	})
+}
+
+func (store *commentStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.CommentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment *model.CommentDTO
+	err = store.read(func(st *state) error {
+		row, ok := st.comments[id]
+		if !ok || row.TodoID != todoID || row.DeletedAt != nil || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		comment = &row.CommentDTO
+		return nil
+	})
+	return comment, err
+}
+
+// GetAll returns a page of comments of the todo, oldest first, and the total
+// number of its comments
+func (store *commentStorage) GetAll(ctx context.Context, todoID uuid.UUID, limit, offset int) ([]model.CommentDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res []model.CommentDTO
+	err = store.read(func(st *state) error {
+		if !st.ownsTodo(t, todoID) {
+			return nil
+		}
+		for _, row := range st.comments {
+			if row.TodoID == todoID && row.DeletedAt == nil {
+				res = append(res, row.CommentDTO)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.CommentDTO) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), compareUUID(a.ID, b.ID))
+	})
+	return page(res, limit, offset), len(res), err
+}
+
+// Update changes the body of the comment and its mentions
+func (store *commentStorage) Update(ctx context.Context, comment *model.CommentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		row, ok := st.comments[comment.ID]
+		if !ok || row.TodoID != comment.TodoID || row.DeletedAt != nil || !st.ownsTodo(t, comment.TodoID) {
+			return errors2.ErrNotFound
+		}
+
+		comment.UpdatedAt = time.Now()
+		comment.Mentions = st.resolveMentions(t, comment.Body)
+		row.Body, row.UpdatedAt, row.Mentions = comment.Body, comment.UpdatedAt, comment.Mentions
+		st.comments[comment.ID] = row
+		return nil
+	})
+}
+
+// Delete soft deletes the comment
+func (store *commentStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		row, ok := st.comments[id]
+		if !ok || row.TodoID != todoID || row.DeletedAt != nil || !st.ownsTodo(t, todoID) {
+			return errors2.ErrNotFound
+		}
+		now := time.Now()
+		row.DeletedAt = &now
+		st.comments[id] = row
+		return nil
+	})
+}
+
+// resolveMentions resolves @login mentions of the body to user ids. Logins
+// of unknown users and of users outside of the organization of t are ignored
+func (st *state) resolveMentions(t tenant, body string) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, login := range mention.Parse(body) {
+		if user := st.userByLogin(login); user != nil && st.isMember(t, user.ID) {
+			ids = append(ids, user.ID)
+		}
+	}
+	slices.SortFunc(ids, compareUUID)
+	return ids
+}
+
+// page returns the part of items selected by limit and offset
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
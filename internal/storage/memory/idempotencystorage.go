@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "IdempotencyStorage" implements the structure "idempotencyStorage"
+var _ storage.IdempotencyStorage = (*idempotencyStorage)(nil)
+
+type idempotencyStorage struct {
+	*session
+}
+
+// Acquire takes the key of the record for a new request and returns nil. When
+// the key is taken it returns the stored response to replay, or
+// ErrIdempotencyInProgress while the first request is running, or
+// ErrIdempotencyKeyReuse when the key was sent with another request
+func (store *idempotencyStorage) Acquire(_ context.Context, record *model.IdempotencyRecordDTO) (*model.IdempotencyRecordDTO, error) {
+	var stored *model.IdempotencyRecordDTO
+	err := store.write(func(st *state) error {
+		key := keyOf(record)
+		if found, ok := st.idempotency[key]; ok && found.ExpiresAt.After(time.Now()) {
+			if found.Fingerprint != record.Fingerprint {
+				return errors2.ErrIdempotencyKeyReuse
+			}
+			if found.Status == 0 {
+				return errors2.ErrIdempotencyInProgress
+			}
+			stored = &found
+			return nil
+		}
+		if _, ok := st.users[record.UserID]; !ok {
+			return fmt.Errorf("error while acquiring idempotency key: %w", errors2.ErrNotFound)
+		}
+
+		st.idempotency[key] = model.IdempotencyRecordDTO{
+			UserID:      record.UserID,
+			OrgID:       record.OrgID,
+			Key:         record.Key,
+			Fingerprint: record.Fingerprint,
+			ExpiresAt:   record.ExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// Complete stores the response of the request that acquired the key
+func (store *idempotencyStorage) Complete(_ context.Context, record *model.IdempotencyRecordDTO) error {
+	return store.write(func(st *state) error {
+		key := keyOf(record)
+		found, ok := st.idempotency[key]
+		if !ok {
+			return nil
+		}
+		found.Status, found.ContentType, found.Body = record.Status, record.ContentType, slices.Clone(record.Body)
+		st.idempotency[key] = found
+		return nil
+	})
+}
+
+// Release frees a key whose request failed before a response was stored
+func (store *idempotencyStorage) Release(_ context.Context, record *model.IdempotencyRecordDTO) error {
+	return store.write(func(st *state) error {
+		key := keyOf(record)
+		if found, ok := st.idempotency[key]; ok && found.Status == 0 {
+			delete(st.idempotency, key)
+		}
+		return nil
+	})
+}
+
+func keyOf(record *model.IdempotencyRecordDTO) idempotencyKey {
+	return idempotencyKey{UserID: record.UserID, OrgID: record.OrgID, Key: record.Key}
+}
+
+// Purge deletes the keys that expired before expiredBefore
+func (store *idempotencyStorage) Purge(_ context.Context, expiredBefore time.Time) (int64, error) {
+	var purged int64
+	err := store.write(func(st *state) error {
+		for key, record := range st.idempotency {
+			if record.ExpiresAt.Before(expiredBefore) {
+				delete(st.idempotency, key)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,220 @@
+// Package memory implements storage.Interface in process memory. It keeps
+// the error semantics of the pgx storage, so handlers and workers can be run
+// in tests and demos without Postgres. Nothing survives a restart
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "Interface" implements the structure "Storage"
+var _ storage.Interface = (*Storage)(nil)
+
+type Storage struct {
+	session     *session
+	user        *userStorage
+	org         *organizationStorage
+	project     *projectsStorage
+	todo        *todoStorage
+	checklist   *checklistStorage
+	comment     *commentStorage
+	attachment  *attachmentStorage
+	column      *columnStorage
+	template    *templateStorage
+	activity    *activityStorage
+	idempotency *idempotencyStorage
+	webhook     *webhookStorage
+	outbox      *outboxStorage
+}
+
+func New(log *zap.Logger) *Storage {
+	return NewWithClock(log, recurrence.SystemClock{})
+}
+
+// NewWithClock returns an empty storage that spawns occurrences of recurring
+// todos at the time of clock
+func NewWithClock(log *zap.Logger, clock recurrence.Clock) *Storage {
+	db := &database{
+		state:     newState(),
+		listeners: make(map[*listener]struct{}),
+	}
+	return newStorage(&session{db: db, log: log.Named("memory-storage"), clock: clock})
+}
+
+func newStorage(s *session) *Storage {
+	return &Storage{
+		session:     s,
+		user:        &userStorage{s},
+		org:         &organizationStorage{s},
+		project:     &projectsStorage{s},
+		todo:        &todoStorage{s},
+		checklist:   &checklistStorage{s},
+		comment:     &commentStorage{s},
+		attachment:  &attachmentStorage{s},
+		column:      &columnStorage{s},
+		template:    &templateStorage{s},
+		activity:    &activityStorage{s},
+		idempotency: &idempotencyStorage{s},
+		webhook:     &webhookStorage{s},
+		outbox:      &outboxStorage{s},
+	}
+}
+
+func (s *Storage) User() storage.UserStorage {
+	return s.user
+}
+
+func (s *Storage) Organization() storage.OrganizationStorage {
+	return s.org
+}
+
+func (s *Storage) Todo() storage.TodoStorage {
+	return s.todo
+}
+
+func (s *Storage) Checklist() storage.ChecklistStorage {
+	return s.checklist
+}
+
+func (s *Storage) Comment() storage.CommentStorage {
+	return s.comment
+}
+
+func (s *Storage) Attachment() storage.AttachmentStorage {
+	return s.attachment
+}
+
+func (s *Storage) Project() storage.ProjectStorage {
+	return s.project
+}
+
+func (s *Storage) Column() storage.ColumnStorage {
+	return s.column
+}
+
+func (s *Storage) Template() storage.TemplateStorage {
+	return s.template
+}
+
+func (s *Storage) Activity() storage.ActivityStorage {
+	return s.activity
+}
+
+func (s *Storage) Idempotency() storage.IdempotencyStorage {
+	return s.idempotency
+}
+
+func (s *Storage) Webhook() storage.WebhookStorage {
+	return s.webhook
+}
+
+func (s *Storage) Outbox() storage.OutboxStorage {
+	return s.outbox
+}
+
+// WithTx runs fn on a copy of the state and makes the copy current when fn
+// returns nil. Transactions hold the lock of the storage until they end, so
+// they never fail to serialize and fn runs once. fn must use tx only: the
+// storage WithTx was called on waits for the transaction. WithTx of tx works
+// like a savepoint
+func (s *Storage) WithTx(ctx context.Context, fn func(tx storage.Interface) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.session.tx != nil {
+		savepoint := s.session.tx.clone()
+		if err := fn(newStorage(s.session.bind(savepoint))); err != nil {
+			return err
+		}
+		*s.session.tx = *savepoint
+		return nil
+	}
+
+	db := s.session.db
+	db.mu.Lock()
+	tx := db.state.clone()
+	err := fn(newStorage(s.session.bind(tx)))
+	var notified []uuid.UUID
+	if err == nil {
+		notified = tx.takeNotified()
+		db.state = tx
+	}
+	db.mu.Unlock()
+
+	db.notify(notified)
+	return err
+}
+
+// database is the state shared by the storage and by the storages bound to
+// its transactions
+type database struct {
+	mu    sync.RWMutex
+	state *state
+
+	// publishing serializes outbox relays, see outboxStorage.Publish
+	publishing sync.Mutex
+
+	listenersMu sync.Mutex
+	listeners   map[*listener]struct{}
+}
+
+// session runs the operations of the storages on the database or, within
+// WithTx, on the state of the transaction
+type session struct {
+	db    *database
+	log   *zap.Logger
+	clock recurrence.Clock
+	// tx is the state of the transaction of WithTx, nil outside of it
+	tx *state
+}
+
+// bind returns a copy of the session running every operation on tx
+func (s *session) bind(tx *state) *session {
+	bound := *s
+	bound.tx = tx
+	return &bound
+}
+
+// read runs fn on the current state, fn must not change it
+func (s *session) read(fn func(st *state) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return fn(s.db.state)
+}
+
+// write runs fn on a copy of the current state and keeps the copy when fn
+// returns nil, so that a failed operation changes nothing, like a rolled back
+// transaction
+func (s *session) write(fn func(st *state) error) error {
+	if s.tx != nil {
+		next := s.tx.clone()
+		if err := fn(next); err != nil {
+			return err
+		}
+		*s.tx = *next
+		return nil
+	}
+
+	s.db.mu.Lock()
+	next := s.db.state.clone()
+	if err := fn(next); err != nil {
+		s.db.mu.Unlock()
+		return err
+	}
+	notified := next.takeNotified()
+	s.db.state = next
+	s.db.mu.Unlock()
+
+	s.db.notify(notified)
+	return nil
+}
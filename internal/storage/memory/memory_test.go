@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return New(zaptest.NewLogger(t))
+	})
+}
+
+func TestSpawnNextClock(t *testing.T) {
+	storagetest.RunClock(t, func(t *testing.T, clock recurrence.Clock) storage.Interface {
+		return NewWithClock(zaptest.NewLogger(t), clock)
+	})
+}
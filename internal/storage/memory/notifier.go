@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/events"
+)
+
+// Checking whether the interface "events.Notifier" implements the structure "Notifier"
+var _ events.Notifier = (*Notifier)(nil)
+
+// Notifier reports projects that got activity in the storage. Only changes
+// made through this process are seen, like the storage itself
+type Notifier struct {
+	db *database
+}
+
+func NewNotifier(store *Storage) *Notifier {
+	return &Notifier{db: store.session.db}
+}
+
+func (n *Notifier) Listen(ctx context.Context, notify func(projectID uuid.UUID)) error {
+	l := &listener{
+		pending: make(map[uuid.UUID]struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	n.db.listenersMu.Lock()
+	n.db.listeners[l] = struct{}{}
+	n.db.listenersMu.Unlock()
+	defer func() {
+		n.db.listenersMu.Lock()
+		delete(n.db.listeners, l)
+		n.db.listenersMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.wake:
+		}
+		for _, projectID := range l.take() {
+			notify(projectID)
+		}
+	}
+}
+
+// listener collects the projects to report until Listen takes them, repeated
+// notifications of a project are merged
+type listener struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID]struct{}
+	wake    chan struct{}
+}
+
+func (l *listener) add(projectIDs []uuid.UUID) {
+	l.mu.Lock()
+	for _, projectID := range projectIDs {
+		l.pending[projectID] = struct{}{}
+	}
+	l.mu.Unlock()
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (l *listener) take() []uuid.UUID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	projectIDs := make([]uuid.UUID, 0, len(l.pending))
+	for projectID := range l.pending {
+		projectIDs = append(projectIDs, projectID)
+	}
+	clear(l.pending)
+	return projectIDs
+}
+
+// notify hands the projects changed by a committed write to the listeners
+func (db *database) notify(projectIDs []uuid.UUID) {
+	if len(projectIDs) == 0 {
+		return
+	}
+	db.listenersMu.Lock()
+	defer db.listenersMu.Unlock()
+	for l := range db.listeners {
+		l.add(projectIDs)
+	}
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "OrganizationStorage" implements the structure "organizationStorage"
+var _ storage.OrganizationStorage = (*organizationStorage)(nil)
+
+type organizationStorage struct {
+	*session
+}
+
+// Create creates the organization owned by the user that created it
+func (store *organizationStorage) Create(_ context.Context, org *model.OrganizationDTO) error {
+	return store.write(func(st *state) error {
+		return st.createOrganization(org)
+	})
+}
+
+func (store *organizationStorage) GetByID(_ context.Context, id uuid.UUID) (*model.OrganizationDTO, error) {
+	var org *model.OrganizationDTO
+	err := store.read(func(st *state) error {
+		found, ok := st.orgs[id]
+		if !ok {
+			return errors2.ErrNotFound
+		}
+		org = &found
+		return nil
+	})
+	return org, err
+}
+
+// GetByMember returns the organizations of the user with their role in each
+func (store *organizationStorage) GetByMember(_ context.Context, userID uuid.UUID) ([]model.OrganizationDTO, error) {
+	var res []model.OrganizationDTO
+	err := store.read(func(st *state) error {
+		for key, member := range st.members {
+			if key.UserID != userID {
+				continue
+			}
+			org := st.orgs[key.OrgID]
+			org.Role = member.Role
+			res = append(res, org)
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.OrganizationDTO) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return compareUUID(a.ID, b.ID)
+	})
+	return res, err
+}
+
+// GetMember returns the membership of the user in the organization, it is
+// how the organization of a request is checked before it is set as the tenant
+func (store *organizationStorage) GetMember(_ context.Context, orgID, userID uuid.UUID) (*model.OrganizationMemberDTO, error) {
+	var member *model.OrganizationMemberDTO
+	err := store.read(func(st *state) error {
+		found, ok := st.members[membership{OrgID: orgID, UserID: userID}]
+		if !ok {
+			return errors2.ErrNotMember
+		}
+		member = st.memberView(found)
+		return nil
+	})
+	return member, err
+}
+
+// GetMembers returns the members of the organization of ctx ordered by login
+func (store *organizationStorage) GetMembers(ctx context.Context) ([]model.OrganizationMemberDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.OrganizationMemberDTO
+	err = store.read(func(st *state) error {
+		for key, member := range st.members {
+			if key.OrgID == t.orgID {
+				res = append(res, *st.memberView(member))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.OrganizationMemberDTO) int {
+		return strings.Compare(a.Login, b.Login)
+	})
+	return res, err
+}
+
+// AddMember adds the user to the organization of ctx
+func (store *organizationStorage) AddMember(ctx context.Context, member *model.OrganizationMemberDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if _, ok := st.orgs[t.orgID]; !ok {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.users[member.UserID]; !ok {
+			return errors2.ErrNotFound
+		}
+		if st.isMember(t, member.UserID) {
+			return errors2.ErrAlreadyExists
+		}
+		member.OrgID = t.orgID
+		member.JoinedAt = time.Now()
+		st.members[membership{OrgID: t.orgID, UserID: member.UserID}] = model.OrganizationMemberDTO{
+			OrgID:    member.OrgID,
+			UserID:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		}
+		return nil
+	})
+}
+
+// UpdateMemberRole changes the role of the member of the organization of ctx.
+// The organization keeps at least one owner, and the owner of a personal
+// organization stays its owner
+func (store *organizationStorage) UpdateMemberRole(ctx context.Context, userID uuid.UUID, role string) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		key := membership{OrgID: t.orgID, UserID: userID}
+		member, ok := st.members[key]
+		if !ok {
+			return errors2.ErrNotFound
+		}
+		if member.Role == model.OrgRoleOwner && role != model.OrgRoleOwner {
+			if err := st.leaveOwners(t, userID); err != nil {
+				return err
+			}
+		}
+		member.Role = role
+		st.members[key] = member
+		return nil
+	})
+}
+
+// RemoveMember removes the user from the organization of ctx. The last owner
+// and the owner of a personal organization can't be removed
+func (store *organizationStorage) RemoveMember(ctx context.Context, userID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		key := membership{OrgID: t.orgID, UserID: userID}
+		member, ok := st.members[key]
+		if !ok {
+			return errors2.ErrNotFound
+		}
+		if userID == t.orgID {
+			return errors2.ErrPersonalOwner
+		}
+		if member.Role == model.OrgRoleOwner {
+			if err := st.leaveOwners(t, userID); err != nil {
+				return err
+			}
+		}
+		delete(st.members, key)
+		return nil
+	})
+}
+
+// createOrganization inserts the organization and makes its creator the
+// owner. The personal organization of a user is created with it
+func (st *state) createOrganization(org *model.OrganizationDTO) error {
+	if _, ok := st.orgs[org.ID]; ok {
+		return errors2.ErrAlreadyExists
+	}
+	if _, ok := st.users[org.CreatedBy]; !ok {
+		return errors2.ErrInserting
+	}
+	org.CreatedAt = time.Now()
+	org.Role = model.OrgRoleOwner
+	st.orgs[org.ID] = model.OrganizationDTO{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+	}
+	st.members[membership{OrgID: org.ID, UserID: org.CreatedBy}] = model.OrganizationMemberDTO{
+		OrgID:    org.ID,
+		UserID:   org.CreatedBy,
+		Role:     model.OrgRoleOwner,
+		JoinedAt: org.CreatedAt,
+	}
+	return nil
+}
+
+// leaveOwners checks that the owner may stop owning the organization of t
+func (st *state) leaveOwners(t tenant, userID uuid.UUID) error {
+	if userID == t.orgID {
+		return errors2.ErrPersonalOwner
+	}
+	var owners int
+	for key, member := range st.members {
+		if key.OrgID == t.orgID && member.Role == model.OrgRoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return errors2.ErrLastOwner
+	}
+	return nil
+}
+
+// memberView returns the member as it is read, with the login of the user
+func (st *state) memberView(member model.OrganizationMemberDTO) *model.OrganizationMemberDTO {
+	member.Login = st.users[member.UserID].Login
+	return &member
+}
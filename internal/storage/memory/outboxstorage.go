@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "OutboxStorage" implements the structure "outboxStorage"
+var _ storage.OutboxStorage = (*outboxStorage)(nil)
+
+type outboxStorage struct {
+	*session
+}
+
+// Publish hands up to limit unpublished events, oldest first, to publish and
+// marks them published when it succeeds. Only one relay publishes at a time,
+// it returns zero when another one is busy or when the oldest event waits
+// for its next attempt, so that events are never published out of order
+func (store *outboxStorage) Publish(ctx context.Context, limit int, publish func(ctx context.Context, events []model.DomainEventDTO) error) (int, error) {
+	if !store.db.publishing.TryLock() {
+		return 0, nil
+	}
+	defer store.db.publishing.Unlock()
+
+	var events []model.DomainEventDTO
+	err := store.read(func(st *state) error {
+		for _, row := range st.outbox {
+			if len(events) == limit {
+				break
+			}
+			if row.PublishedAt == nil {
+				events = append(events, row.DomainEventDTO)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 || events[0].NextAttemptAt.After(time.Now()) {
+		return 0, nil
+	}
+
+	// the state is not locked while publishing, other writes go on
+	if err = publish(ctx, events); err != nil {
+		return 0, err
+	}
+
+	last := events[len(events)-1].ID
+	err = store.write(func(st *state) error {
+		now := time.Now()
+		for i := range st.outbox {
+			row := &st.outbox[i]
+			if row.ID > last {
+				break
+			}
+			if row.PublishedAt == nil {
+				row.PublishedAt = &now
+				row.Attempts++
+				row.LastError = ""
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// Fail records a failed attempt to publish the event, the relay tries again
+// from it at nextAttemptAt
+func (store *outboxStorage) Fail(_ context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	return store.write(func(st *state) error {
+		for i := range st.outbox {
+			row := &st.outbox[i]
+			if row.ID == id && row.PublishedAt == nil {
+				row.Attempts++
+				row.LastError = lastError
+				row.NextAttemptAt = nextAttemptAt
+			}
+		}
+		return nil
+	})
+}
+
+// Purge deletes the events published before publishedBefore and returns how
+// many were removed
+func (store *outboxStorage) Purge(_ context.Context, publishedBefore time.Time) (int64, error) {
+	var purged int64
+	err := store.write(func(st *state) error {
+		st.outbox = slices.DeleteFunc(st.outbox, func(row outboxRow) bool {
+			if row.PublishedAt != nil && row.PublishedAt.Before(publishedBefore) {
+				purged++
+				return true
+			}
+			return false
+		})
+		return nil
+	})
+	return purged, err
+}
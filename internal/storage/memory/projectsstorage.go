@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ProjectStorage" implements the structure "projectsStorage"
+var _ storage.ProjectStorage = (*projectsStorage)(nil)
+
+type projectsStorage struct {
+	*session
+}
+
+// Create inserts the project together with its initial columns
+func (store *projectsStorage) Create(ctx context.Context, project *model.ProjectDTO, columns []model.ColumDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if _, ok := st.projects[project.ID]; ok {
+			return errors2.ErrAlreadyExists
+		}
+		if _, ok := st.users[project.CreatedBy]; !ok {
+			return errors2.ErrInserting
+		}
+		project.OrgID = t.orgID
+		st.projects[project.ID] = model.ProjectDTO{
+			ID:        project.ID,
+			Name:      project.Name,
+			CreatedBy: project.CreatedBy,
+			OrgID:     project.OrgID,
+			Version:   1,
+		}
+		if err := st.logActivity(ctx, project.ID, storage.EntityProject, project.ID, storage.ActionCreate, nil, project); err != nil {
+			return err
+		}
+
+		for _, column := range columns {
+			if err := st.insertColumn(column, project.ID); err != nil {
+				return errors2.ErrInserting
+			}
+			if err := st.logActivity(ctx, project.ID, storage.EntityColumn, column.ID, storage.ActionCreate, nil, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *projectsStorage) GetMyByName(ctx context.Context, name string, createdBy uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.read(func(st *state) error {
+		for _, project := range st.projects {
+			if project.Name == name && project.CreatedBy == createdBy && project.OrgID == t.orgID && project.DeletedAt == nil {
+				return nil
+			}
+		}
+		return errors2.ErrNotFound
+	})
+}
+
+func (store *projectsStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var project *model.ProjectDTO
+	err = store.read(func(st *state) (err error) {
+		project, err = st.activeProject(t, id)
+		return err
+	})
+	return project, err
+}
+
+// GetAll returns projects of the organization of ctx, archived ones only
+// when includeArchived is set
+func (store *projectsStorage) GetAll(ctx context.Context, includeArchived bool) ([]model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ProjectDTO
+	err = store.read(func(st *state) error {
+		for _, project := range st.projects {
+			if project.OrgID == t.orgID && project.DeletedAt == nil && (includeArchived || project.ArchivedAt == nil) {
+				res = append(res, project)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.ProjectDTO) int {
+		return compareUUID(a.ID, b.ID)
+	})
+	return res, err
+}
+
+func (store *projectsStorage) UpdateName(ctx context.Context, name string, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		before, err := st.activeProject(t, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(ctx, before.Version); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Name = name
+		st.putProject(after)
+		return st.logActivity(ctx, id, storage.EntityProject, id, storage.ActionUpdate, before, &after)
+	})
+}
+
+// Archive archives or unarchives the project. Archived projects are left out
+// of GetAll by default but stay readable by id
+func (store *projectsStorage) Archive(ctx context.Context, id uuid.UUID, archived bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		before, err := st.activeProject(t, id)
+		if err != nil {
+			return err
+		}
+
+		after := *before
+		after.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			after.ArchivedAt = &now
+		}
+		st.putProject(after)
+
+		action := storage.ActionArchive
+		if !archived {
+			action = storage.ActionUnarchive
+		}
+		return st.logActivity(ctx, id, storage.EntityProject, id, action, before, &after)
+	})
+}
+
+// Delete moves the project to the trash, its columns and todos are hidden
+// with it until it is restored or purged
+func (store *projectsStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		before, err := st.activeProject(t, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(ctx, before.Version); err != nil {
+			return err
+		}
+
+		after := *before
+		now := time.Now()
+		after.DeletedAt = &now
+		st.putProject(after)
+		return st.logActivity(ctx, id, storage.EntityProject, id, storage.ActionDelete, before, nil)
+	})
+}
+
+// GetTrash returns deleted projects of the user in the organization of ctx,
+// most recently deleted first
+func (store *projectsStorage) GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ProjectDTO
+	err = store.read(func(st *state) error {
+		for _, project := range st.projects {
+			if project.CreatedBy == createdBy && project.OrgID == t.orgID && project.DeletedAt != nil {
+				res = append(res, model.ProjectDTO{
+					ID:        project.ID,
+					Name:      project.Name,
+					CreatedBy: project.CreatedBy,
+					OrgID:     project.OrgID,
+					DeletedAt: project.DeletedAt,
+				})
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.ProjectDTO) int {
+		return b.DeletedAt.Compare(*a.DeletedAt)
+	})
+	return res, err
+}
+
+// Restore takes the project out of the trash together with its columns and
+// the todos that were not deleted on their own
+func (store *projectsStorage) Restore(ctx context.Context, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		project, ok := st.projects[id]
+		if !ok || project.OrgID != t.orgID || project.DeletedAt == nil {
+			return errors2.ErrNotFound
+		}
+		before := model.ProjectDTO{ID: project.ID, Name: project.Name, CreatedBy: project.CreatedBy, OrgID: project.OrgID, DeletedAt: project.DeletedAt}
+
+		project.DeletedAt = nil
+		st.putProject(project)
+
+		after := before
+		after.DeletedAt = nil
+		return st.logActivity(ctx, id, storage.EntityProject, id, storage.ActionRestore, &before, &after)
+	})
+}
+
+// Purge deletes for good the projects that were deleted before
+// deletedBefore together with their activity and returns how many were
+// removed
+func (store *projectsStorage) Purge(_ context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := store.write(func(st *state) error {
+		for id, project := range st.projects {
+			if project.DeletedAt == nil || !project.DeletedAt.Before(deletedBefore) {
+				continue
+			}
+			st.deleteProject(id)
+			purged++
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// deleteProject deletes the project for good together with its columns,
+// todos, webhooks and activity
+func (st *state) deleteProject(id uuid.UUID) {
+	delete(st.projects, id)
+	// the slice is shared with the state the transaction started from
+	activity := make([]model.ActivityDTO, 0, len(st.activity))
+	for _, record := range st.activity {
+		if record.ProjectID != id {
+			activity = append(activity, record)
+		}
+	}
+	st.activity = activity
+	for todoID, todo := range st.todos {
+		if todo.ProjectID == id {
+			st.deleteTodo(todoID)
+		}
+	}
+	for columnID, column := range st.columns {
+		if column.ProjectId == id {
+			delete(st.columns, columnID)
+		}
+	}
+	for webhookID, webhook := range st.webhooks {
+		if webhook.ProjectID == id {
+			st.deleteWebhook(webhookID)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// state holds the tables of the storage. Rows are values and their slices
+// are never changed in place, so a shallow copy of the maps is a snapshot
+type state struct {
+	users        map[uuid.UUID]model.UserDTO
+	orgs         map[uuid.UUID]model.OrganizationDTO
+	members      map[membership]model.OrganizationMemberDTO
+	projects     map[uuid.UUID]model.ProjectDTO
+	columns      map[uuid.UUID]model.ColumDTO
+	todos        map[uuid.UUID]todoRow
+	dependencies map[dependency]struct{}
+	items        map[uuid.UUID]model.ChecklistItemDTO
+	comments     map[uuid.UUID]commentRow
+	attachments  map[uuid.UUID]model.AttachmentDTO
+	templates    map[uuid.UUID]model.ProjectTemplateDTO
+	idempotency  map[idempotencyKey]model.IdempotencyRecordDTO
+	webhooks     map[uuid.UUID]model.WebhookDTO
+	deliveries   map[uuid.UUID]model.WebhookDeliveryDTO
+	// activity and outbox are ordered by id
+	activity []model.ActivityDTO
+	outbox   []outboxRow
+
+	lastActivityID int64
+	lastOutboxID   int64
+	// notified are the projects that got activity, listeners hear of them
+	// when the change is committed
+	notified []uuid.UUID
+}
+
+// todoRow is a stored todo. Column, Progress, BlockedBy and Blocks of the
+// todo are computed when it is read, see todoView
+type todoRow struct {
+	model.TodoDTO
+	SpawnedFrom *uuid.UUID
+	// LastColumn is the name of the deleted column the todo was detached
+	// from, ColumnID is then uuid.Nil
+	LastColumn string
+}
+
+// dependency is an edge of the graph of todos: Blocker blocks Blocked
+type dependency struct {
+	Blocker uuid.UUID
+	Blocked uuid.UUID
+}
+
+// membership is the key of a member of an organization
+type membership struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+type commentRow struct {
+	model.CommentDTO
+	DeletedAt *time.Time
+}
+
+type idempotencyKey struct {
+	UserID uuid.UUID
+	OrgID  uuid.UUID
+	Key    string
+}
+
+type outboxRow struct {
+	model.DomainEventDTO
+	PublishedAt *time.Time
+	LastError   string
+}
+
+func newState() *state {
+	return &state{
+		users:        make(map[uuid.UUID]model.UserDTO),
+		orgs:         make(map[uuid.UUID]model.OrganizationDTO),
+		members:      make(map[membership]model.OrganizationMemberDTO),
+		projects:     make(map[uuid.UUID]model.ProjectDTO),
+		columns:      make(map[uuid.UUID]model.ColumDTO),
+		todos:        make(map[uuid.UUID]todoRow),
+		dependencies: make(map[dependency]struct{}),
+		items:        make(map[uuid.UUID]model.ChecklistItemDTO),
+		comments:     make(map[uuid.UUID]commentRow),
+		attachments:  make(map[uuid.UUID]model.AttachmentDTO),
+		templates:    make(map[uuid.UUID]model.ProjectTemplateDTO),
+		idempotency:  make(map[idempotencyKey]model.IdempotencyRecordDTO),
+		webhooks:     make(map[uuid.UUID]model.WebhookDTO),
+		deliveries:   make(map[uuid.UUID]model.WebhookDeliveryDTO),
+	}
+}
+
+func (st *state) clone() *state {
+	return &state{
+		users:        maps.Clone(st.users),
+		orgs:         maps.Clone(st.orgs),
+		members:      maps.Clone(st.members),
+		projects:     maps.Clone(st.projects),
+		columns:      maps.Clone(st.columns),
+		todos:        maps.Clone(st.todos),
+		dependencies: maps.Clone(st.dependencies),
+		items:        maps.Clone(st.items),
+		comments:     maps.Clone(st.comments),
+		attachments:  maps.Clone(st.attachments),
+		templates:    maps.Clone(st.templates),
+		idempotency:  maps.Clone(st.idempotency),
+		webhooks:     maps.Clone(st.webhooks),
+		deliveries:   maps.Clone(st.deliveries),
+		// clipped, so that appending to the copy never writes to the original
+		activity:       slices.Clip(st.activity),
+		outbox:         slices.Clone(st.outbox),
+		lastActivityID: st.lastActivityID,
+		lastOutboxID:   st.lastOutboxID,
+		notified:       slices.Clip(st.notified),
+	}
+}
+
+func (st *state) takeNotified() []uuid.UUID {
+	notified := st.notified
+	st.notified = nil
+	return notified
+}
+
+// activeProject returns the project of the organization of t unless it is
+// missing or in the trash
+func (st *state) activeProject(t tenant, id uuid.UUID) (*model.ProjectDTO, error) {
+	project, ok := st.projects[id]
+	if !ok || project.OrgID != t.orgID || project.DeletedAt != nil {
+		return nil, errors2.ErrNotFound
+	}
+	return &project, nil
+}
+
+// putProject stores the project, bumping its version when it changed
+func (st *state) putProject(project model.ProjectDTO) {
+	if old, ok := st.projects[project.ID]; ok {
+		project.Version = old.Version
+		if !reflect.DeepEqual(old, project) {
+			project.Version++
+		}
+	}
+	st.projects[project.ID] = project
+}
+
+// findColumn returns the column of an active project of the organization of
+// t by name
+func (st *state) findColumn(t tenant, name string, projectID uuid.UUID) (*model.ColumDTO, error) {
+	if _, err := st.activeProject(t, projectID); err != nil {
+		return nil, err
+	}
+	for _, column := range st.columns {
+		if column.ProjectId == projectID && column.Name == name {
+			return &column, nil
+		}
+	}
+	return nil, errors2.ErrNotFound
+}
+
+// projectColumns returns the columns of the project in order
+func (st *state) projectColumns(projectID uuid.UUID) []model.ColumDTO {
+	var columns []model.ColumDTO
+	for _, column := range st.columns {
+		if column.ProjectId == projectID {
+			columns = append(columns, column)
+		}
+	}
+	slices.SortFunc(columns, func(a, b model.ColumDTO) int {
+		return a.Order - b.Order
+	})
+	return columns
+}
+
+// putColumn stores the column, bumping its version when it changed
+func (st *state) putColumn(column model.ColumDTO) {
+	if old, ok := st.columns[column.ID]; ok {
+		column.Version = old.Version
+		if !reflect.DeepEqual(old, column) {
+			column.Version++
+		}
+	}
+	st.columns[column.ID] = column
+}
+
+// putTodo stores the todo, bumping its version when it changed
+func (st *state) putTodo(row todoRow) {
+	if row.Labels == nil {
+		row.Labels = []string{}
+	}
+	if old, ok := st.todos[row.ID]; ok {
+		row.Version = old.Version
+		if !reflect.DeepEqual(old, row) {
+			row.Version++
+		}
+	}
+	st.todos[row.ID] = row
+}
+
+// liveTodo returns the todo of a project of the organization of t unless it
+// or its project is in the trash
+func (st *state) liveTodo(t tenant, id uuid.UUID) (*todoRow, error) {
+	row, ok := st.todos[id]
+	if !ok || row.DeletedAt != nil {
+		return nil, errors2.ErrNotFound
+	}
+	if _, err := st.activeProject(t, row.ProjectID); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// todoView returns the todo as it is read, with the fields computed from its
+// column, checklist and dependencies
+func (st *state) todoView(row *todoRow) *model.TodoDTO {
+	todo := row.TodoDTO
+	todo.Column = st.columns[row.ColumnID].Name
+	todo.Labels = slices.Clone(row.Labels)
+
+	var items, done int
+	for _, item := range st.items {
+		if item.TodoID == row.ID {
+			items++
+			if item.IsDone {
+				done++
+			}
+		}
+	}
+	switch {
+	case items > 0:
+		todo.Progress = 100 * done / items
+	case todo.IsCompleted:
+		todo.Progress = 100
+	default:
+		todo.Progress = 0
+	}
+
+	todo.BlockedBy, todo.Blocks = []uuid.UUID{}, []uuid.UUID{}
+	for dep := range st.dependencies {
+		if dep.Blocked == row.ID && st.todos[dep.Blocker].DeletedAt == nil {
+			todo.BlockedBy = append(todo.BlockedBy, dep.Blocker)
+		}
+		if dep.Blocker == row.ID && st.todos[dep.Blocked].DeletedAt == nil {
+			todo.Blocks = append(todo.Blocks, dep.Blocked)
+		}
+	}
+	slices.SortFunc(todo.BlockedBy, compareUUID)
+	slices.SortFunc(todo.Blocks, compareUUID)
+	return &todo
+}
+
+// getTodo returns the todo of the organization of t as it is read,
+// ErrGetByID when it is in the trash or in another organization
+func (st *state) getTodo(t tenant, id uuid.UUID) (*model.TodoDTO, error) {
+	row, err := st.liveTodo(t, id)
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	return st.todoView(row), nil
+}
+
+// deleteTodo deletes the todo for good together with its checklist,
+// comments, attachments and dependencies
+func (st *state) deleteTodo(id uuid.UUID) {
+	delete(st.todos, id)
+	for itemID, item := range st.items {
+		if item.TodoID == id {
+			delete(st.items, itemID)
+		}
+	}
+	for commentID, comment := range st.comments {
+		if comment.TodoID == id {
+			delete(st.comments, commentID)
+		}
+	}
+	for attachmentID, attachment := range st.attachments {
+		if attachment.TodoID == id {
+			delete(st.attachments, attachmentID)
+		}
+	}
+	for dep := range st.dependencies {
+		if dep.Blocker == id || dep.Blocked == id {
+			delete(st.dependencies, dep)
+		}
+	}
+	for otherID, other := range st.todos {
+		if other.SpawnedFrom != nil && *other.SpawnedFrom == id {
+			other.SpawnedFrom = nil
+			st.todos[otherID] = other
+		}
+	}
+}
+
+// logActivity records a change of an entity of the project like the pgx
+// storage does: the record is queued for the webhooks of the project,
+// written to the outbox as a domain event and announced to listeners
+func (st *state) logActivity(ctx context.Context, projectID uuid.UUID, entity string, entityID uuid.UUID, action string, before, after any) error {
+	changes, err := storage.DiffSnapshots(before, after)
+	if err != nil {
+		return fmt.Errorf("error while diffing %s: %w", entity, err)
+	}
+	if len(changes) == 0 && action == storage.ActionUpdate {
+		return nil
+	}
+
+	var actorID *uuid.UUID
+	if id, ok := storage.ActorFromContext(ctx); ok {
+		actorID = &id
+	}
+
+	st.lastActivityID++
+	activity := model.ActivityDTO{
+		ID:        st.lastActivityID,
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+	st.activity = append(st.activity, activity)
+
+	if err = st.enqueueWebhooks(&activity); err != nil {
+		return err
+	}
+	if err = st.addDomainEvent(&activity, after); err != nil {
+		return err
+	}
+	if !slices.Contains(st.notified, projectID) {
+		st.notified = append(st.notified, projectID)
+	}
+	return nil
+}
+
+// enqueueWebhooks queues the activity record for every webhook of its
+// project subscribed to its event
+func (st *state) enqueueWebhooks(activity *model.ActivityDTO) error {
+	event := activity.Entity + "." + activity.Action
+	var payload []byte
+	for _, webhook := range st.webhooks {
+		if webhook.ProjectID != activity.ProjectID || (len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event)) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(model.WebhookPayload{Event: event, Activity: *activity})
+			if err != nil {
+				return fmt.Errorf("error while encoding webhook payload: %w", err)
+			}
+		}
+		now := activity.CreatedAt
+		delivery := model.WebhookDeliveryDTO{
+			ID:            uuid.New(),
+			WebhookID:     webhook.ID,
+			ActivityID:    activity.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        model.WebhookPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		}
+		st.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+// addDomainEvent writes the domain event of the activity record to the
+// outbox. after is the state of the entity after the change
+func (st *state) addDomainEvent(activity *model.ActivityDTO, after any) error {
+	state, err := storage.Snapshot(after)
+	if err != nil {
+		return fmt.Errorf("error while encoding %s: %w", activity.Entity, err)
+	}
+	st.lastOutboxID++
+	st.outbox = append(st.outbox, outboxRow{DomainEventDTO: model.DomainEventDTO{
+		ID:            st.lastOutboxID,
+		Type:          model.DomainEventType(activity.Entity, activity.Action, activity.Changes),
+		AggregateType: activity.Entity,
+		AggregateID:   activity.EntityID,
+		ProjectID:     activity.ProjectID,
+		ActorID:       activity.ActorID,
+		Changes:       activity.Changes,
+		State:         state,
+		OccurredAt:    activity.CreatedAt,
+		NextAttemptAt: activity.CreatedAt,
+	}})
+	return nil
+}
+
+// compareUUID orders ids like Postgres does, byte by byte
+func compareUUID(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// checkVersion returns ErrVersionMismatch when the version expected by ctx,
+// see storage.WithExpectedVersion, differs from the stored one
+func checkVersion(ctx context.Context, version int64) error {
+	expected := storage.ExpectedVersionFromContext(ctx)
+	if expected != 0 && expected != version {
+		return errors2.ErrVersionMismatch
+	}
+	return nil
+}
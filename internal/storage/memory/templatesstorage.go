@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "TemplateStorage" implements the structure "templateStorage"
+var _ storage.TemplateStorage = (*templateStorage)(nil)
+
+type templateStorage struct {
+	*session
+}
+
+func (store *templateStorage) Create(_ context.Context, template *model.ProjectTemplateDTO) error {
+	return store.write(func(st *state) error {
+		if _, ok := st.templates[template.ID]; ok {
+			return errors2.ErrAlreadyExists
+		}
+		for _, other := range st.templates {
+			if other.CreatedBy == template.CreatedBy && other.Name == template.Name {
+				return errors2.ErrAlreadyExists
+			}
+		}
+		if _, ok := st.users[template.CreatedBy]; !ok {
+			return errors2.ErrInserting
+		}
+		stored := *template
+		stored.Columns = slices.Clone(template.Columns)
+		st.templates[template.ID] = stored
+		return nil
+	})
+}
+
+// GetByID returns a built-in template or a stored one
+func (store *templateStorage) GetByID(_ context.Context, id uuid.UUID) (*model.ProjectTemplateDTO, error) {
+	if template, ok := model.GetBuiltinTemplate(id); ok {
+		return template, nil
+	}
+
+	var template *model.ProjectTemplateDTO
+	err := store.read(func(st *state) error {
+		found, ok := st.templates[id]
+		if !ok {
+			return errors2.ErrTemplateNotFound
+		}
+		template = &found
+		return nil
+	})
+	return template, err
+}
+
+// GetMyTemplates returns the built-in templates followed by the user's ones
+func (store *templateStorage) GetMyTemplates(_ context.Context, createdBy uuid.UUID) ([]model.ProjectTemplateDTO, error) {
+	var mine []model.ProjectTemplateDTO
+	err := store.read(func(st *state) error {
+		for _, template := range st.templates {
+			if template.CreatedBy == createdBy {
+				mine = append(mine, template)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(mine, func(a, b model.ProjectTemplateDTO) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return append(append([]model.ProjectTemplateDTO(nil), model.BuiltinTemplates...), mine...), err
+}
+
+func (store *templateStorage) Delete(_ context.Context, id uuid.UUID, createdBy uuid.UUID) error {
+	return store.write(func(st *state) error {
+		found, ok := st.templates[id]
+		if !ok || found.CreatedBy != createdBy {
+			return errors2.ErrTemplateNotFound
+		}
+		delete(st.templates, id)
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// tenant is the organization storages of projects and their boards work in,
+// like the tenant of the pgx storage. The state methods that find projects,
+// todos and webhooks take it, so rows of other organizations are never found
+type tenant struct {
+	orgID uuid.UUID
+}
+
+// tenantOf returns the tenant of the organization set by storage.WithTenant,
+// ErrNoOrganization when ctx has none
+func tenantOf(ctx context.Context) (tenant, error) {
+	orgID, ok := storage.TenantFromContext(ctx)
+	if !ok {
+		return tenant{}, errors2.ErrNoOrganization
+	}
+	return tenant{orgID: orgID}, nil
+}
+
+// ownsProject reports whether the project, in the trash or not, belongs to
+// the organization of t
+func (st *state) ownsProject(t tenant, projectID uuid.UUID) bool {
+	project, ok := st.projects[projectID]
+	return ok && project.OrgID == t.orgID
+}
+
+// tenantTodo returns the todo, in the trash or not, of a project of the
+// organization of t
+func (st *state) tenantTodo(t tenant, id uuid.UUID) (todoRow, bool) {
+	row, ok := st.todos[id]
+	if !ok || !st.ownsProject(t, row.ProjectID) {
+		return todoRow{}, false
+	}
+	return row, true
+}
+
+// ownsTodo reports whether the todo, in the trash or not, belongs to a
+// project of the organization of t
+func (st *state) ownsTodo(t tenant, id uuid.UUID) bool {
+	_, ok := st.tenantTodo(t, id)
+	return ok
+}
+
+// ownsWebhook reports whether the webhook belongs to a project of the
+// organization of t
+func (st *state) ownsWebhook(t tenant, id uuid.UUID) bool {
+	webhook, ok := st.webhooks[id]
+	return ok && st.ownsProject(t, webhook.ProjectID)
+}
+
+// isMember reports whether the user is a member of the organization of t
+func (st *state) isMember(t tenant, userID uuid.UUID) bool {
+	_, ok := st.members[membership{OrgID: t.orgID, UserID: userID}]
+	return ok
+}
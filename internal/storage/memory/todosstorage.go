@@ -0,0 +1,941 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/rank"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "TodoStorage" implements the structure "todoStorage"
+var _ storage.TodoStorage = (*todoStorage)(nil)
+
+// errRollback discards the changes of an all or nothing bulk request
+var errRollback = errors.New("bulk request is rolled back")
+
+type todoStorage struct {
+	*session
+}
+
+// Create puts the todo at the end of its column. A todo created in a done
+// column is completed. Unless force is set, a column that reached its WIP
+// limit rejects the todo with ErrWipLimitReached
+func (store *todoStorage) Create(ctx context.Context, todo *model.TodoDTO, force bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		column, err := st.findColumn(t, todo.Column, todo.ProjectID)
+		if err != nil {
+			return err
+		}
+		if !force {
+			if err = st.checkWipLimit(column, todo.ID); err != nil {
+				return err
+			}
+		}
+		todo.ColumnID = column.ID
+		todo.IsCompleted = todo.IsCompleted || column.IsDone
+		todo.Position = rank.After(st.lastPosition(todo.ColumnID, todo.ID))
+		if todo.IsCompleted {
+			todo.Progress = 100
+		}
+
+		err = st.insertTodo(todoRow{TodoDTO: model.TodoDTO{
+			ID:          todo.ID,
+			Name:        todo.Name,
+			Description: todo.Description,
+			IsCompleted: todo.IsCompleted,
+			CreatedBy:   todo.CreatedBy,
+			ProjectID:   todo.ProjectID,
+			ColumnID:    todo.ColumnID,
+			Position:    todo.Position,
+			Recurrence:  todo.Recurrence,
+			DueDate:     dueDate(todo.DueDate),
+		}})
+		if err != nil {
+			return err
+		}
+		return st.logActivity(ctx, todo.ProjectID, storage.EntityTodo, todo.ID, storage.ActionCreate, nil, todo)
+	})
+}
+
+func (store *todoStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.TodoDTO
+	err = store.read(func(st *state) (err error) {
+		todo, err = st.getTodo(t, id)
+		return err
+	})
+	return todo, err
+}
+
+// GetAll returns todos of the user in the organization of ctx, archived todos
+// and todos of archived projects only when includeArchived is set
+func (store *todoStorage) GetAll(ctx context.Context, createdBy uuid.UUID, includeArchived bool) ([]model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.TodoDTO
+	err = store.read(func(st *state) error {
+		rows := st.selectTodos(t, func(row *todoRow, project *model.ProjectDTO) bool {
+			return row.CreatedBy == createdBy && row.DeletedAt == nil &&
+				(includeArchived || (row.ArchivedAt == nil && project.ArchivedAt == nil))
+		})
+		slices.SortFunc(rows, func(a, b todoRow) int {
+			return cmp.Or(
+				compareUUID(a.ProjectID, b.ProjectID),
+				st.columns[a.ColumnID].Order-st.columns[b.ColumnID].Order,
+				strings.Compare(a.Position, b.Position),
+			)
+		})
+		for i := range rows {
+			res = append(res, *st.todoView(&rows[i]))
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Update refuses to complete a todo that has incomplete blockers with
+// ErrBlocked, unless force is set. Completing a recurring todo spawns its
+// next occurrence
+func (store *todoStorage) Update(ctx context.Context, todo *model.TodoDTO, id uuid.UUID, force bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		return store.update(ctx, t, st, todo, id, force)
+	})
+}
+
+// update is Update within st
+func (store *todoStorage) update(ctx context.Context, t tenant, st *state, todo *model.TodoDTO, id uuid.UUID, force bool) error {
+	row, err := st.liveTodo(t, id)
+	if err != nil {
+		return err
+	}
+	isCompleted := row.IsCompleted
+	if !force && todo.IsCompleted && !isCompleted {
+		if err = st.checkBlockers(id); err != nil {
+			return err
+		}
+	}
+
+	before := st.todoView(row)
+	if err = checkVersion(ctx, row.Version); err != nil {
+		return err
+	}
+	if todo.Name != row.Name && st.todoNameTaken(row.ProjectID, todo.Name, id) {
+		return errors2.ErrAlreadyExists
+	}
+	row.Name, row.Description, row.IsCompleted = todo.Name, todo.Description, todo.IsCompleted
+	st.putTodo(*row)
+	if todo.IsCompleted && !isCompleted {
+		if err = store.spawnNext(ctx, t, st, id); err != nil {
+			return err
+		}
+	}
+
+	after, err := st.getTodo(t, id)
+	if err != nil {
+		return err
+	}
+	return st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, storage.ActionUpdate, before, after)
+}
+
+// Move changes the column and the position of the todo at once. The todo is
+// placed right after move.AfterID, right before move.BeforeID or, when both
+// are zero, at the end of the target column. Moving into a done column
+// completes the todo, moving out of it clears the completion. Completing a
+// recurring todo spawns its next occurrence
+func (store *todoStorage) Move(ctx context.Context, id uuid.UUID, move *model.TodoMoveDTO) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.TodoDTO
+	err = store.write(func(st *state) (err error) {
+		todo, err = store.move(ctx, t, st, id, move)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// move is Move within st
+func (store *todoStorage) move(ctx context.Context, t tenant, st *state, id uuid.UUID, move *model.TodoMoveDTO) (*model.TodoDTO, error) {
+	row, ok := st.tenantTodo(t, id)
+	if !ok || row.DeletedAt != nil {
+		return nil, errors2.ErrNotFound
+	}
+	if row.ArchivedAt != nil {
+		return nil, errors2.ErrArchived
+	}
+	sourceIsDone := st.columns[row.ColumnID].IsDone
+
+	column, err := st.findColumn(t, move.Column, row.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !move.Force && column.ID != row.ColumnID {
+		if err = st.checkWipLimit(column, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var prev, next string
+	switch {
+	case move.AfterID != uuid.Nil:
+		prev, err = st.positionInColumn(move.AfterID, column.ID)
+		if err == nil {
+			next = st.nextPosition(column.ID, id, prev)
+		}
+	case move.BeforeID != uuid.Nil:
+		next, err = st.positionInColumn(move.BeforeID, column.ID)
+		if err == nil {
+			prev = st.prevPosition(column.ID, id, next)
+		}
+	default:
+		prev = st.lastPosition(column.ID, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := rank.BetweenChecked(prev, next)
+	if err != nil {
+		return nil, fmt.Errorf("error while ranking todo: %w", err)
+	}
+
+	before, err := st.getTodo(t, id)
+	if err != nil {
+		return nil, err
+	}
+	isCompleted := before.IsCompleted
+	spawn := false
+	if column.IsDone != sourceIsDone {
+		if !move.Force && column.IsDone && !isCompleted {
+			if err = st.checkBlockers(id); err != nil {
+				return nil, err
+			}
+		}
+		spawn = column.IsDone && !isCompleted
+		isCompleted = column.IsDone
+	}
+
+	row.ColumnID, row.Position, row.IsCompleted = column.ID, position, isCompleted
+	st.putTodo(row)
+	if spawn {
+		if err = store.spawnNext(ctx, t, st, id); err != nil {
+			return nil, err
+		}
+	}
+
+	todo, err := st.getTodo(t, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, storage.ActionMove, before, todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// Patch changes only the fields set in patch. A new column or project puts
+// the todo at the end of the target column, which must not have reached its
+// WIP limit unless patch.Force is set. The target project must be active,
+// not archived, in the same organization and owned by userID, and a todo
+// linked by dependencies can not leave its project. Completion follows the
+// target column like in Move
+func (store *todoStorage) Patch(ctx context.Context, id uuid.UUID, userID uuid.UUID, patch *model.TodoPatchDTO) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *model.TodoDTO
+	err = store.write(func(st *state) error {
+		row, err := st.liveTodo(t, id)
+		if err != nil {
+			return err
+		}
+		projectID := row.ProjectID
+		sourceIsDone := st.columns[row.ColumnID].IsDone
+		before := st.todoView(row)
+
+		todo := *before
+		if patch.Name != nil {
+			todo.Name = *patch.Name
+		}
+		if patch.Description != nil {
+			todo.Description = *patch.Description
+		}
+		if patch.Labels != nil {
+			todo.Labels = model.NormalizeLabels(*patch.Labels)
+		}
+		if patch.IsCompleted != nil {
+			todo.IsCompleted = *patch.IsCompleted
+		}
+
+		if patch.ProjectID != nil && *patch.ProjectID != projectID {
+			if patch.Column == nil {
+				return errors2.ErrProjectColumn
+			}
+			if len(before.BlockedBy) > 0 || len(before.Blocks) > 0 {
+				return errors2.ErrBadDependency
+			}
+			project, err := st.activeProject(t, *patch.ProjectID)
+			if err != nil {
+				return errors2.ErrProjectTarget
+			}
+			if project.CreatedBy != userID {
+				return errors2.ErrNotAccessible
+			}
+			if project.ArchivedAt != nil {
+				return errors2.ErrArchived
+			}
+			todo.ProjectID = project.ID
+		}
+
+		if patch.Column != nil && (*patch.Column != before.Column || todo.ProjectID != projectID) {
+			if row.ArchivedAt != nil {
+				return errors2.ErrArchived
+			}
+			column, err := st.findColumn(t, *patch.Column, todo.ProjectID)
+			if err != nil {
+				return errors2.ErrColumnTarget
+			}
+			if !patch.Force {
+				if err = st.checkWipLimit(column, id); err != nil {
+					return err
+				}
+			}
+			todo.ColumnID = column.ID
+			todo.Position = rank.After(st.lastPosition(column.ID, id))
+			if column.IsDone != sourceIsDone {
+				if patch.IsCompleted != nil && *patch.IsCompleted != column.IsDone {
+					return errors2.ErrCompletionConflict
+				}
+				todo.IsCompleted = column.IsDone
+			}
+		}
+
+		if !patch.Force && todo.IsCompleted && !before.IsCompleted {
+			if err = st.checkBlockers(id); err != nil {
+				return err
+			}
+		}
+
+		if (todo.Name != row.Name || todo.ProjectID != row.ProjectID) && st.todoNameTaken(todo.ProjectID, todo.Name, id) {
+			return errors2.ErrAlreadyExists
+		}
+		if err = checkVersion(ctx, row.Version); err != nil {
+			return err
+		}
+		row.Name, row.Description, row.IsCompleted, row.Labels = todo.Name, todo.Description, todo.IsCompleted, todo.Labels
+		row.ProjectID, row.ColumnID, row.Position = todo.ProjectID, todo.ColumnID, todo.Position
+		st.putTodo(*row)
+		if todo.IsCompleted && !before.IsCompleted {
+			if err = store.spawnNext(ctx, t, st, id); err != nil {
+				return err
+			}
+		}
+
+		if after, err = st.getTodo(t, id); err != nil {
+			return err
+		}
+		action := storage.ActionUpdate
+		if after.ColumnID != before.ColumnID {
+			action = storage.ActionMove
+		}
+		if err = st.logActivity(ctx, projectID, storage.EntityTodo, id, action, before, after); err != nil {
+			return err
+		}
+		// the target project gets its own record of the todo coming in
+		if after.ProjectID != projectID {
+			return st.logActivity(ctx, after.ProjectID, storage.EntityTodo, id, action, before, after)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// AddDependency records that blockerID blocks blockedID. Both todos must
+// belong to the same project, and a link that would close a cycle is
+// rejected with ErrDependencyCycle
+func (store *todoStorage) AddDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	if blockedID == blockerID {
+		return errors2.ErrBadDependency
+	}
+
+	return store.write(func(st *state) error {
+		blocked, ok := st.tenantTodo(t, blockedID)
+		if !ok || blocked.DeletedAt != nil {
+			return errors2.ErrNotFound
+		}
+		blocker, ok := st.tenantTodo(t, blockerID)
+		if !ok || blocker.DeletedAt != nil {
+			return errors2.ErrNotFound
+		}
+		if blocked.ProjectID != blocker.ProjectID {
+			return errors2.ErrBadDependency
+		}
+
+		if st.dependencyPath(blockedID, blockerID) {
+			return errors2.ErrDependencyCycle
+		}
+		dep := dependency{Blocker: blockerID, Blocked: blockedID}
+		if _, ok = st.dependencies[dep]; ok {
+			return errors2.ErrAlreadyExists
+		}
+		st.dependencies[dep] = struct{}{}
+		return nil
+	})
+}
+
+func (store *todoStorage) RemoveDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		dep := dependency{Blocker: blockerID, Blocked: blockedID}
+		if _, ok := st.dependencies[dep]; !ok {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.tenantTodo(t, blockedID); !ok {
+			return errors2.ErrNotFound
+		}
+		delete(st.dependencies, dep)
+		return nil
+	})
+}
+
+// dependencyPath reports whether "to" is reachable from "from" by following
+// blocker -> blocked edges
+func (st *state) dependencyPath(from, to uuid.UUID) bool {
+	reachable := map[uuid.UUID]bool{from: true}
+	queue := []uuid.UUID{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			return true
+		}
+		for dep := range st.dependencies {
+			if dep.Blocker == id && !reachable[dep.Blocked] {
+				reachable[dep.Blocked] = true
+				queue = append(queue, dep.Blocked)
+			}
+		}
+	}
+	return false
+}
+
+// spawnNext creates the next occurrence of the completed todo at the end of
+// the first column of its project. It does nothing for todos without a
+// recurrence rule, and a todo spawns at most one occurrence, so completing it
+// again after reopening is harmless. The WIP limit of the first column is
+// not checked, the occurrence is created by the system, not by a user
+func (store *todoStorage) spawnNext(ctx context.Context, t tenant, st *state, id uuid.UUID) error {
+	row := st.todos[id]
+	if row.Recurrence == "" {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(row.Recurrence)
+	if err != nil {
+		return err
+	}
+	now := store.clock.Now()
+	prev := now
+	if row.DueDate != nil {
+		prev = *row.DueDate
+	}
+	nextDue := rule.NextAfter(prev, now)
+
+	columns := st.projectColumns(row.ProjectID)
+	if len(columns) == 0 {
+		return fmt.Errorf("error while locking first column: %w", errors2.ErrNotFound)
+	}
+	first := columns[0]
+	for _, other := range st.todos {
+		if other.SpawnedFrom != nil && *other.SpawnedFrom == id {
+			return nil
+		}
+	}
+
+	spawnedFrom := id
+	next := todoRow{
+		TodoDTO: model.TodoDTO{
+			ID:          uuid.New(),
+			Name:        recurrence.OccurrenceName(row.Name, nextDue),
+			Description: row.Description,
+			IsCompleted: first.IsDone,
+			CreatedBy:   row.CreatedBy,
+			ProjectID:   row.ProjectID,
+			ColumnID:    first.ID,
+			Position:    rank.After(st.lastPosition(first.ID, id)),
+			Recurrence:  row.Recurrence,
+			DueDate:     dueDate(&nextDue),
+		},
+		SpawnedFrom: &spawnedFrom,
+	}
+	if err = st.insertTodo(next); err != nil {
+		return err
+	}
+
+	spawned, err := st.getTodo(t, next.ID)
+	if err != nil {
+		return err
+	}
+	return st.logActivity(ctx, next.ProjectID, storage.EntityTodo, next.ID, storage.ActionCreate, nil, spawned)
+}
+
+// Delete moves the todo to the trash
+func (store *todoStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		return store.delete(ctx, t, st, id)
+	})
+}
+
+// delete is Delete within st
+func (store *todoStorage) delete(ctx context.Context, t tenant, st *state, id uuid.UUID) error {
+	row, err := st.liveTodo(t, id)
+	if err != nil {
+		return err
+	}
+	before := st.todoView(row)
+	if err = checkVersion(ctx, row.Version); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	row.DeletedAt = &now
+	st.putTodo(*row)
+	return st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, storage.ActionDelete, before, nil)
+}
+
+// Bulk applies the operations on behalf of userID at once, every operation
+// on its own copy of the state like in a savepoint. Only the creator of a
+// todo and the owner of its project may change it. When atomic is set the
+// first failure rolls back every operation and the rest of them is skipped,
+// otherwise a failed operation is rolled back alone and the others are kept
+func (store *todoStorage) Bulk(ctx context.Context, userID uuid.UUID, ops []model.TodoBulkOperation, atomic bool) ([]model.TodoBulkResult, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.TodoBulkResult, len(ops))
+	for i := range ops {
+		results[i] = model.TodoBulkResult{TodoID: ops[i].TodoID, Op: ops[i].Op, Status: model.BulkStatusSkipped}
+	}
+
+	err = store.write(func(st *state) error {
+		for i := range ops {
+			savepoint := st.clone()
+			todo, err := store.applyBulk(ctx, t, savepoint, userID, &ops[i])
+			if err != nil {
+				results[i].Status = model.BulkStatusFailed
+				results[i].Err = err
+				if atomic {
+					for j := 0; j < i; j++ {
+						results[j].Status = model.BulkStatusRolledBack
+						results[j].Todo = nil
+					}
+					return errRollback
+				}
+				continue
+			}
+			*st = *savepoint
+			results[i].Status = model.BulkStatusOK
+			results[i].Todo = todo
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return results, nil
+}
+
+// applyBulk checks that userID may change the todo and applies the operation
+// within st. It returns the changed todo, nil when the todo was deleted
+func (store *todoStorage) applyBulk(ctx context.Context, t tenant, st *state, userID uuid.UUID, op *model.TodoBulkOperation) (*model.TodoDTO, error) {
+	row, err := st.liveTodo(t, op.TodoID)
+	if err != nil {
+		return nil, err
+	}
+	if row.CreatedBy != userID && st.projects[row.ProjectID].CreatedBy != userID {
+		return nil, errors2.ErrNotAccessible
+	}
+
+	switch op.Op {
+	case model.BulkComplete:
+		todo, err := st.getTodo(t, op.TodoID)
+		if err != nil {
+			return nil, err
+		}
+		if todo.IsCompleted {
+			return todo, nil
+		}
+		todo.IsCompleted = true
+		if err = store.update(ctx, t, st, todo, op.TodoID, op.Force); err != nil {
+			return nil, err
+		}
+	case model.BulkMove:
+		return store.move(ctx, t, st, op.TodoID, &model.TodoMoveDTO{Column: op.Column, Force: op.Force})
+	case model.BulkDelete:
+		return nil, store.delete(ctx, t, st, op.TodoID)
+	case model.BulkRelabel:
+		err = st.changeTodo(ctx, t, op.TodoID, func(row *todoRow) error {
+			row.Labels = model.NormalizeLabels(op.Labels)
+			return nil
+		})
+	case model.BulkAssign:
+		err = st.changeTodo(ctx, t, op.TodoID, func(row *todoRow) error {
+			// todos are assigned to members of the organization only
+			if op.AssigneeID != nil && !st.isMember(t, *op.AssigneeID) {
+				return errors2.ErrAssigneeNotFound
+			}
+			row.AssigneeID = op.AssigneeID
+			return nil
+		})
+	default:
+		return nil, errors2.ErrBadBulkOperation
+	}
+	if err != nil {
+		return nil, err
+	}
+	return st.getTodo(t, op.TodoID)
+}
+
+// changeTodo applies change to the todo and logs the update
+func (st *state) changeTodo(ctx context.Context, t tenant, id uuid.UUID, change func(row *todoRow) error) error {
+	row, err := st.liveTodo(t, id)
+	if err != nil {
+		return err
+	}
+	before := st.todoView(row)
+	if err = change(row); err != nil {
+		return err
+	}
+	st.putTodo(*row)
+	after, err := st.getTodo(t, id)
+	if err != nil {
+		return err
+	}
+	return st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, storage.ActionUpdate, before, after)
+}
+
+// Archive takes the todo out of its column or, when archived is false, puts
+// it back at the end of the column. A todo whose column was deleted meanwhile
+// goes to the column with the same name or else the first one. Comments,
+// checklist and activity of the todo are kept. Like Restore, unarchiving does
+// not check the WIP limit
+func (store *todoStorage) Archive(ctx context.Context, id uuid.UUID, archived bool) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.TodoDTO
+	err = store.write(func(st *state) error {
+		row, err := st.liveTodo(t, id)
+		if err != nil {
+			return err
+		}
+		before := st.todoView(row)
+		if archived == (before.ArchivedAt != nil) {
+			todo = before
+			return nil
+		}
+
+		if archived {
+			now := time.Now()
+			row.ArchivedAt = &now
+		} else {
+			if err = st.backColumn(row); err != nil {
+				return err
+			}
+			row.ArchivedAt = nil
+			row.Position = rank.After(st.lastPosition(row.ColumnID, id))
+		}
+		st.putTodo(*row)
+
+		if todo, err = st.getTodo(t, id); err != nil {
+			return err
+		}
+		action := storage.ActionArchive
+		if !archived {
+			action = storage.ActionUnarchive
+		}
+		return st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, action, before, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// GetTrash returns deleted todos of the user in the organization of ctx, most
+// recently deleted first. Todos of deleted projects are left out
+func (store *todoStorage) GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.TodoDTO
+	err = store.read(func(st *state) error {
+		rows := st.selectTodos(t, func(row *todoRow, _ *model.ProjectDTO) bool {
+			return row.CreatedBy == createdBy && row.DeletedAt != nil
+		})
+		slices.SortFunc(rows, func(a, b todoRow) int {
+			return b.DeletedAt.Compare(*a.DeletedAt)
+		})
+		for i := range rows {
+			res = append(res, *st.todoView(&rows[i]))
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Restore takes the todo out of the trash and puts it at the end of its
+// column, or of the column Archive would pick when its column was deleted.
+// The WIP limit of the column is not checked
+func (store *todoStorage) Restore(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.TodoDTO
+	err = store.write(func(st *state) error {
+		row, ok := st.tenantTodo(t, id)
+		if !ok || row.DeletedAt == nil {
+			return errors2.ErrNotFound
+		}
+		if _, err := st.activeProject(t, row.ProjectID); err != nil {
+			return err
+		}
+
+		if err := st.backColumn(&row); err != nil {
+			return err
+		}
+		row.DeletedAt = nil
+		row.Position = rank.After(st.lastPosition(row.ColumnID, id))
+		st.putTodo(row)
+
+		var err error
+		if todo, err = st.getTodo(t, id); err != nil {
+			return err
+		}
+		return st.logActivity(ctx, row.ProjectID, storage.EntityTodo, id, storage.ActionRestore, nil, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// Purge deletes for good the todos that were deleted before deletedBefore
+// and returns how many were removed
+func (store *todoStorage) Purge(_ context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := store.write(func(st *state) error {
+		for id, row := range st.todos {
+			if row.DeletedAt != nil && row.DeletedAt.Before(deletedBefore) {
+				st.deleteTodo(id)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// backColumn gives a todo detached from its deleted column the column of the
+// project with the same name or else the first one. ErrColumnTarget is
+// returned when the project has no columns left
+func (st *state) backColumn(row *todoRow) error {
+	if row.ColumnID != uuid.Nil {
+		return nil
+	}
+	columns := st.projectColumns(row.ProjectID)
+	if len(columns) == 0 {
+		return errors2.ErrColumnTarget
+	}
+	row.ColumnID = columns[0].ID
+	for _, column := range columns {
+		if column.Name == row.LastColumn {
+			row.ColumnID = column.ID
+			break
+		}
+	}
+	row.LastColumn = ""
+	return nil
+}
+
+// insertTodo adds a new todo. Names of todos are unique within their project
+func (st *state) insertTodo(row todoRow) error {
+	if _, ok := st.todos[row.ID]; ok {
+		return errors2.ErrAlreadyExists
+	}
+	if st.todoNameTaken(row.ProjectID, row.Name, row.ID) {
+		return errors2.ErrAlreadyExists
+	}
+	if _, ok := st.users[row.CreatedBy]; !ok {
+		return errors2.ErrInserting
+	}
+	row.Version = 1
+	st.putTodo(row)
+	return nil
+}
+
+func (st *state) todoNameTaken(projectID uuid.UUID, name string, except uuid.UUID) bool {
+	for _, row := range st.todos {
+		if row.ProjectID == projectID && row.Name == name && row.ID != except {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTodos returns the todos of active projects of the organization of t
+// that match
+func (st *state) selectTodos(t tenant, match func(row *todoRow, project *model.ProjectDTO) bool) []todoRow {
+	var rows []todoRow
+	for _, row := range st.todos {
+		project, err := st.activeProject(t, row.ProjectID)
+		if err == nil && match(&row, project) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// columnPositions returns the positions taken in the column. Archived and
+// deleted todos are not part of their column, and exclude is the todo being
+// placed
+func (st *state) columnPositions(columnID, exclude uuid.UUID) []string {
+	var positions []string
+	for _, row := range st.todos {
+		if row.ColumnID == columnID && row.ID != exclude && row.DeletedAt == nil && row.ArchivedAt == nil {
+			positions = append(positions, row.Position)
+		}
+	}
+	return positions
+}
+
+func (st *state) lastPosition(columnID, exclude uuid.UUID) string {
+	var last string
+	for _, position := range st.columnPositions(columnID, exclude) {
+		last = max(last, position)
+	}
+	return last
+}
+
+func (st *state) nextPosition(columnID, exclude uuid.UUID, after string) string {
+	var next string
+	for _, position := range st.columnPositions(columnID, exclude) {
+		if position > after && (next == "" || position < next) {
+			next = position
+		}
+	}
+	return next
+}
+
+func (st *state) prevPosition(columnID, exclude uuid.UUID, before string) string {
+	var prev string
+	for _, position := range st.columnPositions(columnID, exclude) {
+		if position < before {
+			prev = max(prev, position)
+		}
+	}
+	return prev
+}
+
+func (st *state) positionInColumn(id, columnID uuid.UUID) (string, error) {
+	row, ok := st.todos[id]
+	if !ok || row.ColumnID != columnID || row.DeletedAt != nil || row.ArchivedAt != nil {
+		return "", errors2.ErrBadPosition
+	}
+	return row.Position, nil
+}
+
+// checkWipLimit returns ErrWipLimitReached when the column can not take one
+// more todo. id is the todo being added, it is not counted
+func (st *state) checkWipLimit(column *model.ColumDTO, id uuid.UUID) error {
+	if column.WipLimit == nil {
+		return nil
+	}
+	if len(st.columnPositions(column.ID, id)) >= *column.WipLimit {
+		return errors2.ErrWipLimitReached
+	}
+	return nil
+}
+
+// checkBlockers returns ErrBlocked when the todo has incomplete blockers
+func (st *state) checkBlockers(id uuid.UUID) error {
+	for dep := range st.dependencies {
+		if dep.Blocked != id {
+			continue
+		}
+		if blocker := st.todos[dep.Blocker]; !blocker.IsCompleted && blocker.DeletedAt == nil {
+			return errors2.ErrBlocked
+		}
+	}
+	return nil
+}
+
+// dueDate keeps the calendar date only, like the DATE column of the pgx
+// storage
+func dueDate(due *time.Time) *time.Time {
+	if due == nil {
+		return nil
+	}
+	date := recurrence.Date(*due)
+	return &date
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "UserStorage" implements the structure "userStorage"
+var _ storage.UserStorage = (*userStorage)(nil)
+
+type userStorage struct {
+	*session
+}
+
+// Create inserts the user together with their personal organization, which
+// has the id of the user
+func (store *userStorage) Create(_ context.Context, user *model.UserDTO) error {
+	return store.write(func(st *state) error {
+		if _, ok := st.users[user.ID]; ok {
+			return errors2.ErrAlreadyExists
+		}
+		if st.userByLogin(user.Login) != nil {
+			return errors2.ErrAlreadyExists
+		}
+		st.users[user.ID] = *user
+		personal := &model.OrganizationDTO{ID: user.ID, Name: user.Login, CreatedBy: user.ID}
+		if err := st.createOrganization(personal); err != nil {
+			return errors2.ErrInserting
+		}
+		return nil
+	})
+}
+
+func (store *userStorage) GetByID(_ context.Context, id uuid.UUID) (*model.UserDTO, error) {
+	var user *model.UserDTO
+	err := store.read(func(st *state) error {
+		u, ok := st.users[id]
+		if !ok {
+			return errors2.ErrGetByID
+		}
+		user = &u
+		return nil
+	})
+	return user, err
+}
+
+func (store *userStorage) GetByLogin(_ context.Context, login string) (*model.UserDTO, error) {
+	var user *model.UserDTO
+	err := store.read(func(st *state) error {
+		if user = st.userByLogin(login); user == nil {
+			return errors2.ErrGetByLogin
+		}
+		return nil
+	})
+	return user, err
+}
+
+func (store *userStorage) ChangePassword(_ context.Context, password string, id uuid.UUID) error {
+	return store.write(func(st *state) error {
+		if user, ok := st.users[id]; ok {
+			user.Password = password
+			st.users[id] = user
+		}
+		return nil
+	})
+}
+
+func (st *state) userByLogin(login string) *model.UserDTO {
+	for _, user := range st.users {
+		if user.Login == login {
+			return &user
+		}
+	}
+	return nil
+}
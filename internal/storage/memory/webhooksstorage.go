@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "WebhookStorage" implements the structure "webhookStorage"
+var _ storage.WebhookStorage = (*webhookStorage)(nil)
+
+type webhookStorage struct {
+	*session
+}
+
+func (store *webhookStorage) Create(ctx context.Context, webhook *model.WebhookDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		if _, ok := st.webhooks[webhook.ID]; ok {
+			return errors2.ErrAlreadyExists
+		}
+		if !st.ownsProject(t, webhook.ProjectID) {
+			return errors2.ErrNotFound
+		}
+		if _, ok := st.users[webhook.CreatedBy]; !ok {
+			return errors2.ErrInserting
+		}
+
+		webhook.CreatedAt = time.Now()
+		stored := *webhook
+		stored.Events = append([]string{}, webhook.Events...)
+		st.webhooks[webhook.ID] = stored
+		return nil
+	})
+}
+
+func (store *webhookStorage) GetByID(ctx context.Context, id uuid.UUID, projectID uuid.UUID) (*model.WebhookDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook *model.WebhookDTO
+	err = store.read(func(st *state) error {
+		found, ok := st.webhooks[id]
+		if !ok || found.ProjectID != projectID || !st.ownsProject(t, projectID) {
+			return errors2.ErrNotFound
+		}
+		webhook = &found
+		return nil
+	})
+	return webhook, err
+}
+
+func (store *webhookStorage) GetAll(ctx context.Context, projectID uuid.UUID) ([]model.WebhookDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.WebhookDTO
+	err = store.read(func(st *state) error {
+		if !st.ownsProject(t, projectID) {
+			return nil
+		}
+		for _, webhook := range st.webhooks {
+			if webhook.ProjectID == projectID {
+				res = append(res, webhook)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.WebhookDTO) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), compareUUID(a.ID, b.ID))
+	})
+	return res, err
+}
+
+// Delete removes the webhook together with its deliveries
+func (store *webhookStorage) Delete(ctx context.Context, id uuid.UUID, projectID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		found, ok := st.webhooks[id]
+		if !ok || found.ProjectID != projectID || !st.ownsProject(t, projectID) {
+			return errors2.ErrNotFound
+		}
+		st.deleteWebhook(id)
+		return nil
+	})
+}
+
+// GetDeliveries returns a page of deliveries of the webhook, newest first,
+// and the total number of its deliveries
+func (store *webhookStorage) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]model.WebhookDeliveryDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res []model.WebhookDeliveryDTO
+	err = store.read(func(st *state) error {
+		if !st.ownsWebhook(t, webhookID) {
+			return nil
+		}
+		for _, delivery := range st.deliveries {
+			if delivery.WebhookID == webhookID {
+				delivery.History = nil
+				res = append(res, delivery)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(res, func(a, b model.WebhookDeliveryDTO) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ActivityID, a.ActivityID))
+	})
+	return page(res, limit, offset), len(res), err
+}
+
+// GetDelivery returns the delivery with the history of its attempts
+func (store *webhookStorage) GetDelivery(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) (*model.WebhookDeliveryDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery *model.WebhookDeliveryDTO
+	err = store.read(func(st *state) error {
+		found, ok := st.deliveries[id]
+		if !ok || found.WebhookID != webhookID || !st.ownsWebhook(t, webhookID) {
+			return errors2.ErrNotFound
+		}
+		found.History = slices.Clone(found.History)
+		delivery = &found
+		return nil
+	})
+	return delivery, err
+}
+
+// Redeliver queues the delivery again with a fresh budget of attempts, the
+// history of the previous attempts is kept
+func (store *webhookStorage) Redeliver(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.write(func(st *state) error {
+		found, ok := st.deliveries[id]
+		if !ok || found.WebhookID != webhookID || !st.ownsWebhook(t, webhookID) {
+			return errors2.ErrNotFound
+		}
+		now := time.Now()
+		found.Status, found.Attempts, found.NextAttemptAt = model.WebhookPending, 0, &now
+		st.deliveries[id] = found
+		return nil
+	})
+}
+
+// ClaimDue takes up to limit pending deliveries that are due and hides them
+// from other dispatchers for lease. A delivery that is not recorded within
+// the lease is claimed again
+func (store *webhookStorage) ClaimDue(_ context.Context, lease time.Duration, limit int) ([]model.WebhookJobDTO, error) {
+	var res []model.WebhookJobDTO
+	err := store.write(func(st *state) error {
+		now := time.Now()
+		var due []model.WebhookDeliveryDTO
+		for _, delivery := range st.deliveries {
+			if delivery.Status == model.WebhookPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+				due = append(due, delivery)
+			}
+		}
+		slices.SortFunc(due, func(a, b model.WebhookDeliveryDTO) int {
+			return a.NextAttemptAt.Compare(*b.NextAttemptAt)
+		})
+
+		leased := now.Add(lease)
+		for _, delivery := range page(due, limit, 0) {
+			delivery.NextAttemptAt = &leased
+			st.deliveries[delivery.ID] = delivery
+
+			webhook := st.webhooks[delivery.WebhookID]
+			job := model.WebhookJobDTO{Delivery: delivery, URL: webhook.URL, Secret: webhook.Secret}
+			job.Delivery.NextAttemptAt, job.Delivery.History = nil, nil
+			res = append(res, job)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// RecordAttempt logs the attempt and moves its delivery to status. A pending
+// delivery is tried again at nextAttemptAt
+func (store *webhookStorage) RecordAttempt(_ context.Context, attempt *model.WebhookAttemptDTO, status string, nextAttemptAt *time.Time) error {
+	return store.write(func(st *state) error {
+		delivery, ok := st.deliveries[attempt.DeliveryID]
+		if !ok {
+			return fmt.Errorf("error while recording attempt: %w", errors2.ErrNotFound)
+		}
+
+		// the history may be shared with a snapshot, it is copied on append
+		delivery.History = append(slices.Clip(delivery.History), *attempt)
+		delivery.Status = status
+		delivery.Attempts++
+		delivery.NextAttemptAt = nextAttemptAt
+		delivery.LastStatusCode = attempt.StatusCode
+		delivery.LastError = attempt.Error
+		delivery.DeliveredAt = nil
+		if status == model.WebhookDelivered {
+			attemptedAt := attempt.AttemptedAt
+			delivery.DeliveredAt = &attemptedAt
+		}
+		st.deliveries[delivery.ID] = delivery
+		return nil
+	})
+}
+
+// deleteWebhook deletes the webhook for good together with its deliveries
+func (st *state) deleteWebhook(id uuid.UUID) {
+	delete(st.webhooks, id)
+	for deliveryID, delivery := range st.deliveries {
+		if delivery.WebhookID == id {
+			delete(st.deliveries, deliveryID)
+		}
+	}
+}
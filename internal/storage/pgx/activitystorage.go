@@ -0,0 +1,178 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Checking whether the interface "ActivityStorage" implements the structure "activityStorage"
+var _ storage.ActivityStorage = (*activityStorage)(nil)
+
+// activityStorage reads the activity log. Records are written by the other
+// storages with logActivity inside their own transactions
+type activityStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newActivityStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*activityStorage, error) {
+	store := &activityStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *activityStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateActivity)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+// GetByProject returns a page of activity of the project, newest first, and
+// the total number of its records
+func (store *activityStorage) GetByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]model.ActivityDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		res   []model.ActivityDTO
+		total int
+	)
+
+	if err = t.QueryRow(ctx, store.pool, queryCountActivity, projectID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error while counting activity: %w", err)
+	}
+
+	rows, err := t.Query(ctx, store.pool, queryGetActivity, projectID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while querying activity: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a model.ActivityDTO
+		err = rows.Scan(&a.ID, &a.ProjectID, &a.ActorID, &a.Entity, &a.EntityID, &a.Action, &a.Changes, &a.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error while scanning activity: %w", err)
+		}
+		res = append(res, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, total, nil
+}
+
+// GetSince returns up to limit records of the project added after the record
+// afterID, oldest first
+func (store *activityStorage) GetSince(ctx context.Context, projectID uuid.UUID, afterID int64, limit int) ([]model.ActivityDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ActivityDTO
+
+	rows, err := t.Query(ctx, store.pool, queryGetActivitySince, projectID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying activity: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a model.ActivityDTO
+		err = rows.Scan(&a.ID, &a.ProjectID, &a.ActorID, &a.Entity, &a.EntityID, &a.Action, &a.Changes, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning activity: %w", err)
+		}
+		res = append(res, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// LastID returns the id of the newest record of the project, zero when it
+// has none
+func (store *activityStorage) LastID(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if err = t.QueryRow(ctx, store.pool, queryGetLastActivityID, projectID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error while getting last activity: %w", err)
+	}
+	return id, nil
+}
+
+// logActivity records a change of an entity of the project in tx. before is
+// nil for created entities and after is nil for deleted ones. The actor is
+// taken from ctx, see storage.WithActor. Updates that change nothing are not
+// recorded. The record is also queued for the webhooks of the project and
+// written to the outbox as a domain event
+func logActivity(ctx context.Context, tx pgx.Tx, projectID uuid.UUID, entity string, entityID uuid.UUID, action string, before, after any) error {
+	changes, err := storage.DiffSnapshots(before, after)
+	if err != nil {
+		return fmt.Errorf("error while diffing %s: %w", entity, err)
+	}
+	if len(changes) == 0 && action == storage.ActionUpdate {
+		return nil
+	}
+
+	var actorID *uuid.UUID
+	if id, ok := storage.ActorFromContext(ctx); ok {
+		actorID = &id
+	}
+
+	if _, err = tx.Exec(ctx, queryLockProjectActivity, projectID); err != nil {
+		return fmt.Errorf("error while locking activity: %w", err)
+	}
+	activity := model.ActivityDTO{
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+	}
+	err = tx.QueryRow(ctx, queryAddActivity, projectID, actorID, entity, entityID, action, changes).Scan(&activity.ID, &activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error while logging activity: %w", err)
+	}
+	if err = enqueueWebhooks(ctx, tx, &activity); err != nil {
+		return err
+	}
+	if err = addDomainEvent(ctx, tx, &activity, after); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, queryNotifyActivity, projectID.String()); err != nil {
+		return fmt.Errorf("error while notifying activity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Checking whether the interface "AttachmentStorage" implements the structure "attachmentStorage"
+var _ storage.AttachmentStorage = (*attachmentStorage)(nil)
+
+// attachmentStorage keeps metadata of files attached to todos, the files
+// themselves are kept by a blob.BlobStore
+type attachmentStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newAttachmentStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*attachmentStorage, error) {
+	store := &attachmentStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *attachmentStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateAttachments)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+// Create adds the attachment to a todo of the organization of ctx
+func (store *attachmentStorage) Create(ctx context.Context, attachment *model.AttachmentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = t.QueryRow(ctx, store.pool, queryCreateAttachment,
+		attachment.ID,
+		attachment.TodoID,
+		attachment.UploadedBy,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+	).Scan(&attachment.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.ForeignKeyViolation == store.pgErr.Code {
+			return errors2.ErrNotFound
+		}
+		return errors2.ErrInserting
+	}
+	return nil
+}
+
+func (store *attachmentStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := scanAttachment(t.QueryRow(ctx, store.pool, queryGetAttachmentByID, id, todoID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+func (store *attachmentStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.AttachmentDTO
+
+	rows, err := t.Query(ctx, store.pool, queryGetAttachments, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying attachments: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		temp, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning attachments: %w", err)
+		}
+		res = append(res, *temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+func (store *attachmentStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := t.Exec(ctx, store.pool, queryDeleteAttachment, id, todoID)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errors2.ErrNotFound
+	}
+	return nil
+}
+
+// Purge deletes the attachments of the todos and projects trashed before
+// deletedBefore and returns the keys of their blobs. Rows and keys go in one
+// statement, so a todo restored meanwhile keeps both its rows and blobs
+func (store *attachmentStorage) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	rows, err := store.pool.Query(ctx, queryPurgeAttachments, deletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error while purging attachments: %w", err)
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error while purging attachments: %w", err)
+	}
+	return keys, nil
+}
+
+func scanAttachment(row pgx.Row) (*model.AttachmentDTO, error) {
+	var a model.AttachmentDTO
+	err := row.Scan(&a.ID, &a.TodoID, &a.UploadedBy, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,161 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/rank"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Checking whether the interface "ChecklistStorage" implements the structure "checklistStorage"
+var _ storage.ChecklistStorage = (*checklistStorage)(nil)
+
+// checklistStorage keeps checklist items of todos. Items are deleted
+// together with their todo
+type checklistStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newChecklistStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*checklistStorage, error) {
+	store := &checklistStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *checklistStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateChecklist)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+// Create appends the item to the end of the checklist of its todo
+func (store *checklistStorage) Create(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// locking the todo serializes concurrent appends to its checklist
+	err = t.QueryRow(ctx, tx, queryLockTodo, item.TodoID).Scan(new(uuid.UUID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while locking todo: %w", err)
+	}
+
+	var last string
+	if err = tx.QueryRow(ctx, queryGetLastItemPosition, item.TodoID).Scan(&last); err != nil {
+		return errors2.ErrInserting
+	}
+	item.Position = rank.After(last)
+
+	_, err = tx.Exec(ctx, queryCreateItem, item.ID, item.TodoID, item.Text, item.IsDone, item.Position)
+	if err != nil {
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *checklistStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item := new(model.ChecklistItemDTO)
+	err = t.QueryRow(ctx, store.pool, queryGetItemByID, id, todoID).Scan(&item.ID, &item.TodoID, &item.Text, &item.IsDone, &item.Position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting checklist item: %w", err)
+	}
+	return item, nil
+}
+
+func (store *checklistStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ChecklistItemDTO
+
+	rows, err := t.Query(ctx, store.pool, queryGetAllItems, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying checklist items: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.ChecklistItemDTO
+		err = rows.Scan(&temp.ID, &temp.TodoID, &temp.Text, &temp.IsDone, &temp.Position)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning checklist items: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+func (store *checklistStorage) Update(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := t.Exec(ctx, store.pool, queryUpdateItem, item.Text, item.IsDone, item.ID, item.TodoID)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errors2.ErrNotFound
+	}
+	return nil
+}
+
+func (store *checklistStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := t.Exec(ctx, store.pool, queryDeleteItem, id, todoID)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errors2.ErrNotFound
+	}
+	return nil
+}
@@ -2,12 +2,14 @@ package pgx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/todo-enjoers/backend_v1/internal/model"
-	"github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
 	"go.uber.org/zap"
 )
@@ -16,12 +18,14 @@ import (
 var _ storage.ColumnStorage = (*columnStorage)(nil)
 
 type columnStorage struct {
-	pool  *pgxpool.Pool
+	pool  db
 	log   *zap.Logger
 	pgErr *pgconn.PgError
+	// todos moves or deletes the todos of a deleted column
+	todos *todoStorage
 }
 
-func newColumnStorage(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError) (*columnStorage, error) {
+func newColumnStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*columnStorage, error) {
 	store := &columnStorage{
 		pool:  pool,
 		log:   log,
@@ -36,48 +40,328 @@ func newColumnStorage(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError
 func (store *columnStorage) migrate() (err error) {
 	_, err = store.pool.Exec(context.Background(), queryMigrateColumnsTable)
 	if err != nil {
-		return errors.ErrTableMigrations
+		return errors2.ErrTableMigrations
 	}
 	return nil
 }
 
+// CreateColumn inserts the column at column.Order, shifting the following
+// columns to the right. An order past the end appends the column
 func (store *columnStorage) CreateColumn(ctx context.Context, column *model.ColumDTO) error {
-	_, err := store.pool.Exec(ctx, queryInsertColumns, column.ProjectId, column.Name, column.Order)
-	return err
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	names, err := store.lockProjectColumns(ctx, t, tx, column.ProjectId)
+	if err != nil {
+		return err
+	}
+	if column.Order < 0 || column.Order > len(names) {
+		column.Order = len(names)
+	}
+
+	if _, err = tx.Exec(ctx, queryShiftColumnsRight, column.ProjectId, column.Order); err != nil {
+		return fmt.Errorf("error while shifting columns: %w", err)
+	}
+	if column.ID == uuid.Nil {
+		column.ID = uuid.New()
+	}
+	if _, err = tx.Exec(ctx, queryInsertColumns, column.ID, column.ProjectId, column.Name, column.Order, column.WipLimit, column.IsDone); err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	if err = logActivity(ctx, tx, column.ProjectId, storage.EntityColumn, column.ID, storage.ActionCreate, nil, column); err != nil {
+		return err
+	}
+	return store.commit(ctx, tx)
+}
+
+// DeleteColumn removes the column and closes the gap it leaves in the order.
+// Todos of the column are appended to the moveTo column when it is set, or
+// moved to the trash one by one when cascade is true. Otherwise a column that
+// still has todos is not deleted and ErrColumnNotEmpty is returned. Archived
+// and trashed todos are detached from the column, they go back to a column of
+// the project when they are unarchived or restored
+func (store *columnStorage) DeleteColumn(ctx context.Context, name string, projectId uuid.UUID, moveTo string, cascade bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	names, err := store.lockProjectColumns(ctx, t, tx, projectId)
+	if err != nil {
+		return err
+	}
+
+	column, err := lockColumn(ctx, t, tx, name, projectId)
+	if err != nil {
+		return err
+	}
+	if expected := storage.ExpectedVersionFromContext(ctx); expected != 0 && expected != column.Version {
+		return errors2.ErrVersionMismatch
+	}
+	// the expected version is the one of the column, not of its todos
+	todoCtx := storage.WithExpectedVersion(ctx, 0)
+
+	// the column leaves the order before its todos move, so that occurrences
+	// of recurring todos completed by the move are spawned in the new first
+	// column
+	if _, err = tx.Exec(ctx, queryMoveColumnLast, column.ID, len(names)); err != nil {
+		return fmt.Errorf("error while shifting columns: %w", err)
+	}
+	if _, err = tx.Exec(ctx, queryShiftColumnsLeft, projectId, column.Order); err != nil {
+		return fmt.Errorf("error while shifting columns: %w", err)
+	}
+
+	switch {
+	case moveTo != "":
+		target, err := lockColumn(ctx, t, tx, moveTo, projectId)
+		if errors.Is(err, errors2.ErrNotFound) || (err == nil && target.ID == column.ID) {
+			return errors2.ErrColumnTarget
+		}
+		if err != nil {
+			return err
+		}
+		if err = store.moveTodos(todoCtx, t, tx, column, target); err != nil {
+			return err
+		}
+	case cascade:
+		ids, err := columnTodos(ctx, tx, column.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err = store.todos.delete(todoCtx, t, tx, id); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err = tx.Exec(ctx, queryDetachColumnTodos, column.ID, column.Name); err != nil {
+		return fmt.Errorf("error while detaching column todos: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, queryDeleteColumns, column.ID); err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.ForeignKeyViolation == store.pgErr.Code {
+			return errors2.ErrColumnNotEmpty
+		}
+		return err
+	}
+	if err = logActivity(ctx, tx, projectId, storage.EntityColumn, column.ID, storage.ActionDelete, column, nil); err != nil {
+		return err
+	}
+	return store.commit(ctx, tx)
+}
+
+// moveTodos appends the live todos of the column to the end of the target
+// column one by one, keeping their relative order. Like Move they are logged
+// and completed when they cross the done boundary, but the WIP limit of the
+// target and open blockers are not checked, deleting a column must not be
+// blocked by them
+func (store *columnStorage) moveTodos(ctx context.Context, t tenant, tx pgx.Tx, column, target *model.ColumDTO) error {
+	ids, err := columnTodos(ctx, tx, column.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		move := &model.TodoMoveDTO{Column: target.Name, Force: true}
+		if _, err = store.todos.move(ctx, t, tx, id, move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnTodos returns the live todos of the column in their order
+func columnTodos(ctx context.Context, tx pgx.Tx, columnID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, queryGetColumnTodos, columnID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying column todos: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("error while scanning column todos: %w", err)
+	}
+	return ids, nil
+}
+
+// ReorderColumns rewrites the order of all columns of the project at once.
+// names must list every column of the project exactly once
+func (store *columnStorage) ReorderColumns(ctx context.Context, projectId uuid.UUID, names []string) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := store.lockProjectColumns(ctx, t, tx, projectId)
+	if err != nil {
+		return err
+	}
+	if !sameColumnSet(current, names) {
+		return errors2.ErrColumnsMismatch
+	}
+
+	if _, err = tx.Exec(ctx, queryReorderColumns, projectId, names); err != nil {
+		return fmt.Errorf("error while reordering columns: %w", err)
+	}
+
+	type columnOrder struct {
+		Columns []string `json:"columns"`
+	}
+	err = logActivity(ctx, tx, projectId, storage.EntityProject, projectId, storage.ActionReorder, columnOrder{current}, columnOrder{names})
+	if err != nil {
+		return err
+	}
+	return store.commit(ctx, tx)
+}
+
+// lockProjectColumns locks every column of the project so that concurrent
+// reorders can not interleave, and returns their names in current order.
+// Columns of deleted projects and of other organizations can not be changed,
+// ErrNotFound is returned
+func (store *columnStorage) lockProjectColumns(ctx context.Context, t tenant, tx pgx.Tx, projectId uuid.UUID) ([]string, error) {
+	var active bool
+	if err := t.QueryRow(ctx, tx, queryProjectIsActive, projectId).Scan(&active); err != nil {
+		return nil, fmt.Errorf("error while checking project: %w", err)
+	}
+	if !active {
+		return nil, errors2.ErrNotFound
+	}
+
+	rows, err := tx.Query(ctx, queryLockProjectColumns, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error while locking columns: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error while scanning columns: %w", err)
+	}
+	return names, nil
 }
-func (store *columnStorage) DeleteColumn(ctx context.Context, name string, projectId uuid.UUID) error {
-	_, err := store.pool.Exec(ctx, queryDeleteColumns, name, projectId)
-	return err
+
+// commit maps the deferred order constraint violation to ErrAlreadyExists
+func (store *columnStorage) commit(ctx context.Context, tx pgx.Tx) error {
+	err := tx.Commit(ctx)
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return fmt.Errorf("error while committing: %w", err)
+	}
+	return nil
+}
+
+func sameColumnSet(current, names []string) bool {
+	if len(current) != len(names) {
+		return false
+	}
+	seen := make(map[string]bool, len(current))
+	for _, name := range current {
+		seen[name] = true
+	}
+	for _, name := range names {
+		if !seen[name] {
+			return false
+		}
+		delete(seen, name)
+	}
+	return true
 }
 
 func (store *columnStorage) GetColumnByName(ctx context.Context, name string, projectId uuid.UUID) (*model.ColumDTO, error) {
-	var column model.ColumDTO
-	err := store.pool.QueryRow(ctx, queryGetColumnByName, name, projectId).Scan(&column.ProjectId, &column.Name, &column.Order)
+	t, err := tenantOf(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &column, nil
+
+	column, err := scanColumn(t.QueryRow(ctx, store.pool, queryGetColumnByName, name, projectId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return column, nil
 }
+
+// UpdateColumn renames the column. Todos reference columns by id, so they
+// follow the rename without being touched
 func (store *columnStorage) UpdateColumn(ctx context.Context, column *model.ColumDTO, name string, projectId uuid.UUID) error {
-	_, err := store.pool.Exec(ctx, queryUpdateColumns, column.Name, name, projectId)
-	return err
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockColumn(ctx, t, tx, name, projectId)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, queryUpdateColumns, column.Name, column.WipLimit, column.IsDone, name, projectId, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return err
+	}
+	if err = checkVersion(tag); err != nil {
+		return err
+	}
+
+	after := *before
+	after.Name, after.WipLimit, after.IsDone = column.Name, column.WipLimit, column.IsDone
+	if err = logActivity(ctx, tx, projectId, storage.EntityColumn, before.ID, storage.ActionUpdate, before, &after); err != nil {
+		return err
+	}
+	return store.commit(ctx, tx)
 }
+
 func (store *columnStorage) GetAllColumns(ctx context.Context, projectId uuid.UUID) ([]model.ColumDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var res []model.ColumDTO
 
-	rows, err := store.pool.Query(ctx, queryGetAllColumns, projectId)
+	rows, err := t.Query(ctx, readDB(ctx, store.pool), queryGetAllColumns, projectId)
 	if err != nil {
-		return nil, fmt.Errorf("error while querying all todos: %w", err)
+		return nil, fmt.Errorf("error while querying all columns: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var temp model.ColumDTO
-		err = rows.Scan(&temp.ProjectId, &temp.Name, &temp.Order)
+		temp, err := scanColumn(rows)
 		if err != nil {
-			return nil, fmt.Errorf("error while scanning todos: %w", err)
+			return nil, fmt.Errorf("error while scanning columns: %w", err)
 		}
-		res = append(res, temp)
+		res = append(res, *temp)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -86,3 +370,28 @@ func (store *columnStorage) GetAllColumns(ctx context.Context, projectId uuid.UU
 
 	return res, nil
 }
+
+// lockColumn locks the column of a project of the organization of t for the
+// rest of the transaction, so that its todos and WIP limit can be checked
+// without races
+func lockColumn(ctx context.Context, t tenant, tx pgx.Tx, name string, projectId uuid.UUID) (*model.ColumDTO, error) {
+	column, err := scanColumn(t.QueryRow(ctx, tx, queryLockColumn, name, projectId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking column: %w", err)
+	}
+	return column, nil
+}
+
+// scanColumn scans a row selected by queryGetColumnByName, queryLockColumn or
+// queryGetAllColumns
+func scanColumn(row pgx.Row) (*model.ColumDTO, error) {
+	var column model.ColumDTO
+	err := row.Scan(&column.ID, &column.ProjectId, &column.Name, &column.Order, &column.WipLimit, &column.IsDone, &column.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
@@ -0,0 +1,57 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/todo-enjoers/backend_v1/internal/events"
+	"go.uber.org/zap"
+)
+
+// projectEventsChannel is notified with the project id by every transaction
+// that adds project activity, see logActivity
+const projectEventsChannel = "project_events"
+
+// Checking whether the interface "events.Notifier" implements the structure "Notifier"
+var _ events.Notifier = (*Notifier)(nil)
+
+// Notifier listens for project activity with LISTEN on a connection taken
+// from the pool for as long as it listens
+type Notifier struct {
+	pool *pgxpool.Pool
+	log  *zap.Logger
+}
+
+func NewNotifier(pool *pgxpool.Pool, log *zap.Logger) *Notifier {
+	return &Notifier{
+		pool: pool,
+		log:  log,
+	}
+}
+
+func (n *Notifier) Listen(ctx context.Context, notify func(projectID uuid.UUID)) error {
+	conn, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error while acquiring connection: %w", err)
+	}
+	// the connection stays in LISTEN state, it must not go back to the pool
+	listener := conn.Hijack()
+	defer listener.Close(context.Background())
+
+	if _, err = listener.Exec(ctx, "LISTEN "+projectEventsChannel); err != nil {
+		return fmt.Errorf("error while listening: %w", err)
+	}
+	for {
+		notification, err := listener.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("error while waiting for notification: %w", err)
+		}
+		projectID, err := uuid.Parse(notification.Payload)
+		if err != nil {
+			n.log.Error("bad project event payload", zap.String("payload", notification.Payload))
+			continue
+		}
+		notify(projectID)
+	}
+}
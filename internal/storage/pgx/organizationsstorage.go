@@ -0,0 +1,269 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Checking whether the interface "OrganizationStorage" implements the structure "organizationStorage"
+var _ storage.OrganizationStorage = (*organizationStorage)(nil)
+
+type organizationStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newOrganizationStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*organizationStorage, error) {
+	store := &organizationStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *organizationStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateOrganizations)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+// Create creates the organization owned by the user that created it
+func (store *organizationStorage) Create(ctx context.Context, org *model.OrganizationDTO) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = createOrganization(ctx, tx, org); err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *organizationStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.OrganizationDTO, error) {
+	org := new(model.OrganizationDTO)
+	err := readDB(ctx, store.pool).QueryRow(ctx, queryGetOrganization, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting organization: %w", err)
+	}
+	return org, nil
+}
+
+// GetByMember returns the organizations of the user with their role in each
+func (store *organizationStorage) GetByMember(ctx context.Context, userID uuid.UUID) ([]model.OrganizationDTO, error) {
+	var res []model.OrganizationDTO
+	rows, err := readDB(ctx, store.pool).Query(ctx, queryGetOrganizationsByMember, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying organizations: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.OrganizationDTO
+		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy, &temp.CreatedAt, &temp.Role)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning organizations: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// GetMember returns the membership of the user in the organization, it is
+// how the organization of a request is checked before it is set as the tenant
+func (store *organizationStorage) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*model.OrganizationMemberDTO, error) {
+	member := new(model.OrganizationMemberDTO)
+	err := store.pool.QueryRow(ctx, queryGetOrganizationMember, orgID, userID).Scan(&member.OrgID, &member.UserID, &member.Login, &member.Role, &member.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting member: %w", err)
+	}
+	return member, nil
+}
+
+// GetMembers returns the members of the organization of ctx ordered by login
+func (store *organizationStorage) GetMembers(ctx context.Context) ([]model.OrganizationMemberDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.OrganizationMemberDTO
+	rows, err := t.Query(ctx, readDB(ctx, store.pool), queryGetOrganizationMembers)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying members: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.OrganizationMemberDTO
+		err = rows.Scan(&temp.OrgID, &temp.UserID, &temp.Login, &temp.Role, &temp.JoinedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning members: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// AddMember adds the user to the organization of ctx
+func (store *organizationStorage) AddMember(ctx context.Context, member *model.OrganizationMemberDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = t.QueryRow(ctx, store.pool, queryAddOrganizationMember, member.UserID, member.Role).Scan(&member.JoinedAt)
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		if errors.As(err, &store.pgErr) && pgerrcode.ForeignKeyViolation == store.pgErr.Code {
+			return errors2.ErrNotFound
+		}
+		return errors2.ErrInserting
+	}
+	member.OrgID = t.orgID
+	return nil
+}
+
+// UpdateMemberRole changes the role of the member of the organization of ctx.
+// The organization keeps at least one owner, and the owner of a personal
+// organization stays its owner
+func (store *organizationStorage) UpdateMemberRole(ctx context.Context, userID uuid.UUID, role string) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMember(ctx, t, tx, userID)
+	if err != nil {
+		return err
+	}
+	if current == model.OrgRoleOwner && role != model.OrgRoleOwner {
+		if err = leaveOwners(ctx, t, tx, userID); err != nil {
+			return err
+		}
+	}
+	if _, err = t.Exec(ctx, tx, queryUpdateOrganizationMemberRole, userID, role); err != nil {
+		return fmt.Errorf("error while updating member: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveMember removes the user from the organization of ctx. The last owner
+// and the owner of a personal organization can't be removed
+func (store *organizationStorage) RemoveMember(ctx context.Context, userID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMember(ctx, t, tx, userID)
+	if err != nil {
+		return err
+	}
+	if userID == t.orgID {
+		return errors2.ErrPersonalOwner
+	}
+	if current == model.OrgRoleOwner {
+		if err = leaveOwners(ctx, t, tx, userID); err != nil {
+			return err
+		}
+	}
+	if _, err = t.Exec(ctx, tx, queryRemoveOrganizationMember, userID); err != nil {
+		return fmt.Errorf("error while removing member: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// createOrganization inserts the organization and makes its creator the
+// owner. The personal organization of a user is created with it
+func createOrganization(ctx context.Context, tx pgx.Tx, org *model.OrganizationDTO) error {
+	if err := tx.QueryRow(ctx, queryCreateOrganization, org.ID, org.Name, org.CreatedBy).Scan(&org.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, queryAddOrganizationOwner, org.ID, org.CreatedBy); err != nil {
+		return err
+	}
+	org.Role = model.OrgRoleOwner
+	return nil
+}
+
+// lockMember locks the membership of the user in the organization of t and
+// returns the role
+func lockMember(ctx context.Context, t tenant, tx pgx.Tx, userID uuid.UUID) (string, error) {
+	var role string
+	err := t.QueryRow(ctx, tx, queryLockOrganizationMember, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errors2.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error while locking member: %w", err)
+	}
+	return role, nil
+}
+
+// leaveOwners checks that the owner may stop owning the organization of t
+func leaveOwners(ctx context.Context, t tenant, tx pgx.Tx, userID uuid.UUID) error {
+	if userID == t.orgID {
+		return errors2.ErrPersonalOwner
+	}
+	var owners int
+	if err := t.QueryRow(ctx, tx, queryCountOrganizationOwners).Scan(&owners); err != nil {
+		return fmt.Errorf("error while counting owners: %w", err)
+	}
+	if owners <= 1 {
+		return errors2.ErrLastOwner
+	}
+	return nil
+}
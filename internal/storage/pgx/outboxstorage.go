@@ -0,0 +1,150 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Checking whether the interface "OutboxStorage" implements the structure "outboxStorage"
+var _ storage.OutboxStorage = (*outboxStorage)(nil)
+
+type outboxStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newOutboxStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*outboxStorage, error) {
+	store := &outboxStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *outboxStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateOutbox)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+// Publish hands up to limit unpublished events, oldest first, to publish and
+// marks them published when it succeeds. Only one relay publishes at a time,
+// it returns zero when another one is busy or when the oldest event waits
+// for its next attempt, so that events are never published out of order
+func (store *outboxStorage) Publish(ctx context.Context, limit int, publish func(ctx context.Context, events []model.DomainEventDTO) error) (int, error) {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err = tx.QueryRow(ctx, queryLockOutboxRelay).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("error while locking outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	events, err := getUnpublishedEvents(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 || events[0].NextAttemptAt.After(time.Now()) {
+		return 0, nil
+	}
+
+	if err = publish(ctx, events); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	if _, err = tx.Exec(ctx, queryMarkEventsPublished, ids); err != nil {
+		return 0, fmt.Errorf("error while marking events published: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// Fail records a failed attempt to publish the event, the relay tries again
+// from it at nextAttemptAt
+func (store *outboxStorage) Fail(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	if _, err := store.pool.Exec(ctx, queryFailOutboxEvent, id, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("error while recording outbox failure: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the events published before publishedBefore and returns how
+// many were removed
+func (store *outboxStorage) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	commandTag, err := store.pool.Exec(ctx, queryPurgeOutboxEvents, publishedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging outbox: %w", err)
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+func getUnpublishedEvents(ctx context.Context, tx pgx.Tx, limit int) ([]model.DomainEventDTO, error) {
+	var res []model.DomainEventDTO
+
+	rows, err := tx.Query(ctx, queryGetUnpublishedEvents, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying outbox: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.DomainEventDTO
+		err = rows.Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.ProjectID, &e.ActorID,
+			&e.Changes, &e.State, &e.OccurredAt, &e.Attempts, &e.NextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning outbox: %w", err)
+		}
+		res = append(res, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// addDomainEvent writes the domain event of the activity record to the
+// outbox in tx. after is the state of the entity after the change
+func addDomainEvent(ctx context.Context, tx pgx.Tx, activity *model.ActivityDTO, after any) error {
+	state, err := storage.Snapshot(after)
+	if err != nil {
+		return fmt.Errorf("error while encoding %s: %w", activity.Entity, err)
+	}
+	_, err = tx.Exec(ctx, queryAddOutboxEvent,
+		model.DomainEventType(activity.Entity, activity.Action, activity.Changes),
+		activity.Entity, activity.EntityID, activity.ProjectID, activity.ActorID,
+		activity.Changes, state, activity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while writing domain event: %w", err)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package pgx
 
 import (
+	"time"
+
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
 )
 
@@ -12,43 +15,130 @@ import (
 var _ storage.Interface = (*Storage)(nil)
 
 type Storage struct {
-	pool    *pgxpool.Pool
-	log     *zap.Logger
-	user    *userStorage
-	project *projectsStorage
-	todo    *todoStorage
-	column  *columnStorage
-	pgErr   *pgconn.PgError
+	pool        *pgxpool.Pool
+	router      *router
+	log         *zap.Logger
+	user        *userStorage
+	org         *organizationStorage
+	project     *projectsStorage
+	todo        *todoStorage
+	checklist   *checklistStorage
+	comment     *commentStorage
+	attachment  *attachmentStorage
+	column      *columnStorage
+	template    *templateStorage
+	activity    *activityStorage
+	idempotency *idempotencyStorage
+	webhook     *webhookStorage
+	outbox      *outboxStorage
+	pgErr       *pgconn.PgError
+	// tx is the transaction of WithTx, nil outside of it
+	tx *txDB
 }
 
 func New(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError) (*Storage, error) {
-	users, err := newUserStorage(pool, log, pgErr)
+	return NewWithReplicas(pool, nil, 0, recurrence.SystemClock{}, log, pgErr)
+}
+
+// NewWithReplicas returns a storage that writes to pool and runs the
+// read-only methods, e.g. GetByID and GetAll, on the replicas. A user reads
+// from pool for sticky after their last write. Occurrences of recurring todos
+// are spawned at the time of clock
+func NewWithReplicas(
+	pool *pgxpool.Pool,
+	replicas []*pgxpool.Pool,
+	sticky time.Duration,
+	clock recurrence.Clock,
+	log *zap.Logger,
+	pgErr *pgconn.PgError,
+) (*Storage, error) {
+	router := newRouter(pool, replicas, sticky)
+
+	users, err := newUserStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	// projects belong to organizations, so organizations must be migrated first
+	orgs, err := newOrganizationStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := newProjectsStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	// todos reference columns, so columns must be migrated first
+	columns, err := newColumnStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := newTodoStorage(router, log, pgErr, clock)
+	if err != nil {
+		return nil, err
+	}
+	columns.todos = todos
+
+	checklist, err := newChecklistStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := newCommentStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := newAttachmentStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := newTemplateStorage(router, log, pgErr)
 	if err != nil {
 		return nil, err
 	}
 
-	projects, err := newProjectsStorage(pool, log, pgErr)
+	activity, err := newActivityStorage(router, log, pgErr)
 	if err != nil {
 		return nil, err
 	}
 
-	todos, err := newTodoStorage(pool, log, pgErr)
+	idempotency, err := newIdempotencyStorage(router, log, pgErr)
 	if err != nil {
 		return nil, err
 	}
 
-	columns, err := newColumnStorage(pool, log, pgErr)
+	webhooks, err := newWebhookStorage(router, log, pgErr)
+	if err != nil {
+		return nil, err
+	}
+
+	outbox, err := newOutboxStorage(router, log, pgErr)
 	if err != nil {
 		return nil, err
 	}
 
 	store := &Storage{
-		pool:    pool,
-		log:     log,
-		user:    users,
-		project: projects,
-		todo:    todos,
-		column:  columns,
+		pool:        pool,
+		router:      router,
+		log:         log,
+		user:        users,
+		org:         orgs,
+		project:     projects,
+		todo:        todos,
+		checklist:   checklist,
+		comment:     comments,
+		attachment:  attachments,
+		column:      columns,
+		template:    templates,
+		activity:    activity,
+		idempotency: idempotency,
+		webhook:     webhooks,
+		outbox:      outbox,
 	}
 
 	return store, nil
@@ -58,10 +148,26 @@ func (s *Storage) User() storage.UserStorage {
 	return s.user
 }
 
+func (s *Storage) Organization() storage.OrganizationStorage {
+	return s.org
+}
+
 func (s *Storage) Todo() storage.TodoStorage {
 	return s.todo
 }
 
+func (s *Storage) Checklist() storage.ChecklistStorage {
+	return s.checklist
+}
+
+func (s *Storage) Comment() storage.CommentStorage {
+	return s.comment
+}
+
+func (s *Storage) Attachment() storage.AttachmentStorage {
+	return s.attachment
+}
+
 func (s *Storage) Project() storage.ProjectStorage {
 	return s.project
 }
@@ -69,3 +175,23 @@ func (s *Storage) Project() storage.ProjectStorage {
 func (s *Storage) Column() storage.ColumnStorage {
 	return s.column
 }
+
+func (s *Storage) Template() storage.TemplateStorage {
+	return s.template
+}
+
+func (s *Storage) Activity() storage.ActivityStorage {
+	return s.activity
+}
+
+func (s *Storage) Idempotency() storage.IdempotencyStorage {
+	return s.idempotency
+}
+
+func (s *Storage) Webhook() storage.WebhookStorage {
+	return s.webhook
+}
+
+func (s *Storage) Outbox() storage.OutboxStorage {
+	return s.outbox
+}
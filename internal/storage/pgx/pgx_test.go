@@ -0,0 +1,56 @@
+package pgx
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/storagetest"
+)
+
+// envDSN names the variable with the DSN of a Postgres database the tests
+// may write to, the tests are skipped without it
+const envDSN = "TEST_POSTGRES_DSN"
+
+func newTestStore(t *testing.T) *Storage {
+	t.Helper()
+	return newTestStoreWithClock(t, recurrence.SystemClock{})
+}
+
+func newTestStoreWithClock(t *testing.T, clock recurrence.Clock) *Storage {
+	t.Helper()
+	dsn := os.Getenv(envDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envDSN)
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	store, err := NewWithReplicas(pool, nil, 0, clock, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// TestStorage shares one database between the checks, they create entities
+// of their own
+func TestStorage(t *testing.T) {
+	store := newTestStore(t)
+	storagetest.Run(t, func(*testing.T) storage.Interface {
+		return store
+	})
+}
+
+func TestSpawnNextClock(t *testing.T) {
+	storagetest.RunClock(t, func(t *testing.T, clock recurrence.Clock) storage.Interface {
+		return newTestStoreWithClock(t, clock)
+	})
+}
@@ -6,24 +6,25 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/todo-enjoers/backend_v1/internal/model"
 	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
 	"go.uber.org/zap"
+	"time"
 )
 
 // Checking whether the interface "ProjectStorage" implements the structure "projectsStorage"
 var _ storage.ProjectStorage = (*projectsStorage)(nil)
 
 type projectsStorage struct {
-	pool  *pgxpool.Pool
+	pool  db
 	log   *zap.Logger
 	pgErr *pgconn.PgError
 }
 
-func newProjectsStorage(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError) (*projectsStorage, error) {
+func newProjectsStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*projectsStorage, error) {
 	store := &projectsStorage{
 		pool:  pool,
 		log:   log,
@@ -43,20 +44,54 @@ func (store *projectsStorage) migrate() (err error) {
 	return nil
 }
 
-func (store *projectsStorage) Create(ctx context.Context, project *model.ProjectDTO) error {
-	_, err := store.pool.Exec(ctx, queryCreateProjects, project.ID, project.Name, project.CreatedBy)
+// Create inserts the project of the organization of ctx together with its
+// initial columns, so that a project is never left half-initialized
+func (store *projectsStorage) Create(ctx context.Context, project *model.ProjectDTO, columns []model.ColumDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = t.Exec(ctx, tx, queryCreateProjects, project.ID, project.Name, project.CreatedBy)
 	if err != nil {
 		if errors.As(err, &store.pgErr) && (pgerrcode.UniqueViolation == store.pgErr.Code) {
 			return errors2.ErrAlreadyExists
 		}
 		return errors2.ErrInserting
 	}
-	return nil
+	project.OrgID = t.orgID
+
+	if err = logActivity(ctx, tx, project.ID, storage.EntityProject, project.ID, storage.ActionCreate, nil, project); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		_, err = tx.Exec(ctx, queryInsertColumns, column.ID, project.ID, column.Name, column.Order, column.WipLimit, column.IsDone)
+		if err != nil {
+			return errors2.ErrInserting
+		}
+		if err = logActivity(ctx, tx, project.ID, storage.EntityColumn, column.ID, storage.ActionCreate, nil, column); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (store *projectsStorage) GetMyByName(ctx context.Context, name string, createdBy uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
 	project := new(model.ProjectDTO)
-	err := store.pool.QueryRow(ctx, queryGetMyProjectsByName, name, createdBy).Scan(&project.ID, &project.Name, &project.CreatedBy)
+	err = t.QueryRow(ctx, store.pool, queryGetMyProjectsByName, name, createdBy).Scan(&project.ID, &project.Name, &project.CreatedBy)
 	if err != nil {
 		return err
 	}
@@ -64,26 +99,41 @@ func (store *projectsStorage) GetMyByName(ctx context.Context, name string, crea
 }
 
 func (store *projectsStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	project := new(model.ProjectDTO)
-	err := store.pool.QueryRow(ctx, queryGetProjectsByID, id).Scan(&project.ID, &project.Name, &project.CreatedBy)
+	err = t.QueryRow(ctx, readDB(ctx, store.pool), queryGetProjectsByID, id).Scan(&project.ID, &project.Name, &project.CreatedBy, &project.OrgID, &project.ArchivedAt, &project.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return project, nil
 }
 
-func (store *projectsStorage) GetMyProjects(ctx context.Context, createdByID uuid.UUID) ([]model.ProjectDTO, error) {
+// GetAll returns projects of the organization of ctx, archived ones only
+// when includeArchived is set
+func (store *projectsStorage) GetAll(ctx context.Context, includeArchived bool) ([]model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var projectsList []model.ProjectDTO
-	rows, err := store.pool.Query(ctx, queryGetMyProjects, createdByID)
+	rows, err := t.Query(ctx, readDB(ctx, store.pool), queryGetAllProjects, includeArchived)
 	if err != nil {
-		return nil, fmt.Errorf("error while querying my projects: %w", err)
+		return nil, fmt.Errorf("error while querying projects: %w", err)
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
 		var temp model.ProjectDTO
-		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy)
+		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy, &temp.OrgID, &temp.ArchivedAt, &temp.Version)
 		if err != nil {
 			return nil, fmt.Errorf("error while scanning groups: %w", err)
 		}
@@ -98,23 +148,199 @@ func (store *projectsStorage) GetMyProjects(ctx context.Context, createdByID uui
 }
 
 func (store *projectsStorage) UpdateName(ctx context.Context, name string, id uuid.UUID) error {
-	commandTag, err := store.pool.Exec(ctx, queryUpdateProjectName, name, id)
+	t, err := tenantOf(ctx)
 	if err != nil {
 		return err
 	}
-	if commandTag.RowsAffected() == 0 {
-		return errors2.ErrNotFound
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
 	}
-	return nil
+	defer tx.Rollback(ctx)
+
+	before, err := lockProject(ctx, t, tx, id)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, queryUpdateProjectName, name, id, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err = checkVersion(tag); err != nil {
+		return err
+	}
+
+	after := *before
+	after.Name = name
+	if err = logActivity(ctx, tx, id, storage.EntityProject, id, storage.ActionUpdate, before, &after); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// Archive archives or unarchives the project. Archived projects are left out
+// of GetAll by default but stay readable by id
+func (store *projectsStorage) Archive(ctx context.Context, id uuid.UUID, archived bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockProject(ctx, t, tx, id)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	if err = tx.QueryRow(ctx, queryArchiveProject, id, archived).Scan(&after.ArchivedAt); err != nil {
+		return fmt.Errorf("error while archiving project: %w", err)
+	}
+
+	action := storage.ActionArchive
+	if !archived {
+		action = storage.ActionUnarchive
+	}
+	if err = logActivity(ctx, tx, id, storage.EntityProject, id, action, before, &after); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete moves the project to the trash, its columns and todos are hidden
+// with it until it is restored or purged
 func (store *projectsStorage) Delete(ctx context.Context, id uuid.UUID) error {
-	commandTag, err := store.pool.Exec(ctx, queryDeleteProject, id)
+	t, err := tenantOf(ctx)
 	if err != nil {
 		return err
 	}
-	if commandTag.RowsAffected() == 0 {
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockProject(ctx, t, tx, id)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, queryDeleteProject, id, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err = checkVersion(tag); err != nil {
+		return err
+	}
+
+	if err = logActivity(ctx, tx, id, storage.EntityProject, id, storage.ActionDelete, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetTrash returns deleted projects of the user in the organization of ctx,
+// most recently deleted first
+func (store *projectsStorage) GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.ProjectDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectsList []model.ProjectDTO
+	rows, err := t.Query(ctx, store.pool, queryGetTrashedProjects, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying trashed projects: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.ProjectDTO
+		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy, &temp.OrgID, &temp.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning projects: %w", err)
+		}
+		projectsList = append(projectsList, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return projectsList, nil
+}
+
+// Restore takes the project out of the trash together with its columns and
+// the todos that were not deleted on their own
+func (store *projectsStorage) Restore(ctx context.Context, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before := new(model.ProjectDTO)
+	err = t.QueryRow(ctx, tx, queryLockTrashedProject, id).Scan(&before.ID, &before.Name, &before.CreatedBy, &before.OrgID, &before.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return errors2.ErrNotFound
 	}
-	return err
+	if err != nil {
+		return fmt.Errorf("error while locking project: %w", err)
+	}
+	if _, err = tx.Exec(ctx, queryRestoreProject, id); err != nil {
+		return err
+	}
+
+	after := *before
+	after.DeletedAt = nil
+	if err = logActivity(ctx, tx, id, storage.EntityProject, id, storage.ActionRestore, before, &after); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Purge deletes for good the projects that were deleted before
+// deletedBefore together with their activity and returns how many were
+// removed
+func (store *projectsStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, queryPurgeProjectActivity, deletedBefore); err != nil {
+		return 0, fmt.Errorf("error while purging activity of projects: %w", err)
+	}
+	commandTag, err := tx.Exec(ctx, queryPurgeProjects, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging projects: %w", err)
+	}
+	return commandTag.RowsAffected(), tx.Commit(ctx)
+}
+
+// lockProject locks the project row of the organization of t for the rest of
+// the transaction and returns it as it was before the change
+func lockProject(ctx context.Context, t tenant, tx pgx.Tx, id uuid.UUID) (*model.ProjectDTO, error) {
+	project := new(model.ProjectDTO)
+	err := t.QueryRow(ctx, tx, queryLockProject, id).Scan(&project.ID, &project.Name, &project.CreatedBy, &project.OrgID, &project.ArchivedAt, &project.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking project: %w", err)
+	}
+	return project, nil
 }
@@ -1,5 +1,91 @@
 package pgx
 
+// subqueries of the projects and todos of the tenant, for tenant queries of
+// rows that belong to them
+const (
+	tenantProjects = `SELECT id FROM projects WHERE org_id = $org`
+	tenantTodos    = `SELECT t.id FROM todos AS t JOIN projects AS p ON p.id = t.project_id WHERE p.org_id = $org`
+	tenantWebhooks = `SELECT w.id FROM webhooks AS w JOIN projects AS p ON p.id = w.project_id WHERE p.org_id = $org`
+)
+
+// query for Organizations Storage
+const (
+	queryMigrateOrganizations = `CREATE TABLE IF NOT EXISTS organizations
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "name" VARCHAR NOT NULL,
+    "created_by" UUID NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY ("created_by") REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS organization_members
+(
+    "org_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "role" VARCHAR NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    "joined_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, user_id),
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_id_index ON organization_members(user_id);
+
+-- every user owns a personal organization with the id of the user
+INSERT INTO organizations (id, name, created_by)
+SELECT id, login, id FROM users
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO organization_members (org_id, user_id, role)
+SELECT id, id, 'owner' FROM users
+ON CONFLICT (org_id, user_id) DO NOTHING;`
+
+	queryCreateOrganization = `INSERT INTO organizations (id, name, created_by) VALUES ($1, $2, $3)
+RETURNING created_at;`
+
+	queryAddOrganizationOwner = `INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, 'owner');`
+
+	queryGetOrganization = `SELECT id, name, created_by, created_at FROM organizations WHERE id = $1;`
+
+	queryGetOrganizationsByMember = `SELECT o.id, o.name, o.created_by, o.created_at, m.role
+FROM organizations AS o
+JOIN organization_members AS m ON m.org_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.name, o.id;`
+
+	organizationMemberSelect = `SELECT m.org_id, m.user_id, u.login, m.role, m.joined_at
+FROM organization_members AS m
+JOIN users AS u ON u.id = m.user_id
+`
+
+	queryGetOrganizationMember = organizationMemberSelect + `WHERE m.org_id = $1 AND m.user_id = $2;`
+
+	queryGetOrganizationMembers tenantQuery = organizationMemberSelect + `WHERE m.org_id = $org
+ORDER BY u.login;`
+
+	queryAddOrganizationMember tenantQuery = `INSERT INTO organization_members (org_id, user_id, role) VALUES ($org, $1, $2)
+RETURNING joined_at;`
+
+	queryLockOrganizationMember tenantQuery = `SELECT role FROM organization_members
+WHERE org_id = $org AND user_id = $1
+FOR UPDATE;`
+
+	// queryCountOrganizationOwners locks the owners, so that two owners can't
+	// leave at once
+	queryIsOrganizationMember tenantQuery = `SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $org AND user_id = $1);`
+
+	queryCountOrganizationOwners tenantQuery = `SELECT count(*) FROM (
+    SELECT 1 FROM organization_members WHERE org_id = $org AND role = 'owner' FOR UPDATE
+) AS owners;`
+
+	queryUpdateOrganizationMemberRole tenantQuery = `UPDATE organization_members SET role = $2
+WHERE org_id = $org AND user_id = $1;`
+
+	queryRemoveOrganizationMember tenantQuery = `DELETE FROM organization_members
+WHERE org_id = $org AND user_id = $1;`
+)
+
 // query for Projects Storage
 const (
 	queryMigrateP = `CREATE TABLE IF NOT EXISTS projects
@@ -9,51 +95,285 @@ const (
     "created_by" UUID NOT NULL ,
     PRIMARY KEY ("id"),
     FOREIGN KEY ("created_by") REFERENCES users(id) ON DELETE CASCADE
-);`
+);
 
-	queryCreateProjects = `INSERT INTO projects (id, name, created_by) VALUES ($1, $2, $3);`
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMPTZ;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS "archived_at" TIMESTAMPTZ;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+
+-- bump_version increments the version of every changed row of projects,
+-- project_columns and todos, it is the ETag of the row
+CREATE OR REPLACE FUNCTION bump_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS projects_bump_version ON projects;
+CREATE TRIGGER projects_bump_version BEFORE UPDATE ON projects
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION bump_version();
+
+-- projects belong to organizations, existing ones to the personal
+-- organization of their creator
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS "org_id" UUID REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE projects SET org_id = created_by WHERE org_id IS NULL;
+ALTER TABLE projects ALTER COLUMN org_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS projects_org_id_index ON projects(org_id);`
+
+	queryCreateProjects tenantQuery = `INSERT INTO projects (id, name, created_by, org_id) VALUES ($1, $2, $3, $org);`
+
+	queryGetMyProjectsByName tenantQuery = `SELECT p.id, p.name, p.created_by
+FROM projects AS p
+WHERE name = $1 and created_by = $2 AND org_id = $org AND deleted_at IS NULL;`
 
-	queryGetMyProjectsByName = `SELECT p.id, p.name, p.created_by
+	queryGetProjectsByID tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.archived_at, p.version
 FROM projects AS p
-WHERE name = $1 and created_by = $2;`
+WHERE p.id = $1 AND p.org_id = $org AND p.deleted_at IS NULL;`
 
-	queryGetProjectsByID = `SELECT p.id, p.name, p.created_by
+	queryGetAllProjects tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.archived_at, p.version
 FROM projects AS p
-WHERE p.id = $1;`
+WHERE org_id = $org AND deleted_at IS NULL AND ($1 OR archived_at IS NULL);`
 
-	queryGetMyProjects = `SELECT p.id, p.name, p.created_by 
+	queryLockProject tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.archived_at, p.version
 FROM projects AS p
-WHERE created_by = $1;`
+WHERE p.id = $1 AND p.org_id = $org AND p.deleted_at IS NULL
+FOR UPDATE;`
 
+	// $3 is the expected version, zero matches any
 	queryUpdateProjectName = `UPDATE projects SET name = $1
- WHERE id = $2;`
+ WHERE id = $2 AND deleted_at IS NULL AND ($3::BIGINT = 0 OR version = $3);`
 
-	queryDeleteProject = `DELETE FROM projects WHERE id = $1;`
+	queryArchiveProject = `UPDATE projects SET archived_at = CASE WHEN $2 THEN now() END WHERE id = $1
+RETURNING archived_at;`
+
+	queryDeleteProject = `UPDATE projects SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND ($2::BIGINT = 0 OR version = $2);`
+
+	queryGetTrashedProjects tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.deleted_at
+FROM projects AS p
+WHERE created_by = $1 AND org_id = $org AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;`
+
+	queryLockTrashedProject tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.deleted_at
+FROM projects AS p
+WHERE p.id = $1 AND p.org_id = $org AND p.deleted_at IS NOT NULL
+FOR UPDATE;`
+
+	queryRestoreProject = `UPDATE projects SET deleted_at = NULL WHERE id = $1;`
+
+	// queryPurgeProjectActivity removes the activity of the projects that
+	// queryPurgeProjects removes, activity_log has no foreign keys
+	queryPurgeProjectActivity = `DELETE FROM activity_log
+WHERE project_id IN (SELECT id FROM projects WHERE deleted_at < $1);`
+
+	// queryPurgeProjects removes projects trashed before $1, their columns
+	// and todos go with them
+	queryPurgeProjects = `DELETE FROM projects WHERE deleted_at < $1;`
 )
 
 // query for Todos Storage
 const (
 	queryMigrateT = `CREATE TABLE IF NOT EXISTS todos (
     "id" UUID PRIMARY KEY NOT NULL UNIQUE,
-    "name" VARCHAR NOT NULL UNIQUE,
+    "name" VARCHAR NOT NULL,
     "description" VARCHAR NOT NULL,
     "is_completed" BOOLEAN NOT NULL DEFAULT FALSE,
     "created_by" UUID NOT NULL,
     "project_id" UUID NOT NULL,
-    "column" VARCHAR NOT NULL,
-    FOREIGN KEY (project_id, "column") REFERENCES project_columns(project_id, name),
+    "column_id" UUID,
+    "position" VARCHAR COLLATE "C" NOT NULL DEFAULT '',
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (column_id) REFERENCES project_columns(id),
     FOREIGN KEY (created_by) REFERENCES users(id)
 );
 
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'todos' AND column_name = 'column') THEN
+        ALTER TABLE todos ADD COLUMN column_id UUID;
+        UPDATE todos AS t SET column_id = c.id
+        FROM project_columns AS c
+        WHERE c.project_id = t.project_id AND c.name = t."column";
+        ALTER TABLE todos ALTER COLUMN column_id SET NOT NULL;
+        ALTER TABLE todos ADD FOREIGN KEY (column_id) REFERENCES project_columns(id);
+        ALTER TABLE todos ADD FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE;
+        ALTER TABLE todos DROP COLUMN "column";
+    END IF;
+END $$;
+
 CREATE INDEX IF NOT EXISTS todos_created_by_index ON todos(created_by);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "position" VARCHAR COLLATE "C" NOT NULL DEFAULT '';
+
+UPDATE todos AS t SET "position" = r.position
+FROM (SELECT id, lpad(row_number() OVER (PARTITION BY column_id ORDER BY name)::text, 10, '0') || 'V' AS position
+      FROM todos) AS r
+WHERE t.id = r.id AND t."position" = '';
+
+CREATE INDEX IF NOT EXISTS todos_column_position_index ON todos(column_id, "position");
+
+CREATE TABLE IF NOT EXISTS todo_dependencies
+(
+    "blocker_id" UUID NOT NULL,
+    "blocked_id" UUID NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id),
+    FOREIGN KEY (blocker_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS todo_dependencies_blocked_id_index ON todo_dependencies(blocked_id);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "recurrence" VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "due_date" DATE;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "spawned_from" UUID UNIQUE REFERENCES todos(id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "archived_at" TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "labels" VARCHAR[] NOT NULL DEFAULT '{}';
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "assignee_id" UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+
+-- a todo that is archived or in the trash when its column is deleted loses
+-- its column, last_column keeps the name to put it back into
+ALTER TABLE todos ALTER COLUMN column_id DROP NOT NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS "last_column" VARCHAR;
+
+DROP TRIGGER IF EXISTS todos_bump_version ON todos;
+CREATE TRIGGER todos_bump_version BEFORE UPDATE ON todos
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION bump_version();
+
+-- names of todos are unique within their project, not across organizations
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS todos_project_id_name_index ON todos(project_id, name);
+`
+	queryCreateTodo = `INSERT INTO todos (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	// queryGetRecurringTodo returns the fields an occurrence is spawned from
+	queryGetRecurringTodo = `SELECT name, description, created_by, project_id, recurrence, due_date FROM todos WHERE id = $1;`
+	queryLockFirstColumn  = `SELECT id, is_done FROM project_columns
+WHERE project_id = $1
+ORDER BY "order"
+LIMIT 1
+FOR UPDATE;`
+	// querySpawnTodo inserts the next occurrence at most once per completed
+	// occurrence, spawned_from is unique
+	querySpawnTodo = `INSERT INTO todos (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date, spawned_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (spawned_from) DO NOTHING;`
+	// todoSelect is the select list scanned by scanTodo. Progress is the share
+	// of done checklist items, a todo without items is either 0 or 100 percent.
+	// The arrays are the todos blocking this one and the todos it blocks.
+	// A todo detached from its deleted column has the nil column id and no
+	// column name. Queries built on it must filter out deleted todos and projects
+	todoSelect = `SELECT t.id, t.name, t.description, t.is_completed, t.created_by, t.project_id,
+       COALESCE(t.column_id, '00000000-0000-0000-0000-000000000000'::UUID), COALESCE(c.name, ''), t."position", t.recurrence, t.due_date,
+       (SELECT CASE
+                   WHEN count(*) = 0 THEN CASE WHEN t.is_completed THEN 100 ELSE 0 END
+                   ELSE 100 * count(*) FILTER (WHERE i.is_done) / count(*)
+               END
+        FROM todo_checklist_items AS i
+        WHERE i.todo_id = t.id)::INT,
+       ARRAY(SELECT d.blocker_id FROM todo_dependencies AS d JOIN todos AS o ON o.id = d.blocker_id
+             WHERE d.blocked_id = t.id AND o.deleted_at IS NULL ORDER BY d.blocker_id),
+       ARRAY(SELECT d.blocked_id FROM todo_dependencies AS d JOIN todos AS o ON o.id = d.blocked_id
+             WHERE d.blocker_id = t.id AND o.deleted_at IS NULL ORDER BY d.blocked_id),
+       t.labels, t.assignee_id, t.deleted_at, t.archived_at, t.version
+FROM todos AS t
+LEFT JOIN project_columns AS c ON c.id = t.column_id
+JOIN projects AS p ON p.id = t.project_id AND p.org_id = $org
 `
-	queryCreateTodo  = `INSERT INTO todos (id, name, description, is_completed, created_by, project_id, "column" )VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	queryTodoGetByID = `SELECT id, name, description, is_completed, created_by, project_id, "column" FROM todos WHERE id = $1`
-	queryGetAllTodos = `SELECT * FROM todos WHERE created_by = $1;`
-	queryUpdateTodo  = `UPDATE todos
+	queryTodoGetByID tenantQuery = todoSelect + `WHERE t.id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL`
+	// queryGetAllTodos hides archived todos and todos of archived projects
+	// unless $2 is true
+	queryGetAllTodos tenantQuery = todoSelect + `WHERE t.created_by = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+  AND ($2 OR (t.archived_at IS NULL AND p.archived_at IS NULL))
+ORDER BY t.project_id, c."order", t."position";`
+	// queryGetTrashedTodos skips todos of deleted projects, they can not be
+	// restored before their project
+	queryGetTrashedTodos tenantQuery = todoSelect + `WHERE t.created_by = $1 AND t.deleted_at IS NOT NULL AND p.deleted_at IS NULL
+ORDER BY t.deleted_at DESC;`
+	queryLockTodoState tenantQuery = `SELECT t.project_id, t.is_completed
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = $1 AND t.deleted_at IS NULL AND p.org_id = $org AND p.deleted_at IS NULL
+FOR UPDATE OF t;`
+	queryLockTrashedTodo tenantQuery = `SELECT t.project_id
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = $1 AND t.deleted_at IS NOT NULL AND p.org_id = $org AND p.deleted_at IS NULL
+FOR UPDATE OF t;`
+	// queryArchiveTodo archives the todo when $2 is true, otherwise it puts the
+	// todo back into column $4 at position $3
+	queryArchiveTodo = `UPDATE todos
+SET archived_at = CASE WHEN $2 THEN now() END,
+    "position" = CASE WHEN $2 THEN "position" ELSE $3 END,
+    column_id = CASE WHEN $2 THEN column_id ELSE $4 END,
+    last_column = CASE WHEN $2 THEN last_column END
+WHERE id = $1;`
+	// queryGetBackColumn returns the column a todo goes back to: its own one
+	// or, when it was detached from its deleted column, the column of the
+	// project with the same name or else the first one. It is NULL when the
+	// project has no columns
+	queryGetBackColumn = `SELECT COALESCE(t.column_id,
+    (SELECT c.id FROM project_columns AS c WHERE c.project_id = t.project_id AND c.name = t.last_column),
+    (SELECT c.id FROM project_columns AS c WHERE c.project_id = t.project_id ORDER BY c."order" LIMIT 1))
+FROM todos AS t
+WHERE t.id = $1;`
+	queryRestoreTodo       = `UPDATE todos SET deleted_at = NULL, column_id = $3, last_column = NULL, "position" = $2 WHERE id = $1;`
+	queryPurgeTodos        = `DELETE FROM todos WHERE deleted_at < $1;`
+	queryCountOpenBlockers = `SELECT count(*)
+FROM todo_dependencies AS d
+JOIN todos AS b ON b.id = d.blocker_id
+WHERE d.blocked_id = $1 AND NOT b.is_completed AND b.deleted_at IS NULL;`
+	queryGetTodoProject tenantQuery = `SELECT t.project_id
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = $1 AND t.deleted_at IS NULL AND p.org_id = $org;`
+	queryLockProjectDependencies = `SELECT pg_advisory_xact_lock(hashtextextended($1::TEXT, 0));`
+	// queryDependencyPath reports whether "to" is reachable from "from" by
+	// following blocker -> blocked edges
+	queryDependencyPath = `WITH RECURSIVE reachable(id) AS (
+    SELECT $1::UUID
+    UNION
+    SELECT d.blocked_id FROM todo_dependencies AS d JOIN reachable AS r ON d.blocker_id = r.id
+)
+SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2);`
+	queryAddDependency                = `INSERT INTO todo_dependencies (blocker_id, blocked_id) VALUES ($1, $2);`
+	queryRemoveDependency tenantQuery = `DELETE FROM todo_dependencies
+WHERE blocker_id = $1 AND blocked_id = $2 AND blocked_id IN (` + tenantTodos + `);`
+	// $5 is the expected version, zero matches any
+	queryUpdateTodo = `UPDATE todos
 		SET name = $1, description = $2, is_completed = $3
-		WHERE id = $4`
-	queryDeleteTodo = `DELETE FROM todos WHERE id = $1`
+		WHERE id = $4 AND ($5::BIGINT = 0 OR version = $5)`
+	queryLockTodoColumn tenantQuery = `SELECT t.project_id, COALESCE(t.column_id, '00000000-0000-0000-0000-000000000000'::UUID), COALESCE(c.is_done, FALSE), t.archived_at IS NOT NULL
+FROM todos AS t
+LEFT JOIN project_columns AS c ON c.id = t.column_id
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = $1 AND t.deleted_at IS NULL AND p.org_id = $org
+FOR UPDATE OF t;`
+	// archived and deleted todos are not part of their column: they do not
+	// count against the WIP limit and do not take positions
+	queryCountColumnTodos = `SELECT count(*) FROM todos WHERE column_id = $1 AND id <> $2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetTodoInColumn  = `SELECT "position" FROM todos WHERE id = $1 AND column_id = $2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetLastPosition  = `SELECT COALESCE(MAX("position"), '') FROM todos
+WHERE column_id = $1 AND id <> $2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetNextPosition = `SELECT COALESCE(MIN("position"), '') FROM todos
+WHERE column_id = $1 AND id <> $2 AND "position" > $3 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetPrevPosition = `SELECT COALESCE(MAX("position"), '') FROM todos
+WHERE column_id = $1 AND id <> $2 AND "position" < $3 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryMoveTodo   = `UPDATE todos SET column_id = $1, "position" = $2, is_completed = $3 WHERE id = $4;`
+	queryDeleteTodo = `UPDATE todos SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2::BIGINT = 0 OR version = $2)`
+	// the nil column id of $6 keeps a detached todo detached
+	queryPatchTodo = `UPDATE todos
+SET name = $1, description = $2, is_completed = $3, labels = $4, project_id = $5, column_id = NULLIF($6, '00000000-0000-0000-0000-000000000000'::UUID), "position" = $7
+WHERE id = $8 AND ($9::BIGINT = 0 OR version = $9);`
+	queryRelabelTodo = `UPDATE todos SET labels = $1 WHERE id = $2;`
+	queryAssignTodo  = `UPDATE todos SET assignee_id = $1 WHERE id = $2;`
+	// queryCanChangeTodo reports whether $2 created the todo or owns its project
+	queryCanChangeTodo tenantQuery = `SELECT t.created_by = $2 OR p.created_by = $2
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = $1 AND t.deleted_at IS NULL AND p.org_id = $org AND p.deleted_at IS NULL;`
 )
 
 // query for Users Storage
@@ -77,30 +397,533 @@ WHERE u.login = $1;`
     encrypted_password VARCHAR NOT NULL
 );
 CREATE UNIQUE INDEX IF NOT EXISTS users_login_idx ON users (login);`
-
-	queryGetAllUsers = `SELECT u.id, u.login
-FROM users AS u;`
 )
 
 // query for Columns Storage
 const (
 	queryMigrateColumnsTable = `CREATE TABLE IF NOT EXISTS project_columns
 (
+    "id" UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
     "project_id" UUID NOT NULL,
     "name" VARCHAR,
     "order" INT,
+    "wip_limit" INT CHECK (wip_limit > 0),
+    "is_done" BOOLEAN NOT NULL DEFAULT FALSE,
     PRIMARY KEY (project_id, name),
     FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+ALTER TABLE project_columns ADD COLUMN IF NOT EXISTS "id" UUID NOT NULL UNIQUE DEFAULT gen_random_uuid();
+
+ALTER TABLE project_columns
+    ADD COLUMN IF NOT EXISTS "wip_limit" INT CHECK (wip_limit > 0),
+    ADD COLUMN IF NOT EXISTS "is_done" BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE project_columns AS c SET "order" = r.position
+FROM (SELECT project_id, name, row_number() OVER (PARTITION BY project_id ORDER BY "order" NULLS LAST, name) - 1 AS position
+      FROM project_columns) AS r
+WHERE c.project_id = r.project_id AND c.name = r.name AND c."order" IS DISTINCT FROM r.position;
+
+ALTER TABLE project_columns ALTER COLUMN "order" SET NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'project_columns_order_key') THEN
+        ALTER TABLE project_columns ADD CONSTRAINT project_columns_order_key
+            UNIQUE (project_id, "order") DEFERRABLE INITIALLY DEFERRED;
+    END IF;
+END $$;
+
+ALTER TABLE project_columns ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+
+DROP TRIGGER IF EXISTS project_columns_bump_version ON project_columns;
+CREATE TRIGGER project_columns_bump_version BEFORE UPDATE ON project_columns
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION bump_version();`
+
+	queryProjectIsActive tenantQuery = `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND org_id = $org AND deleted_at IS NULL);`
+
+	queryLockProjectColumns = `SELECT name FROM project_columns WHERE project_id = $1 ORDER BY "order" FOR UPDATE;`
+
+	queryShiftColumnsRight = `UPDATE project_columns SET "order" = "order" + 1 WHERE project_id = $1 AND "order" >= $2;`
+
+	queryShiftColumnsLeft = `UPDATE project_columns SET "order" = "order" - 1 WHERE project_id = $1 AND "order" > $2;`
+
+	queryInsertColumns = `INSERT INTO project_columns (id, project_id, name, "order", wip_limit, is_done) VALUES ($1, $2, $3, $4, $5, $6);`
+
+	// queryDeleteColumns fails on the foreign key of the todos left in the column
+	queryDeleteColumns = `DELETE FROM project_columns WHERE id = $1;`
+
+	queryMoveColumnLast = `UPDATE project_columns SET "order" = $2 WHERE id = $1;`
+
+	queryGetColumnByName tenantQuery = `SELECT id, project_id, name, "order", wip_limit, is_done, version FROM project_columns
+WHERE name = $1 and project_id = $2
+  AND EXISTS (SELECT 1 FROM projects AS p WHERE p.id = project_id AND p.org_id = $org AND p.deleted_at IS NULL);`
+
+	queryLockColumn tenantQuery = `SELECT id, project_id, name, "order", wip_limit, is_done, version FROM project_columns
+WHERE name = $1 AND project_id = $2
+  AND EXISTS (SELECT 1 FROM projects AS p WHERE p.id = project_id AND p.org_id = $org AND p.deleted_at IS NULL)
+FOR UPDATE;`
+
+	queryUpdateColumns = `UPDATE project_columns SET name = $1, wip_limit = $2, is_done = $3
+WHERE name = $4 and project_id = $5 AND ($6::BIGINT = 0 OR version = $6);`
+
+	queryReorderColumns = `UPDATE project_columns AS c SET "order" = o.idx - 1
+FROM unnest($2::VARCHAR[]) WITH ORDINALITY AS o(name, idx)
+WHERE c.project_id = $1 AND c.name = o.name;`
+
+	queryGetAllColumns tenantQuery = `SELECT id, project_id, name, "order", wip_limit, is_done, version FROM project_columns
+WHERE project_id = $1
+  AND EXISTS (SELECT 1 FROM projects AS p WHERE p.id = project_id AND p.org_id = $org AND p.deleted_at IS NULL)
+ORDER BY "order";`
+
+	queryGetColumnTodos = `SELECT id FROM todos WHERE column_id = $1 AND deleted_at IS NULL AND archived_at IS NULL ORDER BY "position";`
+
+	// queryDetachColumnTodos takes the archived and trashed todos out of the
+	// column $1 being deleted, remembering its name $2 to put them back
+	queryDetachColumnTodos = `UPDATE todos SET column_id = NULL, last_column = $2
+WHERE column_id = $1 AND (archived_at IS NOT NULL OR deleted_at IS NOT NULL);`
+)
+
+// query for Templates Storage
+const (
+	queryMigrateTemplates = `CREATE TABLE IF NOT EXISTS project_templates
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "name" VARCHAR NOT NULL,
+    "created_by" UUID NOT NULL,
+    "columns" JSONB NOT NULL,
+    UNIQUE (created_by, name),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
 );`
 
-	queryInsertColumns = `INSERT INTO project_columns (project_id, name, "order") VALUES ($1, $2, $3);`
+	queryCreateTemplate = `INSERT INTO project_templates (id, name, created_by, columns) VALUES ($1, $2, $3, $4);`
+
+	queryGetTemplateByID = `SELECT id, name, created_by, columns FROM project_templates WHERE id = $1;`
+
+	queryGetMyTemplates = `SELECT id, name, created_by, columns FROM project_templates WHERE created_by = $1 ORDER BY name;`
+
+	queryDeleteTemplate = `DELETE FROM project_templates WHERE id = $1 AND created_by = $2;`
+)
+
+// query for Checklist Storage
+const (
+	queryMigrateChecklist = `CREATE TABLE IF NOT EXISTS todo_checklist_items
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "todo_id" UUID NOT NULL,
+    "text" VARCHAR NOT NULL,
+    "is_done" BOOLEAN NOT NULL DEFAULT FALSE,
+    "position" VARCHAR COLLATE "C" NOT NULL,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS todo_checklist_items_todo_id_index ON todo_checklist_items(todo_id, "position");`
+
+	queryLockTodo tenantQuery = `SELECT id FROM todos WHERE id = $1 AND deleted_at IS NULL AND project_id IN (` + tenantProjects + `) FOR UPDATE;`
+
+	queryGetLastItemPosition = `SELECT COALESCE(MAX("position"), '') FROM todo_checklist_items WHERE todo_id = $1;`
+
+	queryCreateItem = `INSERT INTO todo_checklist_items (id, todo_id, text, is_done, "position") VALUES ($1, $2, $3, $4, $5);`
+
+	queryGetItemByID tenantQuery = `SELECT id, todo_id, text, is_done, "position" FROM todo_checklist_items
+WHERE id = $1 AND todo_id = $2 AND todo_id IN (` + tenantTodos + `);`
+
+	queryGetAllItems tenantQuery = `SELECT id, todo_id, text, is_done, "position" FROM todo_checklist_items
+WHERE todo_id = $1 AND todo_id IN (` + tenantTodos + `)
+ORDER BY "position";`
+
+	queryUpdateItem tenantQuery = `UPDATE todo_checklist_items SET text = $1, is_done = $2
+WHERE id = $3 AND todo_id = $4 AND todo_id IN (` + tenantTodos + `);`
+
+	queryDeleteItem tenantQuery = `DELETE FROM todo_checklist_items
+WHERE id = $1 AND todo_id = $2 AND todo_id IN (` + tenantTodos + `);`
+)
+
+// query for Comments Storage
+const (
+	queryMigrateComments = `CREATE TABLE IF NOT EXISTS todo_comments
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "todo_id" UUID NOT NULL,
+    "author_id" UUID NOT NULL,
+    "body" VARCHAR NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "deleted_at" TIMESTAMPTZ,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS todo_comments_todo_id_index ON todo_comments(todo_id, created_at);
+
+CREATE TABLE IF NOT EXISTS comment_mentions
+(
+    "comment_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES todo_comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS comment_mentions_user_id_index ON comment_mentions(user_id);`
+
+	// commentSelect is the select list scanned by scanComment
+	commentSelect = `SELECT m.id, m.todo_id, m.author_id, m.body, m.created_at, m.updated_at,
+       ARRAY(SELECT cm.user_id FROM comment_mentions AS cm WHERE cm.comment_id = m.id ORDER BY cm.user_id)
+FROM todo_comments AS m
+`
+
+	queryCreateComment tenantQuery = `INSERT INTO todo_comments (id, todo_id, author_id, body)
+SELECT $1::UUID, t.id, $3::UUID, $4::VARCHAR FROM todos AS t
+WHERE t.id = $2 AND t.deleted_at IS NULL AND t.project_id IN (` + tenantProjects + `)
+RETURNING created_at, updated_at;`
+
+	queryGetCommentByID tenantQuery = commentSelect + `WHERE m.id = $1 AND m.todo_id = $2 AND m.deleted_at IS NULL
+  AND m.todo_id IN (` + tenantTodos + `);`
+
+	queryGetComments tenantQuery = commentSelect + `WHERE m.todo_id = $1 AND m.deleted_at IS NULL
+  AND m.todo_id IN (` + tenantTodos + `)
+ORDER BY m.created_at, m.id
+LIMIT $2 OFFSET $3;`
+
+	queryCountComments tenantQuery = `SELECT count(*) FROM todo_comments
+WHERE todo_id = $1 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `);`
+
+	queryUpdateComment tenantQuery = `UPDATE todo_comments SET body = $1, updated_at = now()
+WHERE id = $2 AND todo_id = $3 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `)
+RETURNING updated_at;`
+
+	querySoftDeleteComment tenantQuery = `UPDATE todo_comments SET deleted_at = now()
+WHERE id = $1 AND todo_id = $2 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `);`
+
+	// queryResolveLogins resolves the logins of the members of the tenant only
+	queryResolveLogins tenantQuery = `SELECT u.id FROM users AS u
+JOIN organization_members AS m ON m.user_id = u.id AND m.org_id = $org
+WHERE u.login = ANY($1)
+ORDER BY u.id;`
+
+	queryClearMentions = `DELETE FROM comment_mentions WHERE comment_id = $1;`
+
+	queryAddMentions = `INSERT INTO comment_mentions (comment_id, user_id) SELECT $1, unnest($2::UUID[]);`
+)
+
+// query for Attachments Storage
+const (
+	queryMigrateAttachments = `CREATE TABLE IF NOT EXISTS todo_attachments
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "todo_id" UUID NOT NULL,
+    "uploaded_by" UUID NOT NULL,
+    "file_name" VARCHAR NOT NULL,
+    "content_type" VARCHAR NOT NULL,
+    "size" BIGINT NOT NULL,
+    "storage_key" VARCHAR NOT NULL UNIQUE,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS todo_attachments_todo_id_index ON todo_attachments(todo_id, created_at);`
+
+	queryCreateAttachment tenantQuery = `INSERT INTO todo_attachments (id, todo_id, uploaded_by, file_name, content_type, size, storage_key)
+SELECT $1::UUID, t.id, $3::UUID, $4::VARCHAR, $5::VARCHAR, $6::BIGINT, $7::VARCHAR FROM todos AS t
+WHERE t.id = $2 AND t.project_id IN (` + tenantProjects + `)
+RETURNING created_at;`
+
+	queryGetAttachmentByID tenantQuery = `SELECT id, todo_id, uploaded_by, file_name, content_type, size, storage_key, created_at
+FROM todo_attachments WHERE id = $1 AND todo_id = $2 AND todo_id IN (` + tenantTodos + `);`
+
+	queryGetAttachments tenantQuery = `SELECT id, todo_id, uploaded_by, file_name, content_type, size, storage_key, created_at
+FROM todo_attachments WHERE todo_id = $1 AND todo_id IN (` + tenantTodos + `)
+ORDER BY created_at, id;`
+
+	queryDeleteAttachment tenantQuery = `DELETE FROM todo_attachments
+WHERE id = $1 AND todo_id = $2 AND todo_id IN (` + tenantTodos + `);`
+
+	// queryPurgeAttachments removes the attachments that queryPurgeProjects
+	// and queryPurgeTodos would remove with their todos
+	queryPurgeAttachments = `DELETE FROM todo_attachments
+WHERE todo_id IN (
+    SELECT t.id FROM todos AS t
+    JOIN projects AS p ON p.id = t.project_id
+    WHERE t.deleted_at < $1 OR p.deleted_at < $1
+)
+RETURNING storage_key;`
+)
+
+// query for Activity Storage
+const (
+	// activity_log has no foreign keys, records outlive the entities they
+	// describe
+	queryMigrateActivity = `CREATE TABLE IF NOT EXISTS activity_log
+(
+    "id" BIGSERIAL PRIMARY KEY,
+    "project_id" UUID NOT NULL,
+    "actor_id" UUID,
+    "entity" VARCHAR NOT NULL,
+    "entity_id" UUID NOT NULL,
+    "action" VARCHAR NOT NULL,
+    "changes" JSONB NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS activity_log_project_id_index ON activity_log(project_id, id DESC);`
+
+	// queryLockProjectActivity makes transactions write activity of a project
+	// one at a time, so ids of the project are committed in order and readers
+	// following the ids do not skip records committed late
+	queryLockProjectActivity = `SELECT pg_advisory_xact_lock(hashtextextended($1::TEXT, 1));`
+
+	queryAddActivity = `INSERT INTO activity_log (project_id, actor_id, entity, entity_id, action, changes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at;`
+
+	queryGetActivity tenantQuery = `SELECT id, project_id, actor_id, entity, entity_id, action, changes, created_at
+FROM activity_log
+WHERE project_id = $1 AND project_id IN (` + tenantProjects + `)
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;`
+
+	queryCountActivity tenantQuery = `SELECT count(*) FROM activity_log WHERE project_id = $1 AND project_id IN (` + tenantProjects + `);`
+
+	queryGetActivitySince tenantQuery = `SELECT id, project_id, actor_id, entity, entity_id, action, changes, created_at
+FROM activity_log
+WHERE project_id = $1 AND id > $2 AND project_id IN (` + tenantProjects + `)
+ORDER BY id
+LIMIT $3;`
+
+	queryGetLastActivityID tenantQuery = `SELECT COALESCE(MAX(id), 0) FROM activity_log
+WHERE project_id = $1 AND project_id IN (` + tenantProjects + `);`
+
+	// queryNotifyActivity is delivered to listeners when the transaction commits,
+	// repeated notifications of one transaction are merged
+	queryNotifyActivity = `SELECT pg_notify('` + projectEventsChannel + `', $1::TEXT);`
+)
+
+// query for Idempotency Storage
+const (
+	// status is NULL while the first request with the key is running
+	queryMigrateIdempotency = `CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    "user_id" UUID NOT NULL,
+    "key" VARCHAR NOT NULL,
+    "fingerprint" VARCHAR NOT NULL,
+    "status" INT,
+    "content_type" VARCHAR NOT NULL DEFAULT '',
+    "body" BYTEA,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_index ON idempotency_keys(expires_at);
+
+-- keys are scoped to the organization too, existing ones were sent in the
+-- personal organization of their user
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'idempotency_keys' AND column_name = 'org_id') THEN
+        ALTER TABLE idempotency_keys ADD COLUMN org_id UUID;
+        UPDATE idempotency_keys SET org_id = user_id;
+        ALTER TABLE idempotency_keys ALTER COLUMN org_id SET NOT NULL;
+        ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+        ALTER TABLE idempotency_keys ADD PRIMARY KEY (user_id, org_id, key);
+    END IF;
+END $$;`
+
+	// queryAcquireIdempotencyKey takes a new key or an expired one, it
+	// returns no row when the key is taken
+	queryAcquireIdempotencyKey = `INSERT INTO idempotency_keys (user_id, org_id, key, fingerprint, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, org_id, key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint, status = NULL, content_type = '', body = NULL,
+    created_at = now(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING user_id;`
+
+	queryGetIdempotencyKey = `SELECT user_id, org_id, key, fingerprint, COALESCE(status, 0), content_type, body, expires_at
+FROM idempotency_keys
+WHERE user_id = $1 AND org_id = $2 AND key = $3;`
+
+	queryCompleteIdempotencyKey = `UPDATE idempotency_keys SET status = $4, content_type = $5, body = $6
+WHERE user_id = $1 AND org_id = $2 AND key = $3;`
+
+	queryReleaseIdempotencyKey = `DELETE FROM idempotency_keys WHERE user_id = $1 AND org_id = $2 AND key = $3 AND status IS NULL;`
+
+	queryPurgeIdempotencyKeys = `DELETE FROM idempotency_keys WHERE expires_at < $1;`
+)
+
+// query for Webhooks Storage
+const (
+	// webhook_deliveries is the outbox of webhooks: deliveries are added in the
+	// transaction of the change and sent by the dispatcher. A pending delivery
+	// is due at next_attempt_at, webhook_delivery_attempts logs every attempt
+	queryMigrateWebhooks = `CREATE TABLE IF NOT EXISTS webhooks
+(
+    "id" UUID PRIMARY KEY NOT NULL,
+    "project_id" UUID NOT NULL,
+    "url" VARCHAR NOT NULL,
+    "secret" VARCHAR NOT NULL,
+    "events" VARCHAR[] NOT NULL DEFAULT '{}',
+    "created_by" UUID NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_project_id_index ON webhooks(project_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    "id" UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "webhook_id" UUID NOT NULL,
+    "activity_id" BIGINT NOT NULL,
+    "event" VARCHAR NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" VARCHAR NOT NULL DEFAULT 'pending',
+    "attempts" INT NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMPTZ,
+    "last_status_code" INT,
+    "last_error" VARCHAR NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "delivered_at" TIMESTAMPTZ,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_index ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_index ON webhook_deliveries(webhook_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts
+(
+    "id" BIGSERIAL PRIMARY KEY,
+    "delivery_id" UUID NOT NULL,
+    "attempted_at" TIMESTAMPTZ NOT NULL,
+    "status_code" INT,
+    "error" VARCHAR NOT NULL DEFAULT '',
+    "duration_ms" BIGINT NOT NULL,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_index ON webhook_delivery_attempts(delivery_id, id);`
+
+	queryCreateWebhook tenantQuery = `INSERT INTO webhooks (id, project_id, url, secret, events, created_by)
+SELECT $1::UUID, p.id, $3::VARCHAR, $4::VARCHAR, $5::VARCHAR[], $6::UUID FROM projects AS p
+WHERE p.id = $2 AND p.org_id = $org
+RETURNING created_at;`
+
+	queryGetWebhook tenantQuery = `SELECT id, project_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE id = $1 AND project_id = $2 AND project_id IN (` + tenantProjects + `);`
+
+	queryGetWebhooks tenantQuery = `SELECT id, project_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE project_id = $1 AND project_id IN (` + tenantProjects + `)
+ORDER BY created_at;`
+
+	queryDeleteWebhook tenantQuery = `DELETE FROM webhooks
+WHERE id = $1 AND project_id = $2 AND project_id IN (` + tenantProjects + `);`
+
+	// queryEnqueueWebhookDeliveries adds a delivery of the activity record $3
+	// for every webhook of the project $1 subscribed to the event $2
+	queryEnqueueWebhookDeliveries = `INSERT INTO webhook_deliveries (webhook_id, activity_id, event, payload, next_attempt_at)
+SELECT w.id, $3::BIGINT, $2::VARCHAR, $4::JSONB, now()
+FROM webhooks AS w
+WHERE w.project_id = $1 AND (cardinality(w.events) = 0 OR $2::VARCHAR = ANY (w.events));`
+
+	// queryClaimWebhookDeliveries takes up to $3 deliveries due at $1 and
+	// hides them from other dispatchers until $2
+	queryClaimWebhookDeliveries = `UPDATE webhook_deliveries AS d
+SET next_attempt_at = $2
+FROM webhooks AS w
+WHERE w.id = d.webhook_id AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= $1
+    ORDER BY next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.webhook_id, d.activity_id, d.event, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret;`
+
+	queryAddWebhookAttempt = `INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5);`
+
+	queryUpdateWebhookDelivery = `UPDATE webhook_deliveries
+SET status = $2::VARCHAR, attempts = attempts + 1, next_attempt_at = $3, last_status_code = $4, last_error = $5,
+    delivered_at = CASE WHEN $2::VARCHAR = 'delivered' THEN $6::TIMESTAMPTZ END
+WHERE id = $1;`
+
+	queryRedeliverWebhook tenantQuery = `UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND webhook_id = $2 AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryWebhookDeliverySelect = `SELECT id, webhook_id, activity_id, event, payload, status, attempts, next_attempt_at,
+       last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+`
+
+	queryGetWebhookDelivery tenantQuery = queryWebhookDeliverySelect + `WHERE id = $1 AND webhook_id = $2
+  AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryGetWebhookDeliveries tenantQuery = queryWebhookDeliverySelect + `WHERE webhook_id = $1
+  AND webhook_id IN (` + tenantWebhooks + `)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;`
+
+	queryCountWebhookDeliveries tenantQuery = `SELECT count(*) FROM webhook_deliveries
+WHERE webhook_id = $1 AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryGetWebhookAttempts = `SELECT delivery_id, attempted_at, status_code, error, duration_ms
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY id;`
+)
+
+// query for Outbox Storage
+const (
+	// outbox_events keeps domain events written in the transaction of the
+	// change until the relay publishes them. Events have no foreign keys, so
+	// that they outlive purged projects
+	queryMigrateOutbox = `CREATE TABLE IF NOT EXISTS outbox_events
+(
+    "id" BIGSERIAL PRIMARY KEY,
+    "type" VARCHAR NOT NULL,
+    "aggregate_type" VARCHAR NOT NULL,
+    "aggregate_id" UUID NOT NULL,
+    "project_id" UUID NOT NULL,
+    "actor_id" UUID,
+    "changes" JSONB NOT NULL DEFAULT '{}',
+    "state" JSONB,
+    "occurred_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "published_at" TIMESTAMPTZ,
+    "attempts" INT NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "last_error" VARCHAR NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_index ON outbox_events(id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_published_at_index ON outbox_events(published_at) WHERE published_at IS NOT NULL;`
+
+	queryAddOutboxEvent = `INSERT INTO outbox_events (type, aggregate_type, aggregate_id, project_id, actor_id, changes, state, occurred_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	// queryLockOutboxRelay lets one relay publish at a time, so that events
+	// are published in order
+	queryLockOutboxRelay = `SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_events', 0));`
 
-	queryDeleteColumns = `DELETE FROM project_columns WHERE name = $1 and project_id = $2;`
+	queryGetUnpublishedEvents = `SELECT id, type, aggregate_type, aggregate_id, project_id, actor_id, changes, state, occurred_at, attempts, next_attempt_at
+FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1;`
 
-	queryGetColumnByName = `SELECT * FROM project_columns WHERE name = $1 and project_id = $2;`
+	queryMarkEventsPublished = `UPDATE outbox_events
+SET published_at = now(), attempts = attempts + 1, last_error = ''
+WHERE id = ANY ($1);`
 
-	queryUpdateColumns = `UPDATE project_columns SET name = $1 
-WHERE name = $2 and project_id = $3;`
+	queryFailOutboxEvent = `UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1 AND published_at IS NULL;`
 
-	queryGetAllColumns = `SELECT * FROM project_columns WHERE project_id = $1;`
+	queryPurgeOutboxEvents = `DELETE FROM outbox_events WHERE published_at < $1;`
 )
@@ -0,0 +1,158 @@
+package pgx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// maxWriters is how many recent writers the router remembers before it
+// forgets the ones whose sticky window is over
+const maxWriters = 1024
+
+// router is the db of the storages outside of WithTx. Every query runs on
+// the primary, read-only methods of the storages take a replica with
+// readDB. A user that ran a statement on the primary is kept there for
+// sticky after it, so they read their writes while the replicas catch up.
+// The router only knows the writes made through its own instance, clients
+// carry the window to the others, see storage.WithPrimary
+type router struct {
+	primary  *pgxpool.Pool
+	replicas []*pgxpool.Pool
+	next     atomic.Uint64
+	sticky   time.Duration
+
+	mu sync.Mutex
+	// writes is when the users wrote last
+	writes map[uuid.UUID]time.Time
+}
+
+func newRouter(primary *pgxpool.Pool, replicas []*pgxpool.Pool, sticky time.Duration) *router {
+	return &router{
+		primary:  primary,
+		replicas: replicas,
+		sticky:   sticky,
+		writes:   make(map[uuid.UUID]time.Time),
+	}
+}
+
+// readDB returns where a read-only query of a storage runs: a replica when
+// pool is the router, pool itself when it is the transaction of WithTx
+func readDB(ctx context.Context, pool db) db {
+	if r, ok := pool.(*router); ok {
+		return r.read(ctx)
+	}
+	return pool
+}
+
+// read returns the next replica, or the primary when there are none, when
+// ctx asks for it with storage.WithPrimary or when the user of ctx wrote
+// through this instance within the sticky window
+func (r *router) read(ctx context.Context) db {
+	if len(r.replicas) == 0 || storage.PrimaryFromContext(ctx) || r.wroteRecently(ctx) {
+		return r.primary
+	}
+	return r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+}
+
+func (r *router) wroteRecently(ctx context.Context) bool {
+	actor, ok := storage.ActorFromContext(ctx)
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wrote, ok := r.writes[actor]
+	return ok && time.Since(wrote) < r.sticky
+}
+
+// wrote starts the sticky window of the user of ctx
+func (r *router) wrote(ctx context.Context) {
+	if len(r.replicas) == 0 || r.sticky <= 0 {
+		return
+	}
+	actor, ok := storage.ActorFromContext(ctx)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if len(r.writes) >= maxWriters {
+		for user, wrote := range r.writes {
+			if now.Sub(wrote) >= r.sticky {
+				delete(r.writes, user)
+			}
+		}
+	}
+	r.writes[actor] = now
+}
+
+func (r *router) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.primary.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &routedTx{Tx: tx, router: r}, nil
+}
+
+func (r *router) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tag, err := r.primary.Exec(ctx, sql, arguments...)
+	if err == nil {
+		r.wrote(ctx)
+	}
+	return tag, err
+}
+
+// Query runs on the primary, so it is taken for a write, e.g. INSERT ...
+// RETURNING: reads that may go to a replica take it with readDB instead
+func (r *router) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := r.primary.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &routedRows{Rows: rows, ctx: ctx, router: r}, nil
+}
+
+func (r *router) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := r.Query(ctx, sql, args...)
+	return &txRow{rows: rows, err: err}
+}
+
+// routedRows are rows of a statement on the primary, the statement is done
+// when they are closed
+type routedRows struct {
+	pgx.Rows
+	ctx    context.Context
+	router *router
+}
+
+func (r *routedRows) Close() {
+	r.Rows.Close()
+	if r.Rows.Err() == nil {
+		r.router.wrote(r.ctx)
+	}
+}
+
+// routedTx is a transaction of the router, it starts the sticky window on
+// commit, when the replicas may start lagging behind
+type routedTx struct {
+	pgx.Tx
+	router *router
+}
+
+func (tx *routedTx) Commit(ctx context.Context) error {
+	if err := tx.Tx.Commit(ctx); err != nil {
+		return err
+	}
+	tx.router.wrote(ctx)
+	return nil
+}
@@ -0,0 +1,115 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+func TestSticky(t *testing.T) {
+	primary, replica := new(pgxpool.Pool), new(pgxpool.Pool)
+	r := newRouter(primary, []*pgxpool.Pool{replica}, time.Minute)
+	aliceID := uuid.New()
+	alice := storage.WithActor(context.Background(), aliceID)
+	bob := storage.WithActor(context.Background(), uuid.New())
+
+	if r.read(alice) != replica {
+		t.Fatal("got the primary for a user that did not write")
+	}
+	r.wrote(alice)
+	if r.read(alice) != primary {
+		t.Fatal("got a replica right after the write of the user")
+	}
+	if r.read(bob) != replica {
+		t.Fatal("got the primary for another user")
+	}
+	r.wrote(context.Background())
+	if r.read(context.Background()) != replica {
+		t.Fatal("got the primary without a user")
+	}
+
+	r.writes[aliceID] = time.Now().Add(-time.Minute)
+	if r.read(alice) != replica {
+		t.Fatal("got the primary after the sticky window")
+	}
+	// a client that wrote through another instance
+	if r.read(storage.WithPrimary(bob)) != primary {
+		t.Fatal("got a replica for a context asking for the primary")
+	}
+}
+
+// TestStatementsStartSticky checks that every statement run on the primary
+// through the router keeps its user there. The database stands in for its
+// own replica
+func TestStatementsStartSticky(t *testing.T) {
+	pool := newTestStore(t).pool
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, r *router) error
+	}{
+		{name: "exec", run: func(ctx context.Context, r *router) error {
+			_, err := r.Exec(ctx, `SELECT 1`)
+			return err
+		}},
+		{name: "query row", run: func(ctx context.Context, r *router) error {
+			var n int
+			return r.QueryRow(ctx, `SELECT 1`).Scan(&n)
+		}},
+		{name: "query row without rows", run: func(ctx context.Context, r *router) error {
+			var n int
+			if err := r.QueryRow(ctx, `SELECT 1 WHERE false`).Scan(&n); !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			return nil
+		}},
+		{name: "query", run: func(ctx context.Context, r *router) error {
+			rows, err := r.Query(ctx, `SELECT generate_series(1, 3)`)
+			if err != nil {
+				return err
+			}
+			_, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+			return err
+		}},
+		{name: "transaction", run: func(ctx context.Context, r *router) error {
+			tx, err := r.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, `SELECT 1`); err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter(pool, []*pgxpool.Pool{pool}, time.Minute)
+			ctx := storage.WithActor(context.Background(), uuid.New())
+			if err := tt.run(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+			if !r.wroteRecently(ctx) {
+				t.Fatal("got the user sent to the replicas right after the statement")
+			}
+		})
+	}
+
+	t.Run("failed statement", func(t *testing.T) {
+		r := newRouter(pool, []*pgxpool.Pool{pool}, time.Minute)
+		ctx := storage.WithActor(context.Background(), uuid.New())
+		rows, err := r.Query(ctx, `SELECT 1/0`)
+		if err == nil {
+			rows.Close()
+		}
+		if r.wroteRecently(ctx) {
+			t.Fatal("got the user kept on the primary after a failed statement")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Checking whether the interface "TemplateStorage" implements the structure "templateStorage"
+var _ storage.TemplateStorage = (*templateStorage)(nil)
+
+type templateStorage struct {
+	pool  db
+	log   *zap.Logger
+	pgErr *pgconn.PgError
+}
+
+func newTemplateStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*templateStorage, error) {
+	store := &templateStorage{
+		pool:  pool,
+		log:   log,
+		pgErr: pgErr,
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *templateStorage) migrate() error {
+	_, err := store.pool.Exec(context.Background(), queryMigrateTemplates)
+	if err != nil {
+		return errors2.ErrTableMigrations
+	}
+	return nil
+}
+
+func (store *templateStorage) Create(ctx context.Context, template *model.ProjectTemplateDTO) error {
+	_, err := store.pool.Exec(ctx, queryCreateTemplate, template.ID, template.Name, template.CreatedBy, template.Columns)
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	return nil
+}
+
+// GetByID returns a built-in template or a template stored in the database
+func (store *templateStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.ProjectTemplateDTO, error) {
+	if template, ok := model.GetBuiltinTemplate(id); ok {
+		return template, nil
+	}
+
+	template := new(model.ProjectTemplateDTO)
+	err := store.pool.QueryRow(ctx, queryGetTemplateByID, id).Scan(&template.ID, &template.Name, &template.CreatedBy, &template.Columns)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting template: %w", err)
+	}
+	return template, nil
+}
+
+// GetMyTemplates returns the built-in templates followed by the user's ones
+func (store *templateStorage) GetMyTemplates(ctx context.Context, createdBy uuid.UUID) ([]model.ProjectTemplateDTO, error) {
+	res := append([]model.ProjectTemplateDTO(nil), model.BuiltinTemplates...)
+
+	rows, err := store.pool.Query(ctx, queryGetMyTemplates, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying my templates: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.ProjectTemplateDTO
+		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy, &temp.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning templates: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+func (store *templateStorage) Delete(ctx context.Context, id uuid.UUID, createdBy uuid.UUID) error {
+	commandTag, err := store.pool.Exec(ctx, queryDeleteTemplate, id, createdBy)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errors2.ErrTemplateNotFound
+	}
+	return nil
+}
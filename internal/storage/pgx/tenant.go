@@ -0,0 +1,64 @@
+package pgx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// orgParam stands for the organization in a tenantQuery
+const orgParam = "$org"
+
+// tenantQuery is SQL reading or changing the data of an organization. It
+// filters by the organization with orgParam, e.g. "p.org_id = $org", and
+// can only be run by a tenant, which binds orgParam to the organization of
+// the context. A tenantQuery is not a string, so it can't be passed to
+// db by mistake, and one without orgParam fails with one argument too many
+type tenantQuery string
+
+// tenant runs tenant queries in the organization of a context. Methods of
+// storages of projects and their boards start with a tenant query that finds
+// or locks the entity they work on, so they never touch another
+// organization
+type tenant struct {
+	orgID uuid.UUID
+}
+
+// tenantOf returns the tenant of the organization set by storage.WithTenant,
+// ErrNoOrganization when ctx has none
+func tenantOf(ctx context.Context) (tenant, error) {
+	orgID, ok := storage.TenantFromContext(ctx)
+	if !ok {
+		return tenant{}, errors2.ErrNoOrganization
+	}
+	return tenant{orgID: orgID}, nil
+}
+
+// bind replaces orgParam with the parameter after args and appends the
+// organization to args
+func (t tenant) bind(query tenantQuery, args []any) (string, []any) {
+	sql := strings.ReplaceAll(string(query), orgParam, "$"+strconv.Itoa(len(args)+1))
+	return sql, append(args[:len(args):len(args)], t.orgID)
+}
+
+func (t tenant) Exec(ctx context.Context, on db, query tenantQuery, args ...any) (pgconn.CommandTag, error) {
+	sql, args := t.bind(query, args)
+	return on.Exec(ctx, sql, args...)
+}
+
+func (t tenant) Query(ctx context.Context, on db, query tenantQuery, args ...any) (pgx.Rows, error) {
+	sql, args := t.bind(query, args)
+	return on.Query(ctx, sql, args...)
+}
+
+func (t tenant) QueryRow(ctx context.Context, on db, query tenantQuery, args ...any) pgx.Row {
+	sql, args := t.bind(query, args)
+	return on.QueryRow(ctx, sql, args...)
+}
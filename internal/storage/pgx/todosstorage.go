@@ -6,28 +6,33 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/todo-enjoers/backend_v1/internal/model"
 	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/rank"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
 	"go.uber.org/zap"
+	"time"
 )
 
 // Checking whether the interface "TodoStorage" implements the structure "todoStorage"
 var _ storage.TodoStorage = (*todoStorage)(nil)
 
 type todoStorage struct {
-	pool  *pgxpool.Pool
+	pool  db
 	log   *zap.Logger
 	pgErr *pgconn.PgError
+	clock recurrence.Clock
 }
 
-func newTodoStorage(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError) (*todoStorage, error) {
+func newTodoStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError, clock recurrence.Clock) (*todoStorage, error) {
 	store := &todoStorage{
 		pool:  pool,
 		log:   log,
 		pgErr: pgErr,
+		clock: clock,
 	}
 	if err := store.migrateT(); err != nil {
 		return nil, err
@@ -43,30 +48,80 @@ func (store *todoStorage) migrateT() error {
 	return nil
 }
 
-func (store *todoStorage) Create(ctx context.Context, todo *model.TodoDTO) error {
-	_, err := store.pool.Exec(ctx, queryCreateTodo, todo.ID, todo.Name, todo.Description, todo.IsCompleted, todo.CreatedBy, todo.ProjectID, todo.Column)
+// Create puts the todo at the end of its column. A todo created in a done
+// column is completed. Unless force is set, a column that reached its WIP
+// limit rejects the todo with ErrWipLimitReached
+func (store *todoStorage) Create(ctx context.Context, todo *model.TodoDTO, force bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// locking the column serializes concurrent appends to it
+	column, err := lockColumn(ctx, t, tx, todo.Column, todo.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !force {
+		if err = checkWipLimit(ctx, tx, column, todo.ID); err != nil {
+			return err
+		}
+	}
+	todo.ColumnID = column.ID
+	todo.IsCompleted = todo.IsCompleted || column.IsDone
+
+	var last string
+	if err = tx.QueryRow(ctx, queryGetLastPosition, todo.ColumnID, todo.ID).Scan(&last); err != nil {
+		return errors2.ErrInserting
+	}
+	todo.Position = rank.After(last)
+	if todo.IsCompleted {
+		todo.Progress = 100
+	}
+
+	_, err = tx.Exec(ctx, queryCreateTodo, todo.ID, todo.Name, todo.Description, todo.IsCompleted, todo.CreatedBy, todo.ProjectID, todo.ColumnID, todo.Position, todo.Recurrence, todo.DueDate)
 	if err != nil {
 		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
 			return errors2.ErrAlreadyExists
 		}
 		return errors2.ErrInserting
 	}
-	return nil
+	if err = logActivity(ctx, tx, todo.ProjectID, storage.EntityTodo, todo.ID, storage.ActionCreate, nil, todo); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (store *todoStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error) {
-	var todo model.TodoDTO
-	err := store.pool.QueryRow(ctx, queryTodoGetByID, id).Scan(&todo.ID, &todo.Name, &todo.Description, &todo.IsCompleted, &todo.CreatedBy, &todo.ProjectID, &todo.Column)
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := scanTodo(t.QueryRow(ctx, readDB(ctx, store.pool), queryTodoGetByID, id))
 	if err != nil {
 		return nil, errors2.ErrGetByID
 	}
-	return &todo, nil
+	return todo, nil
 }
 
-func (store *todoStorage) GetAll(ctx context.Context, createdBy uuid.UUID) ([]model.TodoDTO, error) {
+// GetAll returns todos of the user in the organization of ctx, archived todos
+// and todos of archived projects only when includeArchived is set
+func (store *todoStorage) GetAll(ctx context.Context, createdBy uuid.UUID, includeArchived bool) ([]model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var res []model.TodoDTO
 
-	rows, err := store.pool.Query(ctx, queryGetAllTodos, createdBy)
+	rows, err := t.Query(ctx, readDB(ctx, store.pool), queryGetAllTodos, createdBy, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying all todos: %w", err)
 	}
@@ -74,12 +129,11 @@ func (store *todoStorage) GetAll(ctx context.Context, createdBy uuid.UUID) ([]mo
 	defer rows.Close()
 
 	for rows.Next() {
-		var temp model.TodoDTO
-		err = rows.Scan(&temp.ID, &temp.Name, &temp.Description, &temp.IsCompleted, &temp.CreatedBy, &temp.ProjectID, &temp.Column)
+		temp, err := scanTodo(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error while scanning todos: %w", err)
 		}
-		res = append(res, temp)
+		res = append(res, *temp)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -88,20 +142,890 @@ func (store *todoStorage) GetAll(ctx context.Context, createdBy uuid.UUID) ([]mo
 
 	return res, nil
 }
-func (store *todoStorage) Update(ctx context.Context, todo *model.TodoDTO, id uuid.UUID) error {
-	_, err := store.pool.Exec(ctx, queryUpdateTodo, todo.Name, todo.Description, todo.IsCompleted, id)
-	return err
+
+// Update refuses to complete a todo that has incomplete blockers with
+// ErrBlocked, unless force is set. Completing a recurring todo spawns its
+// next occurrence
+func (store *todoStorage) Update(ctx context.Context, todo *model.TodoDTO, id uuid.UUID, force bool) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = store.update(ctx, t, tx, todo, id, force); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (store *todoStorage) Delete(ctx context.Context, id uuid.UUID) error {
-	commandTag, err := store.pool.Exec(ctx, queryDeleteTodo, id)
+// update is Update within tx
+func (store *todoStorage) update(ctx context.Context, t tenant, tx pgx.Tx, todo *model.TodoDTO, id uuid.UUID, force bool) error {
+	var (
+		projectID   uuid.UUID
+		isCompleted bool
+	)
+	err := t.QueryRow(ctx, tx, queryLockTodoState, id).Scan(&projectID, &isCompleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while locking todo: %w", err)
+	}
+
+	if !force && todo.IsCompleted && !isCompleted {
+		if err = checkBlockers(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
+	tag, err := tx.Exec(ctx, queryUpdateTodo, todo.Name, todo.Description, todo.IsCompleted, id, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err = checkVersion(tag); err != nil {
+		return err
+	}
+	if todo.IsCompleted && !isCompleted {
+		if err = store.spawnNext(ctx, t, tx, id); err != nil {
+			return err
+		}
+	}
+
+	after, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, storage.ActionUpdate, before, after); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Move changes the column and the position of the todo in one transaction.
+// The todo is placed right after move.AfterID, right before move.BeforeID or,
+// when both are zero, at the end of the target column. Moving into a done
+// column completes the todo, moving out of it clears the completion.
+// Completing a recurring todo spawns its next occurrence
+func (store *todoStorage) Move(ctx context.Context, id uuid.UUID, move *model.TodoMoveDTO) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	todo, err := store.move(ctx, t, tx, id, move)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing move: %w", err)
+	}
+	return todo, nil
+}
+
+// move is Move within tx
+func (store *todoStorage) move(ctx context.Context, t tenant, tx pgx.Tx, id uuid.UUID, move *model.TodoMoveDTO) (*model.TodoDTO, error) {
+	var (
+		projectID, sourceID    uuid.UUID
+		sourceIsDone, archived bool
+	)
+	err := t.QueryRow(ctx, tx, queryLockTodoColumn, id).Scan(&projectID, &sourceID, &sourceIsDone, &archived)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking todo: %w", err)
+	}
+	if archived {
+		return nil, errors2.ErrArchived
+	}
+
+	column, err := lockColumn(ctx, t, tx, move.Column, projectID)
+	if err != nil {
+		return nil, err
+	}
+	columnID := column.ID
+	if !move.Force && columnID != sourceID {
+		if err = checkWipLimit(ctx, tx, column, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var prev, next string
+	switch {
+	case move.AfterID != uuid.Nil:
+		prev, err = store.positionInColumn(ctx, tx, move.AfterID, columnID)
+		if err == nil {
+			err = tx.QueryRow(ctx, queryGetNextPosition, columnID, id, prev).Scan(&next)
+		}
+	case move.BeforeID != uuid.Nil:
+		next, err = store.positionInColumn(ctx, tx, move.BeforeID, columnID)
+		if err == nil {
+			err = tx.QueryRow(ctx, queryGetPrevPosition, columnID, id, next).Scan(&prev)
+		}
+	default:
+		err = tx.QueryRow(ctx, queryGetLastPosition, columnID, id).Scan(&prev)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := rank.BetweenChecked(prev, next)
+	if err != nil {
+		return nil, fmt.Errorf("error while ranking todo: %w", err)
+	}
+
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	isCompleted := before.IsCompleted
+	spawn := false
+	if column.IsDone != sourceIsDone {
+		if !move.Force && column.IsDone && !isCompleted {
+			if err = checkBlockers(ctx, tx, id); err != nil {
+				return nil, err
+			}
+		}
+		spawn = column.IsDone && !isCompleted
+		isCompleted = column.IsDone
+	}
+
+	if _, err = tx.Exec(ctx, queryMoveTodo, columnID, position, isCompleted, id); err != nil {
+		return nil, fmt.Errorf("error while moving todo: %w", err)
+	}
+	if spawn {
+		if err = store.spawnNext(ctx, t, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	todo, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, storage.ActionMove, before, todo); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// Patch changes only the fields set in patch. A new column or project puts
+// the todo at the end of the target column, which must not have reached its
+// WIP limit unless patch.Force is set. The target project must be active,
+// not archived, in the same organization and owned by userID, and a todo
+// linked by dependencies can not leave its project. Completion follows the
+// target column like in Move
+func (store *todoStorage) Patch(ctx context.Context, id uuid.UUID, userID uuid.UUID, patch *model.TodoPatchDTO) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		projectID, sourceID    uuid.UUID
+		sourceIsDone, archived bool
+	)
+	err = t.QueryRow(ctx, tx, queryLockTodoColumn, id).Scan(&projectID, &sourceID, &sourceIsDone, &archived)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking todo: %w", err)
+	}
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+
+	todo := *before
+	if patch.Name != nil {
+		todo.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		todo.Description = *patch.Description
+	}
+	if patch.Labels != nil {
+		todo.Labels = model.NormalizeLabels(*patch.Labels)
+	}
+	if patch.IsCompleted != nil {
+		todo.IsCompleted = *patch.IsCompleted
+	}
+
+	if patch.ProjectID != nil && *patch.ProjectID != projectID {
+		if patch.Column == nil {
+			return nil, errors2.ErrProjectColumn
+		}
+		if len(before.BlockedBy) > 0 || len(before.Blocks) > 0 {
+			return nil, errors2.ErrBadDependency
+		}
+		project, err := lockProject(ctx, t, tx, *patch.ProjectID)
+		if errors.Is(err, errors2.ErrNotFound) {
+			return nil, errors2.ErrProjectTarget
+		}
+		if err != nil {
+			return nil, err
+		}
+		if project.CreatedBy != userID {
+			return nil, errors2.ErrNotAccessible
+		}
+		if project.ArchivedAt != nil {
+			return nil, errors2.ErrArchived
+		}
+		todo.ProjectID = project.ID
+	}
+
+	if patch.Column != nil && (*patch.Column != before.Column || todo.ProjectID != projectID) {
+		if archived {
+			return nil, errors2.ErrArchived
+		}
+		column, err := lockColumn(ctx, t, tx, *patch.Column, todo.ProjectID)
+		if errors.Is(err, errors2.ErrNotFound) {
+			return nil, errors2.ErrColumnTarget
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !patch.Force {
+			if err = checkWipLimit(ctx, tx, column, id); err != nil {
+				return nil, err
+			}
+		}
+		var last string
+		if err = tx.QueryRow(ctx, queryGetLastPosition, column.ID, id).Scan(&last); err != nil {
+			return nil, err
+		}
+		todo.ColumnID = column.ID
+		todo.Position = rank.After(last)
+		if column.IsDone != sourceIsDone {
+			if patch.IsCompleted != nil && *patch.IsCompleted != column.IsDone {
+				return nil, errors2.ErrCompletionConflict
+			}
+			todo.IsCompleted = column.IsDone
+		}
+	}
+
+	if !patch.Force && todo.IsCompleted && !before.IsCompleted {
+		if err = checkBlockers(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, queryPatchTodo, todo.Name, todo.Description, todo.IsCompleted, todo.Labels, todo.ProjectID, todo.ColumnID, todo.Position, id, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return nil, errors2.ErrAlreadyExists
+		}
+		return nil, fmt.Errorf("error while patching todo: %w", err)
+	}
+	if err = checkVersion(tag); err != nil {
+		return nil, err
+	}
+	if todo.IsCompleted && !before.IsCompleted {
+		if err = store.spawnNext(ctx, t, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	action := storage.ActionUpdate
+	if after.ColumnID != before.ColumnID {
+		action = storage.ActionMove
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, action, before, after); err != nil {
+		return nil, err
+	}
+	// the target project gets its own record of the todo coming in
+	if after.ProjectID != projectID {
+		if err = logActivity(ctx, tx, after.ProjectID, storage.EntityTodo, id, action, before, after); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing patch: %w", err)
+	}
+	return after, nil
+}
+
+// AddDependency records that blockerID blocks blockedID. Both todos must
+// belong to the same project, and a link that would close a cycle is
+// rejected with ErrDependencyCycle
+func (store *todoStorage) AddDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error {
+	if blockedID == blockerID {
+		return errors2.ErrBadDependency
+	}
+
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var blockedProject, blockerProject uuid.UUID
+	err = t.QueryRow(ctx, tx, queryGetTodoProject, blockedID).Scan(&blockedProject)
+	if err == nil {
+		err = t.QueryRow(ctx, tx, queryGetTodoProject, blockerID).Scan(&blockerProject)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while getting todos: %w", err)
+	}
+	if blockedProject != blockerProject {
+		return errors2.ErrBadDependency
+	}
+
+	// dependency writes of one project are serialized, otherwise two
+	// concurrent links could close a cycle that neither of them sees
+	if _, err = tx.Exec(ctx, queryLockProjectDependencies, blockedProject); err != nil {
+		return fmt.Errorf("error while locking dependencies: %w", err)
+	}
+
+	var cycle bool
+	if err = tx.QueryRow(ctx, queryDependencyPath, blockedID, blockerID).Scan(&cycle); err != nil {
+		return fmt.Errorf("error while checking dependency cycle: %w", err)
+	}
+	if cycle {
+		return errors2.ErrDependencyCycle
+	}
+
+	if _, err = tx.Exec(ctx, queryAddDependency, blockerID, blockedID); err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *todoStorage) RemoveDependency(ctx context.Context, blockedID uuid.UUID, blockerID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := t.Exec(ctx, store.pool, queryRemoveDependency, blockerID, blockedID)
 	if err != nil {
 		return err
 	}
+	if commandTag.RowsAffected() == 0 {
+		return errors2.ErrNotFound
+	}
+	return nil
+}
+
+// spawnNext creates the next occurrence of the completed todo at the end of
+// the first column of its project. It does nothing for todos without a
+// recurrence rule, and a todo spawns at most one occurrence, so completing it
+// again after reopening is harmless. The WIP limit of the first column is
+// not checked, the occurrence is created by the system, not by a user
+func (store *todoStorage) spawnNext(ctx context.Context, t tenant, tx pgx.Tx, id uuid.UUID) error {
+	var (
+		next model.TodoDTO
+		due  *time.Time
+	)
+	err := tx.QueryRow(ctx, queryGetRecurringTodo, id).Scan(&next.Name, &next.Description, &next.CreatedBy, &next.ProjectID, &next.Recurrence, &due)
+	if err != nil {
+		return fmt.Errorf("error while getting recurring todo: %w", err)
+	}
+	if next.Recurrence == "" {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(next.Recurrence)
+	if err != nil {
+		return err
+	}
+	now := store.clock.Now()
+	prev := now
+	if due != nil {
+		prev = *due
+	}
+	nextDue := rule.NextAfter(prev, now)
 
+	var columnIsDone bool
+	err = tx.QueryRow(ctx, queryLockFirstColumn, next.ProjectID).Scan(&next.ColumnID, &columnIsDone)
+	if err != nil {
+		return fmt.Errorf("error while locking first column: %w", err)
+	}
+	var last string
+	if err = tx.QueryRow(ctx, queryGetLastPosition, next.ColumnID, id).Scan(&last); err != nil {
+		return fmt.Errorf("error while getting last position: %w", err)
+	}
+
+	next.ID = uuid.New()
+	commandTag, err := tx.Exec(ctx, querySpawnTodo,
+		next.ID,
+		recurrence.OccurrenceName(next.Name, nextDue),
+		next.Description,
+		columnIsDone,
+		next.CreatedBy,
+		next.ProjectID,
+		next.ColumnID,
+		rank.After(last),
+		next.Recurrence,
+		nextDue,
+		id,
+	)
+	if err != nil {
+		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
+			return errors2.ErrAlreadyExists
+		}
+		return fmt.Errorf("error while spawning next occurrence: %w", err)
+	}
 	if commandTag.RowsAffected() == 0 {
+		return nil
+	}
+
+	spawned, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, next.ID))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
+	return logActivity(ctx, tx, next.ProjectID, storage.EntityTodo, next.ID, storage.ActionCreate, nil, spawned)
+}
+
+// checkBlockers returns ErrBlocked when the todo has incomplete blockers
+func checkBlockers(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	var count int
+	if err := tx.QueryRow(ctx, queryCountOpenBlockers, id).Scan(&count); err != nil {
+		return fmt.Errorf("error while counting blockers: %w", err)
+	}
+	if count > 0 {
+		return errors2.ErrBlocked
+	}
+	return nil
+}
+
+// checkWipLimit returns ErrWipLimitReached when the locked column can not
+// take one more todo. id is the todo being added, it is not counted
+func checkWipLimit(ctx context.Context, tx pgx.Tx, column *model.ColumDTO, id uuid.UUID) error {
+	if column.WipLimit == nil {
+		return nil
+	}
+	var count int
+	if err := tx.QueryRow(ctx, queryCountColumnTodos, column.ID, id).Scan(&count); err != nil {
+		return fmt.Errorf("error while counting column todos: %w", err)
+	}
+	if count >= *column.WipLimit {
+		return errors2.ErrWipLimitReached
+	}
+	return nil
+}
+
+func (store *todoStorage) positionInColumn(ctx context.Context, tx pgx.Tx, id, columnID uuid.UUID) (string, error) {
+	var position string
+	err := tx.QueryRow(ctx, queryGetTodoInColumn, id, columnID).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errors2.ErrBadPosition
+	}
+	return position, err
+}
+
+// Delete moves the todo to the trash
+func (store *todoStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = store.delete(ctx, t, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// delete is Delete within tx
+func (store *todoStorage) delete(ctx context.Context, t tenant, tx pgx.Tx, id uuid.UUID) error {
+	var projectID uuid.UUID
+	err := t.QueryRow(ctx, tx, queryLockTodoState, id).Scan(&projectID, new(bool))
+	if errors.Is(err, pgx.ErrNoRows) {
 		return errors2.ErrNotFound
 	}
+	if err != nil {
+		return fmt.Errorf("error while locking todo: %w", err)
+	}
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
 
+	tag, err := tx.Exec(ctx, queryDeleteTodo, id, storage.ExpectedVersionFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err = checkVersion(tag); err != nil {
+		return err
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, storage.ActionDelete, before, nil); err != nil {
+		return err
+	}
 	return nil
 }
+
+// Bulk applies the operations on behalf of userID in one transaction, every
+// operation in its own savepoint. Only the creator of a todo and the owner of
+// its project may change it. When atomic is set the first failure rolls back
+// the whole transaction and the rest of the operations is skipped, otherwise
+// a failed operation is rolled back alone and the others are committed
+func (store *todoStorage) Bulk(ctx context.Context, userID uuid.UUID, ops []model.TodoBulkOperation, atomic bool) ([]model.TodoBulkResult, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]model.TodoBulkResult, len(ops))
+	for i := range ops {
+		results[i] = model.TodoBulkResult{TodoID: ops[i].TodoID, Op: ops[i].Op, Status: model.BulkStatusSkipped}
+	}
+
+	for i := range ops {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error while creating savepoint: %w", err)
+		}
+		todo, err := store.applyBulk(ctx, t, savepoint, userID, &ops[i])
+		if err == nil {
+			err = savepoint.Commit(ctx)
+		}
+		if err != nil {
+			if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+				return nil, fmt.Errorf("error while rolling back savepoint: %w", rollbackErr)
+			}
+			results[i].Status = model.BulkStatusFailed
+			results[i].Err = err
+			if atomic {
+				for j := 0; j < i; j++ {
+					results[j].Status = model.BulkStatusRolledBack
+					results[j].Todo = nil
+				}
+				return results, nil
+			}
+			continue
+		}
+		results[i].Status = model.BulkStatusOK
+		results[i].Todo = todo
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing bulk: %w", err)
+	}
+	return results, nil
+}
+
+// applyBulk checks that userID may change the todo and applies the operation
+// within tx. It returns the changed todo, nil when the todo was deleted
+func (store *todoStorage) applyBulk(ctx context.Context, t tenant, tx pgx.Tx, userID uuid.UUID, op *model.TodoBulkOperation) (*model.TodoDTO, error) {
+	var allowed bool
+	err := t.QueryRow(ctx, tx, queryCanChangeTodo, op.TodoID, userID).Scan(&allowed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while checking access to todo: %w", err)
+	}
+	if !allowed {
+		return nil, errors2.ErrNotAccessible
+	}
+
+	switch op.Op {
+	case model.BulkComplete:
+		todo, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, op.TodoID))
+		if err != nil {
+			return nil, errors2.ErrGetByID
+		}
+		if todo.IsCompleted {
+			return todo, nil
+		}
+		todo.IsCompleted = true
+		if err = store.update(ctx, t, tx, todo, op.TodoID, op.Force); err != nil {
+			return nil, err
+		}
+	case model.BulkMove:
+		return store.move(ctx, t, tx, op.TodoID, &model.TodoMoveDTO{Column: op.Column, Force: op.Force})
+	case model.BulkDelete:
+		return nil, store.delete(ctx, t, tx, op.TodoID)
+	case model.BulkRelabel:
+		err = store.change(ctx, t, tx, op.TodoID, queryRelabelTodo, model.NormalizeLabels(op.Labels), op.TodoID)
+	case model.BulkAssign:
+		// todos are assigned to members of the organization only
+		if op.AssigneeID != nil {
+			var member bool
+			if err = t.QueryRow(ctx, tx, queryIsOrganizationMember, *op.AssigneeID).Scan(&member); err != nil {
+				return nil, fmt.Errorf("error while checking assignee: %w", err)
+			}
+			if !member {
+				return nil, errors2.ErrAssigneeNotFound
+			}
+		}
+		err = store.change(ctx, t, tx, op.TodoID, queryAssignTodo, op.AssigneeID, op.TodoID)
+		if errors.As(err, &store.pgErr) && pgerrcode.ForeignKeyViolation == store.pgErr.Code {
+			return nil, errors2.ErrAssigneeNotFound
+		}
+	default:
+		return nil, errors2.ErrBadBulkOperation
+	}
+	if err != nil {
+		return nil, err
+	}
+	todo, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, op.TodoID))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	return todo, nil
+}
+
+// change locks the todo, runs query with args and logs the update
+func (store *todoStorage) change(ctx context.Context, t tenant, tx pgx.Tx, id uuid.UUID, query string, args ...any) error {
+	var projectID uuid.UUID
+	err := t.QueryRow(ctx, tx, queryLockTodoState, id).Scan(&projectID, new(bool))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while locking todo: %w", err)
+	}
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+	after, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return errors2.ErrGetByID
+	}
+	return logActivity(ctx, tx, projectID, storage.EntityTodo, id, storage.ActionUpdate, before, after)
+}
+
+// Archive takes the todo out of its column or, when archived is false, puts
+// it back at the end of the column. A todo whose column was deleted meanwhile
+// goes to the column with the same name or else the first one. Comments,
+// checklist and activity of the todo are kept. Like Restore, unarchiving does
+// not check the WIP limit
+func (store *todoStorage) Archive(ctx context.Context, id uuid.UUID, archived bool) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var projectID uuid.UUID
+	err = t.QueryRow(ctx, tx, queryLockTodoState, id).Scan(&projectID, new(bool))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking todo: %w", err)
+	}
+	before, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	if archived == (before.ArchivedAt != nil) {
+		return before, nil
+	}
+
+	var (
+		position string
+		columnID uuid.UUID
+	)
+	if !archived {
+		if columnID, err = backColumn(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		var last string
+		if err = tx.QueryRow(ctx, queryGetLastPosition, columnID, id).Scan(&last); err != nil {
+			return nil, fmt.Errorf("error while getting last position: %w", err)
+		}
+		position = rank.After(last)
+	}
+	if _, err = tx.Exec(ctx, queryArchiveTodo, id, archived, position, columnID); err != nil {
+		return nil, fmt.Errorf("error while archiving todo: %w", err)
+	}
+
+	todo, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	action := storage.ActionArchive
+	if !archived {
+		action = storage.ActionUnarchive
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, action, before, todo); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing archive: %w", err)
+	}
+	return todo, nil
+}
+
+// GetTrash returns deleted todos of the user in the organization of ctx, most
+// recently deleted first. Todos of deleted projects are left out
+func (store *todoStorage) GetTrash(ctx context.Context, createdBy uuid.UUID) ([]model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.TodoDTO
+
+	rows, err := t.Query(ctx, store.pool, queryGetTrashedTodos, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying trashed todos: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		temp, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning todos: %w", err)
+		}
+		res = append(res, *temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// Restore takes the todo out of the trash and puts it at the end of its
+// column, or of the column Archive would pick when its column was deleted.
+// The WIP limit of the column is not checked
+func (store *todoStorage) Restore(ctx context.Context, id uuid.UUID) (*model.TodoDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var projectID uuid.UUID
+	err = t.QueryRow(ctx, tx, queryLockTrashedTodo, id).Scan(&projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while locking todo: %w", err)
+	}
+	columnID, err := backColumn(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var last string
+	if err = tx.QueryRow(ctx, queryGetLastPosition, columnID, id).Scan(&last); err != nil {
+		return nil, fmt.Errorf("error while getting last position: %w", err)
+	}
+	if _, err = tx.Exec(ctx, queryRestoreTodo, id, rank.After(last), columnID); err != nil {
+		return nil, fmt.Errorf("error while restoring todo: %w", err)
+	}
+
+	todo, err := scanTodo(t.QueryRow(ctx, tx, queryTodoGetByID, id))
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	if err = logActivity(ctx, tx, projectID, storage.EntityTodo, id, storage.ActionRestore, nil, todo); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing restore: %w", err)
+	}
+	return todo, nil
+}
+
+// backColumn returns the column the locked todo goes back to when it is
+// restored or unarchived, see queryGetBackColumn. ErrColumnTarget is returned
+// when the todo lost its column and the project has none left
+func backColumn(ctx context.Context, tx pgx.Tx, id uuid.UUID) (uuid.UUID, error) {
+	var columnID *uuid.UUID
+	if err := tx.QueryRow(ctx, queryGetBackColumn, id).Scan(&columnID); err != nil {
+		return uuid.Nil, fmt.Errorf("error while getting todo column: %w", err)
+	}
+	if columnID == nil {
+		return uuid.Nil, errors2.ErrColumnTarget
+	}
+	return *columnID, nil
+}
+
+// Purge deletes for good the todos that were deleted before deletedBefore
+// and returns how many were removed
+func (store *todoStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	commandTag, err := store.pool.Exec(ctx, queryPurgeTodos, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging todos: %w", err)
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+// scanTodo scans a row selected with todoSelect
+func scanTodo(row pgx.Row) (*model.TodoDTO, error) {
+	var todo model.TodoDTO
+	err := row.Scan(&todo.ID, &todo.Name, &todo.Description, &todo.IsCompleted, &todo.CreatedBy, &todo.ProjectID, &todo.ColumnID, &todo.Column, &todo.Position, &todo.Recurrence, &todo.DueDate, &todo.Progress, &todo.BlockedBy, &todo.Blocks, &todo.Labels, &todo.AssigneeID, &todo.DeletedAt, &todo.ArchivedAt, &todo.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
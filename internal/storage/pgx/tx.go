@@ -0,0 +1,232 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+const (
+	// maxTxAttempts is how many times WithTx runs a transaction that fails
+	// to serialize
+	maxTxAttempts = 5
+	txBackoff     = 10 * time.Millisecond
+)
+
+// db is what storages run queries on: the pool, or the transaction of
+// WithTx. Begin on a transaction starts a savepoint, so storages that open
+// transactions of their own nest into the one of WithTx
+type db interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// WithTx runs fn in one serializable transaction and commits it when fn
+// returns nil. The transaction is run again, up to maxTxAttempts times, when
+// it fails to serialize or deadlocks, so fn must have no other side effects.
+// WithTx of tx starts a savepoint and leaves retrying to the outer WithTx
+func (s *Storage) WithTx(ctx context.Context, fn func(tx storage.Interface) error) error {
+	if s.tx != nil {
+		savepoint, err := s.tx.savepoint(ctx)
+		if err != nil {
+			return fmt.Errorf("error while starting savepoint: %w", err)
+		}
+		return s.runTx(ctx, savepoint, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+		if err != nil {
+			return fmt.Errorf("error while starting transaction: %w", err)
+		}
+		wrapped := &txDB{Tx: tx, retry: new(atomic.Bool)}
+		err = s.runTx(ctx, wrapped, fn)
+		if err == nil {
+			s.router.wrote(ctx)
+			return nil
+		}
+		if attempt == maxTxAttempts || !(wrapped.retry.Load() || isRetryable(err)) {
+			return err
+		}
+
+		s.log.Info("retrying transaction", zap.Int("attempt", attempt), zap.Error(err))
+		// jitter keeps the conflicting transactions from meeting again
+		backoff := txBackoff<<(attempt-1) + rand.N(txBackoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (s *Storage) runTx(ctx context.Context, tx *txDB, fn func(tx storage.Interface) error) error {
+	defer tx.Rollback(ctx)
+	if err := fn(s.bind(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// bind returns a copy of the storage running every query in tx
+func (s *Storage) bind(tx *txDB) *Storage {
+	bound := *s
+	bound.tx = tx
+
+	user := *s.user
+	user.pool = tx
+	bound.user = &user
+
+	org := *s.org
+	org.pool = tx
+	bound.org = &org
+
+	project := *s.project
+	project.pool = tx
+	bound.project = &project
+
+	todo := *s.todo
+	todo.pool = tx
+	bound.todo = &todo
+
+	checklist := *s.checklist
+	checklist.pool = tx
+	bound.checklist = &checklist
+
+	comment := *s.comment
+	comment.pool = tx
+	bound.comment = &comment
+
+	attachment := *s.attachment
+	attachment.pool = tx
+	bound.attachment = &attachment
+
+	column := *s.column
+	column.pool = tx
+	column.todos = &todo
+	bound.column = &column
+
+	template := *s.template
+	template.pool = tx
+	bound.template = &template
+
+	activity := *s.activity
+	activity.pool = tx
+	bound.activity = &activity
+
+	idempotency := *s.idempotency
+	idempotency.pool = tx
+	bound.idempotency = &idempotency
+
+	webhook := *s.webhook
+	webhook.pool = tx
+	bound.webhook = &webhook
+
+	outbox := *s.outbox
+	outbox.pool = tx
+	bound.outbox = &outbox
+
+	return &bound
+}
+
+// txDB is the transaction of WithTx. It remembers that a query failed to
+// serialize, because storages may replace the error of a query with their own
+type txDB struct {
+	pgx.Tx
+	retry *atomic.Bool
+}
+
+func (tx *txDB) check(err error) error {
+	if isRetryable(err) {
+		tx.retry.Store(true)
+	}
+	return err
+}
+
+func (tx *txDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tx.savepoint(ctx)
+}
+
+func (tx *txDB) savepoint(ctx context.Context) (*txDB, error) {
+	savepoint, err := tx.Tx.Begin(ctx)
+	if err != nil {
+		return nil, tx.check(err)
+	}
+	return &txDB{Tx: savepoint, retry: tx.retry}, nil
+}
+
+func (tx *txDB) Commit(ctx context.Context) error {
+	return tx.check(tx.Tx.Commit(ctx))
+}
+
+func (tx *txDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tag, err := tx.Tx.Exec(ctx, sql, arguments...)
+	return tag, tx.check(err)
+}
+
+func (tx *txDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := tx.Tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, tx.check(err)
+	}
+	return &txRows{Rows: rows, tx: tx}, nil
+}
+
+func (tx *txDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := tx.Query(ctx, sql, args...)
+	return &txRow{rows: rows, err: err}
+}
+
+type txRows struct {
+	pgx.Rows
+	tx *txDB
+}
+
+func (r *txRows) Err() error {
+	return r.tx.check(r.Rows.Err())
+}
+
+// txRow is pgx.Row of txDB and the router, it scans the first row like pgx
+// does
+type txRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *txRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// isRetryable reports whether err is a serialization failure or a deadlock,
+// the transaction may succeed when it is run again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		(pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected)
+}
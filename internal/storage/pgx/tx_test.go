@@ -0,0 +1,269 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// errHidden stands for the errors storages return instead of the one of the
+// query
+var errHidden = errors.New("could not update")
+
+// raiseSerializationFailure fails like a transaction that can not be
+// serialized
+const raiseSerializationFailure = `DO $$ BEGIN RAISE EXCEPTION 'conflict' USING ERRCODE = 'serialization_failure'; END $$;`
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		retry bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: pgerrcode.SerializationFailure}, retry: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: pgerrcode.DeadlockDetected}, retry: true},
+		{name: "wrapped", err: fmt.Errorf("error while updating: %w", &pgconn.PgError{Code: pgerrcode.SerializationFailure}), retry: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgerrcode.UniqueViolation}},
+		{name: "other error", err: errHidden},
+		{name: "no error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &txDB{retry: new(atomic.Bool)}
+			if err := tx.check(tt.err); err != tt.err {
+				t.Fatalf("got %v, want the error passed through", err)
+			}
+			if tx.retry.Load() != tt.retry {
+				t.Fatalf("got retry %t, want %t", tx.retry.Load(), tt.retry)
+			}
+			savepoint := &txDB{retry: tx.retry}
+			savepoint.check(&pgconn.PgError{Code: pgerrcode.SerializationFailure})
+			if !tx.retry.Load() {
+				t.Fatal("got a failure in a savepoint not marking the transaction for retry")
+			}
+		})
+	}
+}
+
+// newTable creates a table of counters for the test
+func newTable(t *testing.T, store *Storage, ids ...int) string {
+	t.Helper()
+	ctx := context.Background()
+	table := pgx.Identifier{"tx_test_" + uuid.NewString()[:8]}.Sanitize()
+	if _, err := store.pool.Exec(ctx, `CREATE TABLE `+table+` (id INT PRIMARY KEY, n INT NOT NULL DEFAULT 0)`); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = store.pool.Exec(context.Background(), `DROP TABLE `+table)
+	})
+	for _, id := range ids {
+		if _, err := store.pool.Exec(ctx, `INSERT INTO `+table+` (id) VALUES ($1)`, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return table
+}
+
+// txOf returns the transaction the storage of WithTx runs in
+func txOf(s storage.Interface) *txDB {
+	return s.(*Storage).tx
+}
+
+func counters(t *testing.T, store *Storage, table string) map[int]int {
+	t.Helper()
+	rows, err := store.pool.Query(context.Background(), `SELECT id, n FROM `+table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := map[int]int{}
+	var id, n int
+	_, err = pgx.ForEachRow(rows, []any{&id, &n}, func() error {
+		res[id] = n
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// TestWithTxRetriesHiddenFailure checks that a transaction is run again
+// when a query failed to serialize, also when fn returns another error
+func TestWithTxRetriesHiddenFailure(t *testing.T) {
+	store := newTestStore(t)
+	table := newTable(t, store, 1)
+	ctx := context.Background()
+
+	runs := 0
+	err := store.WithTx(ctx, func(tx storage.Interface) error {
+		runs++
+		if _, err := txOf(tx).Exec(ctx, `UPDATE `+table+` SET n = n + 1 WHERE id = 1`); err != nil {
+			return errHidden
+		}
+		if runs == 1 {
+			if _, err := txOf(tx).Exec(ctx, raiseSerializationFailure); err != nil {
+				return errHidden
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Fatalf("got %d runs, want the failed one run again", runs)
+	}
+	if got := counters(t, store, table)[1]; got != 1 {
+		t.Fatalf("got counter %d, want the update of the failed run rolled back", got)
+	}
+}
+
+func TestWithTxGivesUp(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	runs := 0
+	err := store.WithTx(ctx, func(tx storage.Interface) error {
+		runs++
+		_, err := txOf(tx).Exec(ctx, raiseSerializationFailure)
+		return err
+	})
+	if !isRetryable(err) {
+		t.Fatalf("got %v, want the serialization failure", err)
+	}
+	if runs != maxTxAttempts {
+		t.Fatalf("got %d runs, want %d", runs, maxTxAttempts)
+	}
+}
+
+func TestWithTxDoesNotRetryOtherErrors(t *testing.T) {
+	store := newTestStore(t)
+
+	runs := 0
+	err := store.WithTx(context.Background(), func(storage.Interface) error {
+		runs++
+		return errHidden
+	})
+	if !errors.Is(err, errHidden) || runs != 1 {
+		t.Fatalf("got %v after %d runs, want %v after 1", err, runs, errHidden)
+	}
+}
+
+// TestWithTxWriteSkew runs two transactions that each read both counters and
+// write one of them. Serialized they see each other, so one of them has to
+// run again
+func TestWithTxWriteSkew(t *testing.T) {
+	store := newTestStore(t)
+	table := newTable(t, store, 1, 2)
+	ctx := context.Background()
+
+	var (
+		read sync.WaitGroup
+		done sync.WaitGroup
+		runs atomic.Int32
+		errs = make([]error, 2)
+	)
+	read.Add(2)
+	for i, id := range []int{1, 2} {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			first := true
+			errs[i] = store.WithTx(ctx, func(tx storage.Interface) error {
+				runs.Add(1)
+				var sum int
+				if err := txOf(tx).QueryRow(ctx, `SELECT sum(n) FROM `+table).Scan(&sum); err != nil {
+					return errHidden
+				}
+				// both read before either writes, the first time only
+				if first {
+					first = false
+					read.Done()
+					read.Wait()
+				}
+				if _, err := txOf(tx).Exec(ctx, `UPDATE `+table+` SET n = $1 WHERE id = $2`, sum+1, id); err != nil {
+					return errHidden
+				}
+				return nil
+			})
+		}()
+	}
+	done.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs.Load() < 3 {
+		t.Fatalf("got %d runs, want a transaction run again", runs.Load())
+	}
+	// run one after the other, the second sees the counter of the first
+	got := counters(t, store, table)
+	values := []int{got[1], got[2]}
+	slices.Sort(values)
+	if !slices.Equal(values, []int{1, 2}) {
+		t.Fatalf("got counters %v, want 1 and 2", got)
+	}
+}
+
+// TestWithTxSavepoint checks that a failed nested WithTx rolls back to its
+// savepoint and leaves the outer transaction usable
+func TestWithTxSavepoint(t *testing.T) {
+	store := newTestStore(t)
+	table := newTable(t, store)
+	ctx := context.Background()
+	insert := func(tx storage.Interface, id int) error {
+		_, err := txOf(tx).Exec(ctx, `INSERT INTO `+table+` (id) VALUES ($1)`, id)
+		return err
+	}
+
+	err := store.WithTx(ctx, func(tx storage.Interface) error {
+		if err := insert(tx, 1); err != nil {
+			return err
+		}
+		err := tx.WithTx(ctx, func(nested storage.Interface) error {
+			if err := insert(nested, 2); err != nil {
+				return err
+			}
+			// fails and would abort the whole transaction without the
+			// savepoint
+			return insert(nested, 1)
+		})
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+			return fmt.Errorf("got %v from the nested transaction, want a unique violation", err)
+		}
+
+		if err = tx.WithTx(ctx, func(nested storage.Interface) error {
+			return insert(nested, 3)
+		}); err != nil {
+			return err
+		}
+		return insert(tx, 4)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := counters(t, store, table)
+	ids := make([]int, 0, len(got))
+	for id := range got {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []int{1, 3, 4}) {
+		t.Fatalf("got rows %v, want 1, 3 and 4", ids)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/todo-enjoers/backend_v1/internal/model"
 	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
 	"github.com/todo-enjoers/backend_v1/internal/storage"
@@ -18,12 +17,12 @@ import (
 var _ storage.UserStorage = (*userStorage)(nil)
 
 type userStorage struct {
-	pool  *pgxpool.Pool
+	pool  db
 	log   *zap.Logger
 	pgErr *pgconn.PgError
 }
 
-func newUserStorage(pool *pgxpool.Pool, log *zap.Logger, pgErr *pgconn.PgError) (*userStorage, error) {
+func newUserStorage(pool db, log *zap.Logger, pgErr *pgconn.PgError) (*userStorage, error) {
 	store := &userStorage{
 		pool:  pool,
 		log:   log,
@@ -43,20 +42,32 @@ func (store *userStorage) migrate() error {
 	return nil
 }
 
+// Create inserts the user together with their personal organization, which
+// has the id of the user
 func (store *userStorage) Create(ctx context.Context, user *model.UserDTO) error {
-	_, err := store.pool.Exec(ctx, queryInsertInto, user.ID, user.Login, user.Password)
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, queryInsertInto, user.ID, user.Login, user.Password)
 	if err != nil {
 		if errors.As(err, &store.pgErr) && pgerrcode.UniqueViolation == store.pgErr.Code {
 			return errors2.ErrAlreadyExists
 		}
 		return errors2.ErrInserting
 	}
-	return nil
+	personal := &model.OrganizationDTO{ID: user.ID, Name: user.Login, CreatedBy: user.ID}
+	if err = createOrganization(ctx, tx, personal); err != nil {
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
 }
 
 func (store *userStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.UserDTO, error) {
 	u := new(model.UserDTO)
-	err := store.pool.QueryRow(ctx, queryGetByID, id).Scan(&u.ID, &u.Login, &u.Password)
+	err := readDB(ctx, store.pool).QueryRow(ctx, queryGetByID, id).Scan(&u.ID, &u.Login, &u.Password)
 	if err != nil {
 		return nil, errors2.ErrGetByID
 	}
@@ -76,28 +87,3 @@ func (store *userStorage) ChangePassword(ctx context.Context, password string, i
 	_, err := store.pool.Exec(ctx, queryUpdatePassword, password, id)
 	return err
 }
-
-func (store *userStorage) GetAll(ctx context.Context) ([]model.UserDTO, error) {
-	var res []model.UserDTO
-	rows, err := store.pool.Query(ctx, queryGetAllUsers)
-	if err != nil {
-		return nil, fmt.Errorf("error while querying all users: %w", err)
-	}
-
-	defer rows.Close()
-
-	for rows.Next() {
-		var temp model.UserDTO
-		err = rows.Scan(&temp.ID, &temp.Login)
-		if err != nil {
-			return nil, fmt.Errorf("error while scanning users: %w", err)
-		}
-		res = append(res, temp)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("unwrapped error: %w", err)
-	}
-
-	return res, nil
-}
@@ -0,0 +1,16 @@
+package pgx
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+)
+
+// checkVersion turns a conditional update of a locked row that changed
+// nothing into ErrVersionMismatch. The row exists, so only the expected
+// version from storage.WithExpectedVersion can have filtered it out
+func checkVersion(tag pgconn.CommandTag) error {
+	if tag.RowsAffected() == 0 {
+		return errors2.ErrVersionMismatch
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads go to the primary database. It is
+// meant for a client that wrote lately, maybe through another instance of the
+// server, so that it reads its writes while the replicas catch up
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryFromContext reports whether reads of ctx must go to the primary, see
+// WithPrimary
+func PrimaryFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ActivityStorage" implements the structure "activityStorage"
+var _ storage.ActivityStorage = (*activityStorage)(nil)
+
+// activityStorage reads the activity log. Records are written by the other
+// storages with logActivity inside their own transactions
+type activityStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// GetByProject returns a page of activity of the project, newest first, and
+// the total number of its records
+func (store *activityStorage) GetByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]model.ActivityDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		res   []model.ActivityDTO
+		total int
+	)
+	if err := t.QueryRow(ctx, store.pool, queryCountActivity, projectID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error while counting activity: %w", err)
+	}
+
+	rows, err := t.Query(ctx, store.pool, queryGetActivity, projectID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while querying activity: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error while scanning activity: %w", err)
+		}
+		res = append(res, *a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, total, nil
+}
+
+// GetSince returns up to limit records of the project added after the record
+// afterID, oldest first
+func (store *activityStorage) GetSince(ctx context.Context, projectID uuid.UUID, afterID int64, limit int) ([]model.ActivityDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ActivityDTO
+	rows, err := t.Query(ctx, store.pool, queryGetActivitySince, projectID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying activity: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning activity: %w", err)
+		}
+		res = append(res, *a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, nil
+}
+
+// LastID returns the id of the newest record of the project, zero when it
+// has none
+func (store *activityStorage) LastID(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if err := t.QueryRow(ctx, store.pool, queryGetLastActivityID, projectID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error while getting last activity: %w", err)
+	}
+	return id, nil
+}
+
+func scanActivity(r row) (*model.ActivityDTO, error) {
+	var a model.ActivityDTO
+	err := r.Scan(&a.ID, &a.ProjectID, &a.ActorID, &a.Entity, &a.EntityID, &a.Action, asJSON(&a.Changes), &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// logActivity records a change of an entity of the project in tx. before is
+// nil for created entities and after is nil for deleted ones. The actor is
+// taken from ctx, see storage.WithActor. Updates that change nothing are not
+// recorded. The record is also queued for the webhooks of the project and
+// written to the outbox as a domain event
+func logActivity(ctx context.Context, tx *txDB, projectID uuid.UUID, entity string, entityID uuid.UUID, action string, before, after any) error {
+	changes, err := storage.DiffSnapshots(before, after)
+	if err != nil {
+		return fmt.Errorf("error while diffing %s: %w", entity, err)
+	}
+	if len(changes) == 0 && action == storage.ActionUpdate {
+		return nil
+	}
+
+	var actorID *uuid.UUID
+	if id, ok := storage.ActorFromContext(ctx); ok {
+		actorID = &id
+	}
+
+	activity := model.ActivityDTO{
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	}
+	res, err := tx.Exec(ctx, queryAddActivity, projectID, actorID, entity, entityID, action, asJSON(changes), activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error while logging activity: %w", err)
+	}
+	if activity.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("error while logging activity: %w", err)
+	}
+
+	if err = enqueueWebhooks(ctx, tx, &activity); err != nil {
+		return err
+	}
+	if err = addDomainEvent(ctx, tx, &activity, after); err != nil {
+		return err
+	}
+	tx.notify(projectID)
+	return nil
+}
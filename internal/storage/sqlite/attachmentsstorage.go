@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "AttachmentStorage" implements the structure "attachmentStorage"
+var _ storage.AttachmentStorage = (*attachmentStorage)(nil)
+
+// attachmentStorage keeps metadata of files attached to todos, the files
+// themselves are kept by a blob.BlobStore
+type attachmentStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+func (store *attachmentStorage) Create(ctx context.Context, attachment *model.AttachmentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := t.Exec(ctx, store.pool, queryCreateAttachment,
+		attachment.ID,
+		attachment.TodoID,
+		attachment.UploadedBy,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		now,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors2.ErrNotFound
+		}
+		return errors2.ErrInserting
+	}
+	if err = checkFound(res, errors2.ErrNotFound); err != nil {
+		return err
+	}
+	attachment.CreatedAt = now
+	return nil
+}
+
+func (store *attachmentStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := scanAttachment(t.QueryRow(ctx, store.pool, queryGetAttachmentByID, id, todoID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+func (store *attachmentStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.AttachmentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.AttachmentDTO
+	rows, err := t.Query(ctx, store.pool, queryGetAttachments, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		temp, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning attachments: %w", err)
+		}
+		res = append(res, *temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, nil
+}
+
+func (store *attachmentStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, queryDeleteAttachment, id, todoID)
+	if err != nil {
+		return err
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
+
+// Purge deletes the attachments of the todos and projects trashed before
+// deletedBefore and returns the keys of their blobs. Rows and keys go in one
+// statement, so a todo restored meanwhile keeps both its rows and blobs
+func (store *attachmentStorage) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	rows, err := store.pool.Query(ctx, queryPurgeAttachments, deletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error while purging attachments: %w", err)
+	}
+	keys, err := collectRows[string](rows)
+	if err != nil {
+		return nil, fmt.Errorf("error while purging attachments: %w", err)
+	}
+	return keys, nil
+}
+
+func scanAttachment(r row) (*model.AttachmentDTO, error) {
+	var a model.AttachmentDTO
+	err := r.Scan(&a.ID, &a.TodoID, &a.UploadedBy, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/rank"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "ChecklistStorage" implements the structure "checklistStorage"
+var _ storage.ChecklistStorage = (*checklistStorage)(nil)
+
+// checklistStorage keeps checklist items of todos. Items are deleted
+// together with their todo
+type checklistStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// Create appends the item to the end of the checklist of its todo
+func (store *checklistStorage) Create(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = t.QueryRow(ctx, tx, queryLockTodo, item.TodoID).Scan(new(uuid.UUID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors2.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while locking todo: %w", err)
+	}
+
+	var last string
+	if err = tx.QueryRow(ctx, queryGetLastItemPosition, item.TodoID).Scan(&last); err != nil {
+		return errors2.ErrInserting
+	}
+	item.Position = rank.After(last)
+
+	_, err = tx.Exec(ctx, queryCreateItem, item.ID, item.TodoID, item.Text, item.IsDone, item.Position)
+	if err != nil {
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *checklistStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item := new(model.ChecklistItemDTO)
+	err = t.QueryRow(ctx, store.pool, queryGetItemByID, id, todoID).Scan(&item.ID, &item.TodoID, &item.Text, &item.IsDone, &item.Position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting checklist item: %w", err)
+	}
+	return item, nil
+}
+
+func (store *checklistStorage) GetAll(ctx context.Context, todoID uuid.UUID) ([]model.ChecklistItemDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.ChecklistItemDTO
+	rows, err := t.Query(ctx, store.pool, queryGetAllItems, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying checklist items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.ChecklistItemDTO
+		err = rows.Scan(&temp.ID, &temp.TodoID, &temp.Text, &temp.IsDone, &temp.Position)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning checklist items: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, nil
+}
+
+func (store *checklistStorage) Update(ctx context.Context, item *model.ChecklistItemDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, queryUpdateItem, item.Text, item.IsDone, item.ID, item.TodoID)
+	if err != nil {
+		return err
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
+
+func (store *checklistStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, queryDeleteItem, id, todoID)
+	if err != nil {
+		return err
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
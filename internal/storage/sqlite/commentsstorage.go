@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/mention"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "CommentStorage" implements the structure "commentStorage"
+var _ storage.CommentStorage = (*commentStorage)(nil)
+
+// commentStorage keeps comments on todos. Deleted comments stay in the table
+// with deleted_at set and are hidden from every query
+type commentStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// Create inserts the comment and records the users mentioned in its body
+func (store *commentStorage) Create(ctx context.Context, comment *model.CommentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	res, err := t.Exec(ctx, tx, queryCreateComment, comment.ID, comment.TodoID, comment.AuthorID, comment.Body, now)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors2.ErrNotFound
+		}
+		return errors2.ErrInserting
+	}
+	if err = checkFound(res, errors2.ErrNotFound); err != nil {
+		return err
+	}
+	comment.CreatedAt, comment.UpdatedAt = now, now
+
+	if comment.Mentions, err = store.saveMentions(ctx, t, tx, comment); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *commentStorage) GetByID(ctx context.Context, id uuid.UUID, todoID uuid.UUID) (*model.CommentDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := scanComment(t.QueryRow(ctx, store.pool, queryGetCommentByID, id, todoID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting comment: %w", err)
+	}
+	return comment, nil
+}
+
+// GetAll returns a page of comments of the todo, oldest first, and the total
+// number of its comments
+func (store *commentStorage) GetAll(ctx context.Context, todoID uuid.UUID, limit, offset int) ([]model.CommentDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		res   []model.CommentDTO
+		total int
+	)
+	if err := t.QueryRow(ctx, store.pool, queryCountComments, todoID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error while counting comments: %w", err)
+	}
+
+	rows, err := t.Query(ctx, store.pool, queryGetComments, todoID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while querying comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		temp, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error while scanning comments: %w", err)
+		}
+		res = append(res, *temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, total, nil
+}
+
+// Update changes the body of the comment and its mentions
+func (store *commentStorage) Update(ctx context.Context, comment *model.CommentDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	res, err := t.Exec(ctx, tx, queryUpdateComment, comment.Body, comment.ID, comment.TodoID, now)
+	if err != nil {
+		return fmt.Errorf("error while updating comment: %w", err)
+	}
+	if err = checkFound(res, errors2.ErrNotFound); err != nil {
+		return err
+	}
+	comment.UpdatedAt = now
+
+	if _, err = tx.Exec(ctx, queryClearMentions, comment.ID); err != nil {
+		return fmt.Errorf("error while clearing mentions: %w", err)
+	}
+	if comment.Mentions, err = store.saveMentions(ctx, t, tx, comment); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete soft deletes the comment
+func (store *commentStorage) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, querySoftDeleteComment, id, todoID, time.Now())
+	if err != nil {
+		return err
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
+
+// saveMentions resolves @login mentions of the body to user ids and records
+// them. Logins of unknown users and of users outside of the organization are
+// ignored
+func (store *commentStorage) saveMentions(ctx context.Context, t tenant, tx *txDB, comment *model.CommentDTO) ([]uuid.UUID, error) {
+	logins := mention.Parse(comment.Body)
+	if len(logins) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	rows, err := t.Query(ctx, tx, queryResolveLogins, asJSON(logins))
+	if err != nil {
+		return nil, fmt.Errorf("error while resolving mentions: %w", err)
+	}
+	ids, err := collectRows[uuid.UUID](rows)
+	if err != nil {
+		return nil, fmt.Errorf("error while scanning mentions: %w", err)
+	}
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+
+	if _, err = tx.Exec(ctx, queryAddMentions, comment.ID, asJSON(ids)); err != nil {
+		return nil, fmt.Errorf("error while saving mentions: %w", err)
+	}
+	return ids, nil
+}
+
+// scanComment scans a row selected with commentSelect
+func scanComment(r row) (*model.CommentDTO, error) {
+	var comment model.CommentDTO
+	err := r.Scan(&comment.ID, &comment.TodoID, &comment.AuthorID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt, asJSON(&comment.Mentions))
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
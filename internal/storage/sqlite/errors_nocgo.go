@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package sqlite
+
+// The driver needs cgo. Without it the server still builds for Postgres,
+// opening a SQLite database fails with an error of the driver
+
+func isUniqueViolation(error) bool {
+	return false
+}
+
+func isForeignKeyViolation(error) bool {
+	return false
+}
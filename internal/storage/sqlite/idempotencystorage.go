@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "IdempotencyStorage" implements the structure "idempotencyStorage"
+var _ storage.IdempotencyStorage = (*idempotencyStorage)(nil)
+
+type idempotencyStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// Acquire takes the key of the record for a new request and returns nil. When
+// the key is taken it returns the stored response to replay, or
+// ErrIdempotencyInProgress while the first request is running, or
+// ErrIdempotencyKeyReuse when the key was sent with another request
+func (store *idempotencyStorage) Acquire(ctx context.Context, record *model.IdempotencyRecordDTO) (*model.IdempotencyRecordDTO, error) {
+	err := store.pool.QueryRow(ctx, queryAcquireIdempotencyKey, record.UserID, record.OrgID, record.Key, record.Fingerprint, record.ExpiresAt, time.Now()).Scan(new(uuid.UUID))
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error while acquiring idempotency key: %w", err)
+	}
+
+	var stored model.IdempotencyRecordDTO
+	err = store.pool.QueryRow(ctx, queryGetIdempotencyKey, record.UserID, record.OrgID, record.Key).Scan(
+		&stored.UserID, &stored.OrgID, &stored.Key, &stored.Fingerprint, &stored.Status, &stored.ContentType, &stored.Body, &stored.ExpiresAt,
+	)
+	// the key was released in between, the client may retry
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting idempotency key: %w", err)
+	}
+	if stored.Fingerprint != record.Fingerprint {
+		return nil, errors2.ErrIdempotencyKeyReuse
+	}
+	if stored.Status == 0 {
+		return nil, errors2.ErrIdempotencyInProgress
+	}
+	return &stored, nil
+}
+
+// Complete stores the response of the request that acquired the key
+func (store *idempotencyStorage) Complete(ctx context.Context, record *model.IdempotencyRecordDTO) error {
+	_, err := store.pool.Exec(ctx, queryCompleteIdempotencyKey, record.UserID, record.OrgID, record.Key, record.Status, record.ContentType, record.Body)
+	if err != nil {
+		return fmt.Errorf("error while completing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release frees a key whose request failed before a response was stored
+func (store *idempotencyStorage) Release(ctx context.Context, record *model.IdempotencyRecordDTO) error {
+	_, err := store.pool.Exec(ctx, queryReleaseIdempotencyKey, record.UserID, record.OrgID, record.Key)
+	if err != nil {
+		return fmt.Errorf("error while releasing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the keys that expired before expiredBefore
+func (store *idempotencyStorage) Purge(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	res, err := store.pool.Exec(ctx, queryPurgeIdempotencyKeys, expiredBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
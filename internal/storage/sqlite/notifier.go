@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/events"
+)
+
+// Checking whether the interface "events.Notifier" implements the structure "Notifier"
+var _ events.Notifier = (*Notifier)(nil)
+
+// Notifier reports projects that got activity in the storage. SQLite has no
+// LISTEN, so only changes made through this process are seen
+type Notifier struct {
+	hub *hub
+}
+
+func NewNotifier(store *Storage) *Notifier {
+	return &Notifier{hub: store.pool.hub}
+}
+
+func (n *Notifier) Listen(ctx context.Context, notify func(projectID uuid.UUID)) error {
+	l := &listener{
+		pending: make(map[uuid.UUID]struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	n.hub.mu.Lock()
+	n.hub.listeners[l] = struct{}{}
+	n.hub.mu.Unlock()
+	defer func() {
+		n.hub.mu.Lock()
+		delete(n.hub.listeners, l)
+		n.hub.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.wake:
+		}
+		for _, projectID := range l.take() {
+			notify(projectID)
+		}
+	}
+}
+
+// hub hands projects changed by committed transactions to the listeners
+type hub struct {
+	mu        sync.Mutex
+	listeners map[*listener]struct{}
+}
+
+func newHub() *hub {
+	return &hub{listeners: make(map[*listener]struct{})}
+}
+
+func (h *hub) notify(projectIDs []uuid.UUID) {
+	if len(projectIDs) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for l := range h.listeners {
+		l.add(projectIDs)
+	}
+}
+
+// listener collects the projects to report until Listen takes them, repeated
+// notifications of a project are merged
+type listener struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID]struct{}
+	wake    chan struct{}
+}
+
+func (l *listener) add(projectIDs []uuid.UUID) {
+	l.mu.Lock()
+	for _, projectID := range projectIDs {
+		l.pending[projectID] = struct{}{}
+	}
+	l.mu.Unlock()
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (l *listener) take() []uuid.UUID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	projectIDs := make([]uuid.UUID, 0, len(l.pending))
+	for projectID := range l.pending {
+		projectIDs = append(projectIDs, projectID)
+	}
+	clear(l.pending)
+	return projectIDs
+}
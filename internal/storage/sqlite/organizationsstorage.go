@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "OrganizationStorage" implements the structure "organizationStorage"
+var _ storage.OrganizationStorage = (*organizationStorage)(nil)
+
+type organizationStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// Create creates the organization owned by the user that created it
+func (store *organizationStorage) Create(ctx context.Context, org *model.OrganizationDTO) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = createOrganization(ctx, tx, org); err != nil {
+		if isUniqueViolation(err) {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *organizationStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.OrganizationDTO, error) {
+	org := new(model.OrganizationDTO)
+	err := store.pool.QueryRow(ctx, queryGetOrganization, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting organization: %w", err)
+	}
+	return org, nil
+}
+
+// GetByMember returns the organizations of the user with their role in each
+func (store *organizationStorage) GetByMember(ctx context.Context, userID uuid.UUID) ([]model.OrganizationDTO, error) {
+	var res []model.OrganizationDTO
+	rows, err := store.pool.Query(ctx, queryGetOrganizationsByMember, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying organizations: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.OrganizationDTO
+		err = rows.Scan(&temp.ID, &temp.Name, &temp.CreatedBy, &temp.CreatedAt, &temp.Role)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning organizations: %w", err)
+		}
+		res = append(res, temp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// GetMember returns the membership of the user in the organization, it is
+// how the organization of a request is checked before it is set as the tenant
+func (store *organizationStorage) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*model.OrganizationMemberDTO, error) {
+	member, err := scanMember(store.pool.QueryRow(ctx, queryGetOrganizationMember, orgID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting member: %w", err)
+	}
+	return member, nil
+}
+
+// GetMembers returns the members of the organization of ctx ordered by login
+func (store *organizationStorage) GetMembers(ctx context.Context) ([]model.OrganizationMemberDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.OrganizationMemberDTO
+	rows, err := t.Query(ctx, store.pool, queryGetOrganizationMembers)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying members: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning members: %w", err)
+		}
+		res = append(res, *member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return res, nil
+}
+
+// AddMember adds the user to the organization of ctx
+func (store *organizationStorage) AddMember(ctx context.Context, member *model.OrganizationMemberDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	joinedAt := time.Now()
+	if _, err = t.Exec(ctx, store.pool, queryAddOrganizationMember, member.UserID, member.Role, joinedAt); err != nil {
+		if isUniqueViolation(err) {
+			return errors2.ErrAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return errors2.ErrNotFound
+		}
+		return errors2.ErrInserting
+	}
+	member.OrgID = t.orgID
+	member.JoinedAt = joinedAt
+	return nil
+}
+
+// UpdateMemberRole changes the role of the member of the organization of ctx.
+// The organization keeps at least one owner, and the owner of a personal
+// organization stays its owner
+func (store *organizationStorage) UpdateMemberRole(ctx context.Context, userID uuid.UUID, role string) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMember(ctx, t, tx, userID)
+	if err != nil {
+		return err
+	}
+	if current == model.OrgRoleOwner && role != model.OrgRoleOwner {
+		if err = leaveOwners(ctx, t, tx, userID); err != nil {
+			return err
+		}
+	}
+	if _, err = t.Exec(ctx, tx, queryUpdateOrganizationMemberRole, userID, role); err != nil {
+		return fmt.Errorf("error while updating member: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveMember removes the user from the organization of ctx. The last owner
+// and the owner of a personal organization can't be removed
+func (store *organizationStorage) RemoveMember(ctx context.Context, userID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMember(ctx, t, tx, userID)
+	if err != nil {
+		return err
+	}
+	if userID == t.orgID {
+		return errors2.ErrPersonalOwner
+	}
+	if current == model.OrgRoleOwner {
+		if err = leaveOwners(ctx, t, tx, userID); err != nil {
+			return err
+		}
+	}
+	if _, err = t.Exec(ctx, tx, queryRemoveOrganizationMember, userID); err != nil {
+		return fmt.Errorf("error while removing member: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// createOrganization inserts the organization and makes its creator the
+// owner. The personal organization of a user is created with it
+func createOrganization(ctx context.Context, tx *txDB, org *model.OrganizationDTO) error {
+	createdAt := time.Now()
+	if _, err := tx.Exec(ctx, queryCreateOrganization, org.ID, org.Name, org.CreatedBy, createdAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, queryAddOrganizationOwner, org.ID, org.CreatedBy, createdAt); err != nil {
+		return err
+	}
+	org.CreatedAt = createdAt
+	org.Role = model.OrgRoleOwner
+	return nil
+}
+
+// lockMember returns the role of the user in the organization of t, the
+// transaction holds the write lock of the database
+func lockMember(ctx context.Context, t tenant, tx *txDB, userID uuid.UUID) (string, error) {
+	var role string
+	err := t.QueryRow(ctx, tx, queryLockOrganizationMember, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors2.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error while locking member: %w", err)
+	}
+	return role, nil
+}
+
+// leaveOwners checks that the owner may stop owning the organization of t
+func leaveOwners(ctx context.Context, t tenant, tx *txDB, userID uuid.UUID) error {
+	if userID == t.orgID {
+		return errors2.ErrPersonalOwner
+	}
+	var owners int
+	if err := t.QueryRow(ctx, tx, queryCountOrganizationOwners).Scan(&owners); err != nil {
+		return fmt.Errorf("error while counting owners: %w", err)
+	}
+	if owners <= 1 {
+		return errors2.ErrLastOwner
+	}
+	return nil
+}
+
+func scanMember(r row) (*model.OrganizationMemberDTO, error) {
+	member := new(model.OrganizationMemberDTO)
+	if err := r.Scan(&member.OrgID, &member.UserID, &member.Login, &member.Role, &member.JoinedAt); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "OutboxStorage" implements the structure "outboxStorage"
+var _ storage.OutboxStorage = (*outboxStorage)(nil)
+
+type outboxStorage struct {
+	pool db
+	log  *zap.Logger
+	// relay is held by the relay publishing events, it stands in for the
+	// advisory lock of Postgres. Only one process opens the database file
+	relay *sync.Mutex
+}
+
+// Publish hands up to limit unpublished events, oldest first, to publish and
+// marks them published when it succeeds. Only one relay publishes at a time,
+// it returns zero when another one is busy or when the oldest event waits
+// for its next attempt, so that events are never published out of order.
+// Events are read outside of a transaction, so that writers are not blocked
+// while they are published
+func (store *outboxStorage) Publish(ctx context.Context, limit int, publish func(ctx context.Context, events []model.DomainEventDTO) error) (int, error) {
+	if !store.relay.TryLock() {
+		return 0, nil
+	}
+	defer store.relay.Unlock()
+
+	events, err := getUnpublishedEvents(ctx, store.pool, limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 || events[0].NextAttemptAt.After(time.Now()) {
+		return 0, nil
+	}
+
+	if err = publish(ctx, events); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	if _, err = store.pool.Exec(ctx, queryMarkEventsPublished, asJSON(ids), time.Now()); err != nil {
+		return 0, fmt.Errorf("error while marking events published: %w", err)
+	}
+	return len(events), nil
+}
+
+// Fail records a failed attempt to publish the event, the relay tries again
+// from it at nextAttemptAt
+func (store *outboxStorage) Fail(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	if _, err := store.pool.Exec(ctx, queryFailOutboxEvent, id, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("error while recording outbox failure: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the events published before publishedBefore and returns how
+// many were removed
+func (store *outboxStorage) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	res, err := store.pool.Exec(ctx, queryPurgeOutboxEvents, publishedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging outbox: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func getUnpublishedEvents(ctx context.Context, pool db, limit int) ([]model.DomainEventDTO, error) {
+	var res []model.DomainEventDTO
+	rows, err := pool.Query(ctx, queryGetUnpublishedEvents, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying outbox: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.DomainEventDTO
+		err = rows.Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.ProjectID, &e.ActorID,
+			asJSON(&e.Changes), asJSON(&e.State), &e.OccurredAt, &e.Attempts, &e.NextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning outbox: %w", err)
+		}
+		res = append(res, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, nil
+}
+
+// addDomainEvent writes the domain event of the activity record to the
+// outbox in tx. after is the state of the entity after the change
+func addDomainEvent(ctx context.Context, tx *txDB, activity *model.ActivityDTO, after any) error {
+	state, err := storage.Snapshot(after)
+	if err != nil {
+		return fmt.Errorf("error while encoding %s: %w", activity.Entity, err)
+	}
+	var stateJSON any
+	if state != nil {
+		stateJSON = asJSON(state)
+	}
+	_, err = tx.Exec(ctx, queryAddOutboxEvent,
+		model.DomainEventType(activity.Entity, activity.Action, activity.Changes),
+		activity.Entity, activity.EntityID, activity.ProjectID, activity.ActorID,
+		asJSON(activity.Changes), stateJSON, activity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while writing domain event: %w", err)
+	}
+	return nil
+}
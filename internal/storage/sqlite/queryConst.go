@@ -0,0 +1,540 @@
+package sqlite
+
+// The queries follow the ones of the pgx storage. Transactions hold the
+// write lock of the database from their start, so the "lock" queries read
+// rows without FOR UPDATE and advisory locks are not needed. Times are bound
+// by the storages instead of now(), and arrays are JSON text read with
+// json_each
+
+// subqueries of the projects and todos of the tenant, for tenant queries of
+// rows that belong to them
+const (
+	tenantProjects = `SELECT id FROM projects WHERE org_id = $org`
+	tenantTodos    = `SELECT t.id FROM todos AS t JOIN projects AS p ON p.id = t.project_id WHERE p.org_id = $org`
+	tenantWebhooks = `SELECT w.id FROM webhooks AS w JOIN projects AS p ON p.id = w.project_id WHERE p.org_id = $org`
+)
+
+// query for Organizations Storage
+const (
+	queryCreateOrganization = `INSERT INTO organizations (id, name, created_by, created_at) VALUES (?1, ?2, ?3, ?4);`
+
+	queryAddOrganizationOwner = `INSERT INTO organization_members (org_id, user_id, role, joined_at) VALUES (?1, ?2, 'owner', ?3);`
+
+	queryGetOrganization = `SELECT id, name, created_by, created_at FROM organizations WHERE id = ?1;`
+
+	queryGetOrganizationsByMember = `SELECT o.id, o.name, o.created_by, o.created_at, m.role
+FROM organizations AS o
+JOIN organization_members AS m ON m.org_id = o.id
+WHERE m.user_id = ?1
+ORDER BY o.name, o.id;`
+
+	organizationMemberSelect = `SELECT m.org_id, m.user_id, u.login, m.role, m.joined_at
+FROM organization_members AS m
+JOIN users AS u ON u.id = m.user_id
+`
+
+	queryGetOrganizationMember = organizationMemberSelect + `WHERE m.org_id = ?1 AND m.user_id = ?2;`
+
+	queryGetOrganizationMembers tenantQuery = organizationMemberSelect + `WHERE m.org_id = $org
+ORDER BY u.login;`
+
+	queryAddOrganizationMember tenantQuery = `INSERT INTO organization_members (org_id, user_id, role, joined_at) VALUES ($org, ?1, ?2, ?3);`
+
+	queryLockOrganizationMember tenantQuery = `SELECT role FROM organization_members WHERE org_id = $org AND user_id = ?1;`
+
+	queryIsOrganizationMember tenantQuery = `SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $org AND user_id = ?1);`
+
+	queryCountOrganizationOwners tenantQuery = `SELECT count(*) FROM organization_members WHERE org_id = $org AND role = 'owner';`
+
+	queryUpdateOrganizationMemberRole tenantQuery = `UPDATE organization_members SET role = ?2
+WHERE org_id = $org AND user_id = ?1;`
+
+	queryRemoveOrganizationMember tenantQuery = `DELETE FROM organization_members
+WHERE org_id = $org AND user_id = ?1;`
+)
+
+// query for Projects Storage
+const (
+	queryCreateProjects tenantQuery = `INSERT INTO projects (id, name, created_by, org_id) VALUES (?1, ?2, ?3, $org);`
+
+	queryGetMyProjectsByName tenantQuery = `SELECT p.id, p.name, p.created_by
+FROM projects AS p
+WHERE name = ?1 AND created_by = ?2 AND org_id = $org AND deleted_at IS NULL;`
+
+	queryGetProjectsByID tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.archived_at, p.version
+FROM projects AS p
+WHERE p.id = ?1 AND p.org_id = $org AND p.deleted_at IS NULL;`
+
+	queryGetAllProjects tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.archived_at, p.version
+FROM projects AS p
+WHERE org_id = $org AND deleted_at IS NULL AND (?1 OR archived_at IS NULL);`
+
+	queryLockProject = queryGetProjectsByID
+
+	// ?3 is the expected version, zero matches any
+	queryUpdateProjectName = `UPDATE projects SET name = ?1
+WHERE id = ?2 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3);`
+
+	queryArchiveProject = `UPDATE projects SET archived_at = ?2 WHERE id = ?1;`
+
+	queryDeleteProject = `UPDATE projects SET deleted_at = ?2
+WHERE id = ?1 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3);`
+
+	queryGetTrashedProjects tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.deleted_at
+FROM projects AS p
+WHERE created_by = ?1 AND org_id = $org AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;`
+
+	queryLockTrashedProject tenantQuery = `SELECT p.id, p.name, p.created_by, p.org_id, p.deleted_at
+FROM projects AS p
+WHERE p.id = ?1 AND p.org_id = $org AND p.deleted_at IS NOT NULL;`
+
+	queryRestoreProject = `UPDATE projects SET deleted_at = NULL WHERE id = ?1;`
+
+	// queryPurgeProjectActivity removes the activity of the projects that
+	// queryPurgeProjects removes, activity_log has no foreign keys
+	queryPurgeProjectActivity = `DELETE FROM activity_log
+WHERE project_id IN (SELECT id FROM projects WHERE deleted_at < ?1);`
+
+	// queryPurgeProjects removes projects trashed before ?1, their columns
+	// and todos go with them
+	queryPurgeProjects = `DELETE FROM projects WHERE deleted_at < ?1;`
+)
+
+// query for Todos Storage
+const (
+	queryCreateTodo = `INSERT INTO todos (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10);`
+	// queryGetRecurringTodo returns the fields an occurrence is spawned from
+	queryGetRecurringTodo = `SELECT name, description, created_by, project_id, recurrence, due_date FROM todos WHERE id = ?1;`
+	queryLockFirstColumn  = `SELECT id, is_done FROM project_columns
+WHERE project_id = ?1
+ORDER BY "order"
+LIMIT 1;`
+	// querySpawnTodo inserts the next occurrence at most once per completed
+	// occurrence, spawned_from is unique
+	querySpawnTodo = `INSERT INTO todos (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date, spawned_from)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
+ON CONFLICT (spawned_from) DO NOTHING;`
+	// todoSelect is the select list scanned by scanTodo. Progress is the share
+	// of done checklist items, a todo without items is either 0 or 100 percent.
+	// The arrays are the todos blocking this one and the todos it blocks.
+	// A todo detached from its deleted column has the nil column id and no
+	// column name. Queries built on it must filter out deleted todos and projects
+	todoSelect = `SELECT t.id, t.name, t.description, t.is_completed, t.created_by, t.project_id,
+       COALESCE(t.column_id, '00000000-0000-0000-0000-000000000000'), COALESCE(c.name, ''), t."position", t.recurrence, t.due_date,
+       (SELECT CASE
+                   WHEN count(*) = 0 THEN CASE WHEN t.is_completed THEN 100 ELSE 0 END
+                   ELSE 100 * sum(i.is_done) / count(*)
+               END
+        FROM todo_checklist_items AS i
+        WHERE i.todo_id = t.id),
+       (SELECT json_group_array(d.blocker_id ORDER BY d.blocker_id)
+        FROM todo_dependencies AS d JOIN todos AS o ON o.id = d.blocker_id
+        WHERE d.blocked_id = t.id AND o.deleted_at IS NULL),
+       (SELECT json_group_array(d.blocked_id ORDER BY d.blocked_id)
+        FROM todo_dependencies AS d JOIN todos AS o ON o.id = d.blocked_id
+        WHERE d.blocker_id = t.id AND o.deleted_at IS NULL),
+       t.labels, t.assignee_id, t.deleted_at, t.archived_at, t.version
+FROM todos AS t
+LEFT JOIN project_columns AS c ON c.id = t.column_id
+JOIN projects AS p ON p.id = t.project_id AND p.org_id = $org
+`
+	queryTodoGetByID tenantQuery = todoSelect + `WHERE t.id = ?1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL`
+	// queryGetAllTodos hides archived todos and todos of archived projects
+	// unless ?2 is true
+	queryGetAllTodos tenantQuery = todoSelect + `WHERE t.created_by = ?1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+  AND (?2 OR (t.archived_at IS NULL AND p.archived_at IS NULL))
+ORDER BY t.project_id, c."order", t."position";`
+	// queryGetTrashedTodos skips todos of deleted projects, they can not be
+	// restored before their project
+	queryGetTrashedTodos tenantQuery = todoSelect + `WHERE t.created_by = ?1 AND t.deleted_at IS NOT NULL AND p.deleted_at IS NULL
+ORDER BY t.deleted_at DESC;`
+	queryLockTodoState tenantQuery = `SELECT t.project_id, t.is_completed
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = ?1 AND t.deleted_at IS NULL AND p.org_id = $org AND p.deleted_at IS NULL;`
+	queryLockTrashedTodo tenantQuery = `SELECT t.project_id
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = ?1 AND t.deleted_at IS NOT NULL AND p.org_id = $org AND p.deleted_at IS NULL;`
+	// queryArchiveTodo archives the todo at ?2 when it is set, otherwise it
+	// puts the todo back into column ?4 at position ?3
+	queryArchiveTodo = `UPDATE todos
+SET archived_at = ?2,
+    "position" = CASE WHEN ?2 IS NOT NULL THEN "position" ELSE ?3 END,
+    column_id = CASE WHEN ?2 IS NOT NULL THEN column_id ELSE ?4 END,
+    last_column = CASE WHEN ?2 IS NOT NULL THEN last_column END
+WHERE id = ?1;`
+	// queryGetBackColumn returns the column a todo goes back to: its own one
+	// or, when it was detached from its deleted column, the column of the
+	// project with the same name or else the first one. It is NULL when the
+	// project has no columns
+	queryGetBackColumn = `SELECT COALESCE(t.column_id,
+    (SELECT c.id FROM project_columns AS c WHERE c.project_id = t.project_id AND c.name = t.last_column),
+    (SELECT c.id FROM project_columns AS c WHERE c.project_id = t.project_id ORDER BY c."order" LIMIT 1))
+FROM todos AS t
+WHERE t.id = ?1;`
+	queryRestoreTodo       = `UPDATE todos SET deleted_at = NULL, column_id = ?3, last_column = NULL, "position" = ?2 WHERE id = ?1;`
+	queryPurgeTodos        = `DELETE FROM todos WHERE deleted_at < ?1;`
+	queryCountOpenBlockers = `SELECT count(*)
+FROM todo_dependencies AS d
+JOIN todos AS b ON b.id = d.blocker_id
+WHERE d.blocked_id = ?1 AND NOT b.is_completed AND b.deleted_at IS NULL;`
+	queryGetTodoProject tenantQuery = `SELECT t.project_id
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = ?1 AND t.deleted_at IS NULL AND p.org_id = $org;`
+	// queryDependencyPath reports whether "to" is reachable from "from" by
+	// following blocker -> blocked edges
+	queryDependencyPath = `WITH RECURSIVE reachable(id) AS (
+    SELECT ?1
+    UNION
+    SELECT d.blocked_id FROM todo_dependencies AS d JOIN reachable AS r ON d.blocker_id = r.id
+)
+SELECT EXISTS (SELECT 1 FROM reachable WHERE id = ?2);`
+	queryAddDependency                = `INSERT INTO todo_dependencies (blocker_id, blocked_id) VALUES (?1, ?2);`
+	queryRemoveDependency tenantQuery = `DELETE FROM todo_dependencies
+WHERE blocker_id = ?1 AND blocked_id = ?2 AND blocked_id IN (` + tenantTodos + `);`
+	// ?5 is the expected version, zero matches any
+	queryUpdateTodo = `UPDATE todos
+SET name = ?1, description = ?2, is_completed = ?3
+WHERE id = ?4 AND (?5 = 0 OR version = ?5);`
+	queryLockTodoColumn tenantQuery = `SELECT t.project_id, COALESCE(t.column_id, '00000000-0000-0000-0000-000000000000'), COALESCE(c.is_done, FALSE), t.archived_at IS NOT NULL
+FROM todos AS t
+LEFT JOIN project_columns AS c ON c.id = t.column_id
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = ?1 AND t.deleted_at IS NULL AND p.org_id = $org;`
+	// archived and deleted todos are not part of their column: they do not
+	// count against the WIP limit and do not take positions
+	queryCountColumnTodos = `SELECT count(*) FROM todos WHERE column_id = ?1 AND id <> ?2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetTodoInColumn  = `SELECT "position" FROM todos WHERE id = ?1 AND column_id = ?2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetLastPosition  = `SELECT COALESCE(MAX("position"), '') FROM todos
+WHERE column_id = ?1 AND id <> ?2 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetNextPosition = `SELECT COALESCE(MIN("position"), '') FROM todos
+WHERE column_id = ?1 AND id <> ?2 AND "position" > ?3 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryGetPrevPosition = `SELECT COALESCE(MAX("position"), '') FROM todos
+WHERE column_id = ?1 AND id <> ?2 AND "position" < ?3 AND deleted_at IS NULL AND archived_at IS NULL;`
+	queryMoveTodo   = `UPDATE todos SET column_id = ?1, "position" = ?2, is_completed = ?3 WHERE id = ?4;`
+	queryDeleteTodo = `UPDATE todos SET deleted_at = ?2 WHERE id = ?1 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3);`
+	// the nil column id of ?6 keeps a detached todo detached
+	queryPatchTodo = `UPDATE todos
+SET name = ?1, description = ?2, is_completed = ?3, labels = ?4, project_id = ?5, column_id = NULLIF(?6, '00000000-0000-0000-0000-000000000000'), "position" = ?7
+WHERE id = ?8 AND (?9 = 0 OR version = ?9);`
+	queryRelabelTodo = `UPDATE todos SET labels = ?1 WHERE id = ?2;`
+	queryAssignTodo  = `UPDATE todos SET assignee_id = ?1 WHERE id = ?2;`
+	// queryCanChangeTodo reports whether ?2 created the todo or owns its project
+	queryCanChangeTodo tenantQuery = `SELECT t.created_by = ?2 OR p.created_by = ?2
+FROM todos AS t
+JOIN projects AS p ON p.id = t.project_id
+WHERE t.id = ?1 AND t.deleted_at IS NULL AND p.org_id = $org AND p.deleted_at IS NULL;`
+)
+
+// query for Users Storage
+const (
+	queryInsertInto = `INSERT INTO users (id, login, encrypted_password) VALUES (?1, ?2, ?3);`
+
+	queryGetByID = `SELECT u.id, u.login, u.encrypted_password
+FROM users AS u
+WHERE u.id = ?1;`
+
+	queryUpdatePassword = `UPDATE users SET encrypted_password = ?1 WHERE id = ?2;`
+
+	queryGetByLogin = `SELECT u.id, u.login, u.encrypted_password
+FROM users AS u
+WHERE u.login = ?1;`
+)
+
+// query for Columns Storage
+const (
+	queryProjectIsActive tenantQuery = `SELECT EXISTS (SELECT 1 FROM projects WHERE id = ?1 AND org_id = $org AND deleted_at IS NULL);`
+
+	queryLockProjectColumns = `SELECT name FROM project_columns WHERE project_id = ?1 ORDER BY "order";`
+
+	queryShiftColumnsRight = `UPDATE project_columns SET "order" = "order" + 1 WHERE project_id = ?1 AND "order" >= ?2;`
+
+	queryShiftColumnsLeft = `UPDATE project_columns SET "order" = "order" - 1 WHERE project_id = ?1 AND "order" > ?2;`
+
+	queryInsertColumns = `INSERT INTO project_columns (id, project_id, name, "order", wip_limit, is_done) VALUES (?1, ?2, ?3, ?4, ?5, ?6);`
+
+	// queryDeleteColumns fails on the foreign key of the todos left in the column
+	queryDeleteColumns = `DELETE FROM project_columns WHERE id = ?1;`
+
+	queryMoveColumnLast = `UPDATE project_columns SET "order" = ?2 WHERE id = ?1;`
+
+	queryGetColumnByName tenantQuery = `SELECT id, project_id, name, "order", wip_limit, is_done, version FROM project_columns
+WHERE name = ?1 AND project_id = ?2
+  AND EXISTS (SELECT 1 FROM projects AS p WHERE p.id = project_id AND p.org_id = $org AND p.deleted_at IS NULL);`
+
+	queryLockColumn = queryGetColumnByName
+
+	queryUpdateColumns = `UPDATE project_columns SET name = ?1, wip_limit = ?2, is_done = ?3
+WHERE name = ?4 AND project_id = ?5 AND (?6 = 0 OR version = ?6);`
+
+	// queryReorderColumns orders the columns as they are listed in the JSON
+	// array ?2
+	queryReorderColumns = `UPDATE project_columns
+SET "order" = (SELECT o.key FROM json_each(?2) AS o WHERE o.value = project_columns.name)
+WHERE project_id = ?1 AND name IN (SELECT value FROM json_each(?2));`
+
+	queryGetAllColumns tenantQuery = `SELECT id, project_id, name, "order", wip_limit, is_done, version FROM project_columns
+WHERE project_id = ?1
+  AND EXISTS (SELECT 1 FROM projects AS p WHERE p.id = project_id AND p.org_id = $org AND p.deleted_at IS NULL)
+ORDER BY "order";`
+
+	queryGetColumnTodos = `SELECT id FROM todos WHERE column_id = ?1 AND deleted_at IS NULL AND archived_at IS NULL ORDER BY "position";`
+
+	// queryDetachColumnTodos takes the archived and trashed todos out of the
+	// column ?1 being deleted, remembering its name ?2 to put them back
+	queryDetachColumnTodos = `UPDATE todos SET column_id = NULL, last_column = ?2
+WHERE column_id = ?1 AND (archived_at IS NOT NULL OR deleted_at IS NOT NULL);`
+)
+
+// query for Templates Storage
+const (
+	queryCreateTemplate = `INSERT INTO project_templates (id, name, created_by, columns) VALUES (?1, ?2, ?3, ?4);`
+
+	queryGetTemplateByID = `SELECT id, name, created_by, columns FROM project_templates WHERE id = ?1;`
+
+	queryGetMyTemplates = `SELECT id, name, created_by, columns FROM project_templates WHERE created_by = ?1 ORDER BY name;`
+
+	queryDeleteTemplate = `DELETE FROM project_templates WHERE id = ?1 AND created_by = ?2;`
+)
+
+// query for Checklist Storage
+const (
+	queryLockTodo tenantQuery = `SELECT id FROM todos WHERE id = ?1 AND deleted_at IS NULL AND project_id IN (` + tenantProjects + `);`
+
+	queryGetLastItemPosition = `SELECT COALESCE(MAX("position"), '') FROM todo_checklist_items WHERE todo_id = ?1;`
+
+	queryCreateItem = `INSERT INTO todo_checklist_items (id, todo_id, text, is_done, "position") VALUES (?1, ?2, ?3, ?4, ?5);`
+
+	queryGetItemByID tenantQuery = `SELECT id, todo_id, text, is_done, "position" FROM todo_checklist_items
+WHERE id = ?1 AND todo_id = ?2 AND todo_id IN (` + tenantTodos + `);`
+
+	queryGetAllItems tenantQuery = `SELECT id, todo_id, text, is_done, "position" FROM todo_checklist_items
+WHERE todo_id = ?1 AND todo_id IN (` + tenantTodos + `)
+ORDER BY "position";`
+
+	queryUpdateItem tenantQuery = `UPDATE todo_checklist_items SET text = ?1, is_done = ?2
+WHERE id = ?3 AND todo_id = ?4 AND todo_id IN (` + tenantTodos + `);`
+
+	queryDeleteItem tenantQuery = `DELETE FROM todo_checklist_items
+WHERE id = ?1 AND todo_id = ?2 AND todo_id IN (` + tenantTodos + `);`
+)
+
+// query for Comments Storage
+const (
+	// commentSelect is the select list scanned by scanComment
+	commentSelect = `SELECT m.id, m.todo_id, m.author_id, m.body, m.created_at, m.updated_at,
+       (SELECT json_group_array(cm.user_id ORDER BY cm.user_id) FROM comment_mentions AS cm WHERE cm.comment_id = m.id)
+FROM todo_comments AS m
+`
+
+	// queryCreateComment inserts nothing when the todo is missing or deleted
+	queryCreateComment tenantQuery = `INSERT INTO todo_comments (id, todo_id, author_id, body, created_at, updated_at)
+SELECT ?1, t.id, ?3, ?4, ?5, ?5 FROM todos AS t
+WHERE t.id = ?2 AND t.deleted_at IS NULL AND t.project_id IN (` + tenantProjects + `);`
+
+	queryGetCommentByID tenantQuery = commentSelect + `WHERE m.id = ?1 AND m.todo_id = ?2 AND m.deleted_at IS NULL
+  AND m.todo_id IN (` + tenantTodos + `);`
+
+	queryGetComments tenantQuery = commentSelect + `WHERE m.todo_id = ?1 AND m.deleted_at IS NULL
+  AND m.todo_id IN (` + tenantTodos + `)
+ORDER BY m.created_at, m.id
+LIMIT ?2 OFFSET ?3;`
+
+	queryCountComments tenantQuery = `SELECT count(*) FROM todo_comments
+WHERE todo_id = ?1 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `);`
+
+	queryUpdateComment tenantQuery = `UPDATE todo_comments SET body = ?1, updated_at = ?4
+WHERE id = ?2 AND todo_id = ?3 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `);`
+
+	querySoftDeleteComment tenantQuery = `UPDATE todo_comments SET deleted_at = ?3
+WHERE id = ?1 AND todo_id = ?2 AND deleted_at IS NULL AND todo_id IN (` + tenantTodos + `);`
+
+	// queryResolveLogins resolves the logins of the members of the tenant only
+	queryResolveLogins tenantQuery = `SELECT u.id FROM users AS u
+JOIN organization_members AS m ON m.user_id = u.id AND m.org_id = $org
+WHERE u.login IN (SELECT value FROM json_each(?1))
+ORDER BY u.id;`
+
+	queryClearMentions = `DELETE FROM comment_mentions WHERE comment_id = ?1;`
+
+	queryAddMentions = `INSERT INTO comment_mentions (comment_id, user_id) SELECT ?1, value FROM json_each(?2);`
+)
+
+// query for Attachments Storage
+const (
+	// queryCreateAttachment inserts nothing when the todo is missing or
+	// belongs to another organization
+	queryCreateAttachment tenantQuery = `INSERT INTO todo_attachments (id, todo_id, uploaded_by, file_name, content_type, size, storage_key, created_at)
+SELECT ?1, t.id, ?3, ?4, ?5, ?6, ?7, ?8 FROM todos AS t
+WHERE t.id = ?2 AND t.project_id IN (` + tenantProjects + `);`
+
+	queryGetAttachmentByID tenantQuery = `SELECT id, todo_id, uploaded_by, file_name, content_type, size, storage_key, created_at
+FROM todo_attachments WHERE id = ?1 AND todo_id = ?2 AND todo_id IN (` + tenantTodos + `);`
+
+	queryGetAttachments tenantQuery = `SELECT id, todo_id, uploaded_by, file_name, content_type, size, storage_key, created_at
+FROM todo_attachments WHERE todo_id = ?1 AND todo_id IN (` + tenantTodos + `)
+ORDER BY created_at, id;`
+
+	queryDeleteAttachment tenantQuery = `DELETE FROM todo_attachments
+WHERE id = ?1 AND todo_id = ?2 AND todo_id IN (` + tenantTodos + `);`
+
+	// queryPurgeAttachments removes the attachments that queryPurgeProjects
+	// and queryPurgeTodos would remove with their todos
+	queryPurgeAttachments = `DELETE FROM todo_attachments
+WHERE todo_id IN (
+    SELECT t.id FROM todos AS t
+    JOIN projects AS p ON p.id = t.project_id
+    WHERE t.deleted_at < ?1 OR p.deleted_at < ?1
+)
+RETURNING storage_key;`
+)
+
+// query for Activity Storage
+const (
+	queryAddActivity = `INSERT INTO activity_log (project_id, actor_id, entity, entity_id, action, changes, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7);`
+
+	queryGetActivity tenantQuery = `SELECT id, project_id, actor_id, entity, entity_id, action, changes, created_at
+FROM activity_log
+WHERE project_id = ?1 AND project_id IN (` + tenantProjects + `)
+ORDER BY id DESC
+LIMIT ?2 OFFSET ?3;`
+
+	queryCountActivity tenantQuery = `SELECT count(*) FROM activity_log WHERE project_id = ?1 AND project_id IN (` + tenantProjects + `);`
+
+	queryGetActivitySince tenantQuery = `SELECT id, project_id, actor_id, entity, entity_id, action, changes, created_at
+FROM activity_log
+WHERE project_id = ?1 AND id > ?2 AND project_id IN (` + tenantProjects + `)
+ORDER BY id
+LIMIT ?3;`
+
+	queryGetLastActivityID tenantQuery = `SELECT COALESCE(MAX(id), 0) FROM activity_log
+WHERE project_id = ?1 AND project_id IN (` + tenantProjects + `);`
+)
+
+// query for Idempotency Storage
+const (
+	// queryAcquireIdempotencyKey takes a new key or one expired at ?6, it
+	// returns no row when the key is taken
+	queryAcquireIdempotencyKey = `INSERT INTO idempotency_keys (user_id, org_id, key, fingerprint, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?6, ?5)
+ON CONFLICT (user_id, org_id, key) DO UPDATE
+SET fingerprint = excluded.fingerprint, status = NULL, content_type = '', body = NULL,
+    created_at = excluded.created_at, expires_at = excluded.expires_at
+WHERE idempotency_keys.expires_at <= excluded.created_at
+RETURNING user_id;`
+
+	queryGetIdempotencyKey = `SELECT user_id, org_id, key, fingerprint, COALESCE(status, 0), content_type, body, expires_at
+FROM idempotency_keys
+WHERE user_id = ?1 AND org_id = ?2 AND key = ?3;`
+
+	queryCompleteIdempotencyKey = `UPDATE idempotency_keys SET status = ?4, content_type = ?5, body = ?6
+WHERE user_id = ?1 AND org_id = ?2 AND key = ?3;`
+
+	queryReleaseIdempotencyKey = `DELETE FROM idempotency_keys WHERE user_id = ?1 AND org_id = ?2 AND key = ?3 AND status IS NULL;`
+
+	queryPurgeIdempotencyKeys = `DELETE FROM idempotency_keys WHERE expires_at < ?1;`
+)
+
+// query for Webhooks Storage
+const (
+	// queryCreateWebhook inserts nothing when the project belongs to another
+	// organization
+	queryCreateWebhook tenantQuery = `INSERT INTO webhooks (id, project_id, url, secret, events, created_by, created_at)
+SELECT ?1, p.id, ?3, ?4, ?5, ?6, ?7 FROM projects AS p
+WHERE p.id = ?2 AND p.org_id = $org;`
+
+	queryGetWebhook tenantQuery = `SELECT id, project_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE id = ?1 AND project_id = ?2 AND project_id IN (` + tenantProjects + `);`
+
+	queryGetWebhooks tenantQuery = `SELECT id, project_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE project_id = ?1 AND project_id IN (` + tenantProjects + `)
+ORDER BY created_at;`
+
+	queryDeleteWebhook tenantQuery = `DELETE FROM webhooks
+WHERE id = ?1 AND project_id = ?2 AND project_id IN (` + tenantProjects + `);`
+
+	// queryGetSubscribedWebhooks returns the webhooks of the project ?1
+	// subscribed to the event ?2
+	queryGetSubscribedWebhooks = `SELECT w.id FROM webhooks AS w
+WHERE w.project_id = ?1 AND (json_array_length(w.events) = 0 OR ?2 IN (SELECT value FROM json_each(w.events)))
+ORDER BY w.id;`
+
+	queryAddWebhookDelivery = `INSERT INTO webhook_deliveries (id, webhook_id, activity_id, event, payload, next_attempt_at, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6);`
+
+	// queryGetDueWebhookDeliveries returns up to ?2 deliveries due at ?1
+	queryGetDueWebhookDeliveries = `SELECT d.id, d.webhook_id, d.activity_id, d.event, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret
+FROM webhook_deliveries AS d
+JOIN webhooks AS w ON w.id = d.webhook_id
+WHERE d.status = 'pending' AND d.next_attempt_at <= ?1
+ORDER BY d.next_attempt_at
+LIMIT ?2;`
+
+	// queryLeaseWebhookDeliveries hides the deliveries of the JSON array ?1
+	// from other dispatchers until ?2
+	queryLeaseWebhookDeliveries = `UPDATE webhook_deliveries SET next_attempt_at = ?2
+WHERE id IN (SELECT value FROM json_each(?1));`
+
+	queryAddWebhookAttempt = `INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES (?1, ?2, ?3, ?4, ?5);`
+
+	queryUpdateWebhookDelivery = `UPDATE webhook_deliveries
+SET status = ?2, attempts = attempts + 1, next_attempt_at = ?3, last_status_code = ?4, last_error = ?5,
+    delivered_at = CASE WHEN ?2 = 'delivered' THEN ?6 END
+WHERE id = ?1;`
+
+	queryRedeliverWebhook tenantQuery = `UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = ?3
+WHERE id = ?1 AND webhook_id = ?2 AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryWebhookDeliverySelect = `SELECT id, webhook_id, activity_id, event, payload, status, attempts, next_attempt_at,
+       last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+`
+
+	queryGetWebhookDelivery tenantQuery = queryWebhookDeliverySelect + `WHERE id = ?1 AND webhook_id = ?2
+  AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryGetWebhookDeliveries tenantQuery = queryWebhookDeliverySelect + `WHERE webhook_id = ?1
+  AND webhook_id IN (` + tenantWebhooks + `)
+ORDER BY created_at DESC
+LIMIT ?2 OFFSET ?3;`
+
+	queryCountWebhookDeliveries tenantQuery = `SELECT count(*) FROM webhook_deliveries
+WHERE webhook_id = ?1 AND webhook_id IN (` + tenantWebhooks + `);`
+
+	queryGetWebhookAttempts = `SELECT delivery_id, attempted_at, status_code, error, duration_ms
+FROM webhook_delivery_attempts
+WHERE delivery_id = ?1
+ORDER BY id;`
+)
+
+// query for Outbox Storage
+const (
+	queryAddOutboxEvent = `INSERT INTO outbox_events (type, aggregate_type, aggregate_id, project_id, actor_id, changes, state, occurred_at, next_attempt_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?8);`
+
+	queryGetUnpublishedEvents = `SELECT id, type, aggregate_type, aggregate_id, project_id, actor_id, changes, state, occurred_at, attempts, next_attempt_at
+FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT ?1;`
+
+	// queryMarkEventsPublished marks the events of the JSON array ?1
+	queryMarkEventsPublished = `UPDATE outbox_events
+SET published_at = ?2, attempts = attempts + 1, last_error = ''
+WHERE id IN (SELECT value FROM json_each(?1));`
+
+	queryFailOutboxEvent = `UPDATE outbox_events
+SET attempts = attempts + 1, last_error = ?2, next_attempt_at = ?3
+WHERE id = ?1 AND published_at IS NULL;`
+
+	queryPurgeOutboxEvents = `DELETE FROM outbox_events WHERE published_at < ?1;`
+)
@@ -0,0 +1,122 @@
+// Package sqlite implements storage.Interface on a SQLite database file, so
+// that the server runs on a laptop or a Raspberry Pi without Postgres. The
+// schema is created by the migrations in migrations/sqlite, which must be run
+// before the storage is used. Queries and error semantics follow the pgx
+// storage
+package sqlite
+
+import (
+	"database/sql"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "Interface" implements the structure "Storage"
+var _ storage.Interface = (*Storage)(nil)
+
+type Storage struct {
+	pool        *database
+	log         *zap.Logger
+	user        *userStorage
+	org         *organizationStorage
+	project     *projectsStorage
+	todo        *todoStorage
+	checklist   *checklistStorage
+	comment     *commentStorage
+	attachment  *attachmentStorage
+	column      *columnStorage
+	template    *templateStorage
+	activity    *activityStorage
+	idempotency *idempotencyStorage
+	webhook     *webhookStorage
+	outbox      *outboxStorage
+	// tx is the transaction of WithTx, nil outside of it
+	tx *txDB
+}
+
+// New returns the storage of the database opened by pkg/sqlite. The
+// database must be migrated
+func New(sqlDB *sql.DB, log *zap.Logger) *Storage {
+	return NewWithClock(sqlDB, log, recurrence.SystemClock{})
+}
+
+// NewWithClock returns the storage like New does, it spawns occurrences of
+// recurring todos at the time of clock
+func NewWithClock(sqlDB *sql.DB, log *zap.Logger, clock recurrence.Clock) *Storage {
+	log = log.Named("sqlite-storage")
+	pool := &database{db: sqlDB, hub: newHub()}
+	todos := &todoStorage{pool: pool, log: log, clock: clock}
+	return &Storage{
+		pool:        pool,
+		log:         log,
+		user:        &userStorage{pool: pool, log: log},
+		org:         &organizationStorage{pool: pool, log: log},
+		project:     &projectsStorage{pool: pool, log: log},
+		todo:        todos,
+		checklist:   &checklistStorage{pool: pool, log: log},
+		comment:     &commentStorage{pool: pool, log: log},
+		attachment:  &attachmentStorage{pool: pool, log: log},
+		column:      &columnStorage{pool: pool, log: log, todos: todos},
+		template:    &templateStorage{pool: pool, log: log},
+		activity:    &activityStorage{pool: pool, log: log},
+		idempotency: &idempotencyStorage{pool: pool, log: log},
+		webhook:     &webhookStorage{pool: pool, log: log},
+		outbox:      &outboxStorage{pool: pool, log: log, relay: new(sync.Mutex)},
+	}
+}
+
+func (s *Storage) User() storage.UserStorage {
+	return s.user
+}
+
+func (s *Storage) Organization() storage.OrganizationStorage {
+	return s.org
+}
+
+func (s *Storage) Todo() storage.TodoStorage {
+	return s.todo
+}
+
+func (s *Storage) Checklist() storage.ChecklistStorage {
+	return s.checklist
+}
+
+func (s *Storage) Comment() storage.CommentStorage {
+	return s.comment
+}
+
+func (s *Storage) Attachment() storage.AttachmentStorage {
+	return s.attachment
+}
+
+func (s *Storage) Project() storage.ProjectStorage {
+	return s.project
+}
+
+func (s *Storage) Column() storage.ColumnStorage {
+	return s.column
+}
+
+func (s *Storage) Template() storage.TemplateStorage {
+	return s.template
+}
+
+func (s *Storage) Activity() storage.ActivityStorage {
+	return s.activity
+}
+
+func (s *Storage) Idempotency() storage.IdempotencyStorage {
+	return s.idempotency
+}
+
+func (s *Storage) Webhook() storage.WebhookStorage {
+	return s.webhook
+}
+
+func (s *Storage) Outbox() storage.OutboxStorage {
+	return s.outbox
+}
@@ -0,0 +1,56 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	ternsqlite "github.com/todo-enjoers/backend_v1/internal/pkg/tern/sqlite"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/storagetest"
+	migrations "github.com/todo-enjoers/backend_v1/migrations/sqlite"
+)
+
+// TestStorage runs every check on a database of its own
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return NewWithClock(newTestDB(t), zaptest.NewLogger(t), recurrence.SystemClock{})
+	})
+}
+
+func TestSpawnNextClock(t *testing.T) {
+	storagetest.RunClock(t, func(t *testing.T, clock recurrence.Clock) storage.Interface {
+		return NewWithClock(newTestDB(t), zaptest.NewLogger(t), clock)
+	})
+}
+
+// newTestDB opens a database in a temporary directory and migrates it like
+// the server does it
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	cfg := &config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "db.sqlite")}
+	db, err := sql.Open("sqlite3", cfg.GetDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	m, err := ternsqlite.NewMigrator(context.Background(), db, "schema_version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.LoadMigrations(migrations.Migrations); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
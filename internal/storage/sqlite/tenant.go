@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// orgParam stands for the organization in a tenantQuery
+const orgParam = "$org"
+
+// tenantQuery is SQL reading or changing the data of an organization, like
+// the one of the pgx storage. orgParam is bound to the parameter after the
+// arguments of the query
+type tenantQuery string
+
+// tenant runs tenant queries in the organization of a context
+type tenant struct {
+	orgID uuid.UUID
+}
+
+// tenantOf returns the tenant of the organization set by storage.WithTenant,
+// ErrNoOrganization when ctx has none
+func tenantOf(ctx context.Context) (tenant, error) {
+	orgID, ok := storage.TenantFromContext(ctx)
+	if !ok {
+		return tenant{}, errors2.ErrNoOrganization
+	}
+	return tenant{orgID: orgID}, nil
+}
+
+// bind replaces orgParam with the parameter after args and appends the
+// organization to args
+func (t tenant) bind(query tenantQuery, args []any) (string, []any) {
+	q := strings.ReplaceAll(string(query), orgParam, "?"+strconv.Itoa(len(args)+1))
+	return q, append(args[:len(args):len(args)], t.orgID)
+}
+
+func (t tenant) Exec(ctx context.Context, on db, query tenantQuery, args ...any) (sql.Result, error) {
+	q, args := t.bind(query, args)
+	return on.Exec(ctx, q, args...)
+}
+
+func (t tenant) Query(ctx context.Context, on db, query tenantQuery, args ...any) (*sql.Rows, error) {
+	q, args := t.bind(query, args)
+	return on.Query(ctx, q, args...)
+}
+
+func (t tenant) QueryRow(ctx context.Context, on db, query tenantQuery, args ...any) *sql.Row {
+	q, args := t.bind(query, args)
+	return on.QueryRow(ctx, q, args...)
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// db is what storages run queries on: the database, or the transaction of
+// WithTx. Begin on a transaction starts a savepoint, so storages that open
+// transactions of their own nest into the one of WithTx
+type db interface {
+	Begin(ctx context.Context) (*txDB, error)
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn in one transaction and commits it when fn returns nil.
+// Transactions take the write lock of the database when they begin, see
+// config.SQLiteConfig, so they never fail to serialize and fn runs once.
+// WithTx of tx starts a savepoint
+func (s *Storage) WithTx(ctx context.Context, fn func(tx storage.Interface) error) error {
+	var (
+		tx  *txDB
+		err error
+	)
+	if s.tx != nil {
+		tx, err = s.tx.Begin(ctx)
+	} else {
+		tx, err = s.pool.Begin(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+	if err = fn(s.bind(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// bind returns a copy of the storage running every query in tx
+func (s *Storage) bind(tx *txDB) *Storage {
+	bound := *s
+	bound.tx = tx
+
+	user := *s.user
+	user.pool = tx
+	bound.user = &user
+
+	org := *s.org
+	org.pool = tx
+	bound.org = &org
+
+	project := *s.project
+	project.pool = tx
+	bound.project = &project
+
+	todo := *s.todo
+	todo.pool = tx
+	bound.todo = &todo
+
+	checklist := *s.checklist
+	checklist.pool = tx
+	bound.checklist = &checklist
+
+	comment := *s.comment
+	comment.pool = tx
+	bound.comment = &comment
+
+	attachment := *s.attachment
+	attachment.pool = tx
+	bound.attachment = &attachment
+
+	column := *s.column
+	column.pool = tx
+	column.todos = &todo
+	bound.column = &column
+
+	template := *s.template
+	template.pool = tx
+	bound.template = &template
+
+	activity := *s.activity
+	activity.pool = tx
+	bound.activity = &activity
+
+	idempotency := *s.idempotency
+	idempotency.pool = tx
+	bound.idempotency = &idempotency
+
+	webhook := *s.webhook
+	webhook.pool = tx
+	bound.webhook = &webhook
+
+	outbox := *s.outbox
+	outbox.pool = tx
+	bound.outbox = &outbox
+
+	return &bound
+}
+
+// database runs queries on the connections of the database file
+type database struct {
+	db  *sql.DB
+	hub *hub
+}
+
+func (d *database) Begin(ctx context.Context) (*txDB, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &txDB{tx: tx, hub: d.hub, notified: new([]uuid.UUID), savepoints: new(int)}, nil
+}
+
+func (d *database) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, query, bindArgs(args)...)
+}
+
+func (d *database) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, query, bindArgs(args)...)
+}
+
+func (d *database) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.db.QueryRowContext(ctx, query, bindArgs(args)...)
+}
+
+// txDB is a transaction or, when savepoint is set, a savepoint of one. Like
+// a pgx.Tx, it may be rolled back after it was committed, which does nothing
+type txDB struct {
+	tx        *sql.Tx
+	hub       *hub
+	savepoint string
+	done      bool
+	// notified collects the projects with activity in the transaction, they
+	// are handed to the hub when it commits. mark is the length of notified
+	// when the savepoint started
+	notified *[]uuid.UUID
+	mark     int
+	// savepoints numbers the savepoints of the transaction
+	savepoints *int
+}
+
+func (tx *txDB) Begin(ctx context.Context) (*txDB, error) {
+	*tx.savepoints++
+	name := fmt.Sprintf("sp_%d", *tx.savepoints)
+	if _, err := tx.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &txDB{
+		tx:         tx.tx,
+		hub:        tx.hub,
+		savepoint:  name,
+		notified:   tx.notified,
+		mark:       len(*tx.notified),
+		savepoints: tx.savepoints,
+	}, nil
+}
+
+func (tx *txDB) Commit(ctx context.Context) error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	if tx.savepoint != "" {
+		_, err := tx.tx.ExecContext(ctx, "RELEASE "+tx.savepoint)
+		return err
+	}
+	if err := tx.tx.Commit(); err != nil {
+		return err
+	}
+	tx.hub.notify(*tx.notified)
+	return nil
+}
+
+func (tx *txDB) Rollback(ctx context.Context) error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	if tx.savepoint != "" {
+		*tx.notified = (*tx.notified)[:tx.mark]
+		_, err := tx.tx.ExecContext(ctx, "ROLLBACK TO "+tx.savepoint+"; RELEASE "+tx.savepoint)
+		return err
+	}
+	return tx.tx.Rollback()
+}
+
+// notify reports the project to listeners once the transaction commits, it
+// stands in for pg_notify
+func (tx *txDB) notify(projectID uuid.UUID) {
+	*tx.notified = append(*tx.notified, projectID)
+}
+
+func (tx *txDB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.tx.ExecContext(ctx, query, bindArgs(args)...)
+}
+
+func (tx *txDB) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.tx.QueryContext(ctx, query, bindArgs(args)...)
+}
+
+func (tx *txDB) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.tx.QueryRowContext(ctx, query, bindArgs(args)...)
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "UserStorage" implements the structure "userStorage"
+var _ storage.UserStorage = (*userStorage)(nil)
+
+type userStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+// Create inserts the user together with their personal organization, which
+// has the id of the user
+func (store *userStorage) Create(ctx context.Context, user *model.UserDTO) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, queryInsertInto, user.ID, user.Login, user.Password)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	personal := &model.OrganizationDTO{ID: user.ID, Name: user.Login, CreatedBy: user.ID}
+	if err = createOrganization(ctx, tx, personal); err != nil {
+		return errors2.ErrInserting
+	}
+	return tx.Commit(ctx)
+}
+
+func (store *userStorage) GetByID(ctx context.Context, id uuid.UUID) (*model.UserDTO, error) {
+	u := new(model.UserDTO)
+	err := store.pool.QueryRow(ctx, queryGetByID, id).Scan(&u.ID, &u.Login, &u.Password)
+	if err != nil {
+		return nil, errors2.ErrGetByID
+	}
+	return u, nil
+}
+
+func (store *userStorage) GetByLogin(ctx context.Context, login string) (*model.UserDTO, error) {
+	u := new(model.UserDTO)
+	err := store.pool.QueryRow(ctx, queryGetByLogin, login).Scan(&u.ID, &u.Login, &u.Password)
+	if err != nil {
+		return nil, errors2.ErrGetByLogin
+	}
+	return u, nil
+}
+
+func (store *userStorage) ChangePassword(ctx context.Context, password string, id uuid.UUID) error {
+	_, err := store.pool.Exec(ctx, queryUpdatePassword, password, id)
+	return err
+}
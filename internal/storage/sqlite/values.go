@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// timeLayout has a fixed width, so that times stored as text compare in SQL
+// like they do in Go. The driver parses it back
+const timeLayout = "2006-01-02 15:04:05.000000000-07:00"
+
+// bindArgs formats the times among args in UTC with timeLayout
+func bindArgs(args []any) []any {
+	bound := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			bound[i] = v.UTC().Format(timeLayout)
+		case *time.Time:
+			if v != nil {
+				bound[i] = v.UTC().Format(timeLayout)
+			}
+		default:
+			bound[i] = arg
+		}
+	}
+	return bound
+}
+
+// jsonValue stores v as JSON text and scans JSON text back into it, it
+// stands in for the arrays and JSONB columns of Postgres. A nil slice is
+// stored as an empty array
+type jsonValue struct {
+	v any
+}
+
+func asJSON(v any) jsonValue {
+	return jsonValue{v: v}
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	if rv := reflect.ValueOf(j.v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		return "[]", nil
+	}
+	b, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (j jsonValue) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), j.v)
+	case []byte:
+		return json.Unmarshal(src, j.v)
+	default:
+		return fmt.Errorf("can not scan %T as JSON", src)
+	}
+}
+
+// row is a *sql.Row or *sql.Rows
+type row interface {
+	Scan(dest ...any) error
+}
+
+// collectRows scans the single column of every row and closes rows
+func collectRows[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	var res []T
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, rows.Err()
+}
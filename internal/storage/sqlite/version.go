@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+)
+
+// checkVersion turns a conditional update of a row that changed nothing into
+// ErrVersionMismatch. The row was read in the transaction, so only the
+// expected version from storage.WithExpectedVersion can have filtered it out
+func checkVersion(res sql.Result) error {
+	return checkFound(res, errors2.ErrVersionMismatch)
+}
+
+// checkFound returns notFound when the statement changed no row, it stands
+// in for the RowsAffected of pgx command tags
+func checkFound(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Checking whether the interface "WebhookStorage" implements the structure "webhookStorage"
+var _ storage.WebhookStorage = (*webhookStorage)(nil)
+
+type webhookStorage struct {
+	pool db
+	log  *zap.Logger
+}
+
+func (store *webhookStorage) Create(ctx context.Context, webhook *model.WebhookDTO) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := t.Exec(ctx, store.pool, queryCreateWebhook,
+		webhook.ID, webhook.ProjectID, webhook.URL, webhook.Secret, asJSON(webhook.Events), webhook.CreatedBy, now,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors2.ErrAlreadyExists
+		}
+		return errors2.ErrInserting
+	}
+	if err = checkFound(res, errors2.ErrNotFound); err != nil {
+		return err
+	}
+	webhook.CreatedAt = now
+	return nil
+}
+
+func (store *webhookStorage) GetByID(ctx context.Context, id uuid.UUID, projectID uuid.UUID) (*model.WebhookDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := scanWebhook(t.QueryRow(ctx, store.pool, queryGetWebhook, id, projectID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (store *webhookStorage) GetAll(ctx context.Context, projectID uuid.UUID) ([]model.WebhookDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.WebhookDTO
+	rows, err := t.Query(ctx, store.pool, queryGetWebhooks, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning webhooks: %w", err)
+		}
+		res = append(res, *w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, nil
+}
+
+// Delete removes the webhook together with its deliveries
+func (store *webhookStorage) Delete(ctx context.Context, id uuid.UUID, projectID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, queryDeleteWebhook, id, projectID)
+	if err != nil {
+		return fmt.Errorf("error while deleting webhook: %w", err)
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
+
+// GetDeliveries returns a page of deliveries of the webhook, newest first,
+// and the total number of its deliveries
+func (store *webhookStorage) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]model.WebhookDeliveryDTO, int, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := t.QueryRow(ctx, store.pool, queryCountWebhookDeliveries, webhookID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error while counting deliveries: %w", err)
+	}
+
+	var res []model.WebhookDeliveryDTO
+	rows, err := t.Query(ctx, store.pool, queryGetWebhookDeliveries, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while querying deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		res = append(res, *d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return res, total, nil
+}
+
+// GetDelivery returns the delivery with the history of its attempts
+func (store *webhookStorage) GetDelivery(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) (*model.WebhookDeliveryDTO, error) {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := scanWebhookDelivery(t.QueryRow(ctx, store.pool, queryGetWebhookDelivery, id, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors2.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := store.pool.Query(ctx, queryGetWebhookAttempts, id)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying attempts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a model.WebhookAttemptDTO
+		if err = rows.Scan(&a.DeliveryID, &a.AttemptedAt, &a.StatusCode, &a.Error, &a.DurationMs); err != nil {
+			return nil, fmt.Errorf("error while scanning attempts: %w", err)
+		}
+		delivery.History = append(delivery.History, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return delivery, nil
+}
+
+// Redeliver queues the delivery again with a fresh budget of attempts, the
+// history of the previous attempts is kept
+func (store *webhookStorage) Redeliver(ctx context.Context, id uuid.UUID, webhookID uuid.UUID) error {
+	t, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.Exec(ctx, store.pool, queryRedeliverWebhook, id, webhookID, time.Now())
+	if err != nil {
+		return fmt.Errorf("error while redelivering: %w", err)
+	}
+	return checkFound(res, errors2.ErrNotFound)
+}
+
+// ClaimDue takes up to limit pending deliveries that are due and hides them
+// from other dispatchers for lease. A delivery that is not recorded within
+// the lease is claimed again
+func (store *webhookStorage) ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]model.WebhookJobDTO, error) {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	rows, err := tx.Query(ctx, queryGetDueWebhookDeliveries, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while claiming deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		res []model.WebhookJobDTO
+		ids []uuid.UUID
+	)
+	for rows.Next() {
+		var job model.WebhookJobDTO
+		d := &job.Delivery
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.ActivityID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &job.URL, &job.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning deliveries: %w", err)
+		}
+		res = append(res, job)
+		ids = append(ids, d.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if _, err = tx.Exec(ctx, queryLeaseWebhookDeliveries, asJSON(ids), now.Add(lease)); err != nil {
+		return nil, fmt.Errorf("error while claiming deliveries: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RecordAttempt logs the attempt and moves its delivery to status. A pending
+// delivery is tried again at nextAttemptAt
+func (store *webhookStorage) RecordAttempt(ctx context.Context, attempt *model.WebhookAttemptDTO, status string, nextAttemptAt *time.Time) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, queryAddWebhookAttempt,
+		attempt.DeliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error, attempt.DurationMs,
+	)
+	if err != nil {
+		return fmt.Errorf("error while recording attempt: %w", err)
+	}
+	_, err = tx.Exec(ctx, queryUpdateWebhookDelivery,
+		attempt.DeliveryID, status, nextAttemptAt, attempt.StatusCode, attempt.Error, attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while updating delivery: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// enqueueWebhooks queues the activity record in tx for every webhook of its
+// project subscribed to its event
+func enqueueWebhooks(ctx context.Context, tx *txDB, activity *model.ActivityDTO) error {
+	event := activity.Entity + "." + activity.Action
+
+	rows, err := tx.Query(ctx, queryGetSubscribedWebhooks, activity.ProjectID, event)
+	if err != nil {
+		return fmt.Errorf("error while queueing webhooks: %w", err)
+	}
+	webhooks, err := collectRows[uuid.UUID](rows)
+	if err != nil {
+		return fmt.Errorf("error while queueing webhooks: %w", err)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(model.WebhookPayload{Event: event, Activity: *activity})
+	if err != nil {
+		return fmt.Errorf("error while encoding webhook payload: %w", err)
+	}
+	for _, webhookID := range webhooks {
+		if _, err = tx.Exec(ctx, queryAddWebhookDelivery, uuid.New(), webhookID, activity.ID, event, payload, activity.CreatedAt); err != nil {
+			return fmt.Errorf("error while queueing webhooks: %w", err)
+		}
+	}
+	return nil
+}
+
+func scanWebhook(r row) (*model.WebhookDTO, error) {
+	w := new(model.WebhookDTO)
+	err := r.Scan(&w.ID, &w.ProjectID, &w.URL, &w.Secret, asJSON(&w.Events), &w.CreatedBy, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func scanWebhookDelivery(r row) (*model.WebhookDeliveryDTO, error) {
+	d := new(model.WebhookDeliveryDTO)
+	err := r.Scan(
+		&d.ID, &d.WebhookID, &d.ActivityID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while scanning delivery: %w", err)
+	}
+	return d, nil
+}
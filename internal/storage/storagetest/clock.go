@@ -0,0 +1,98 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// fakeClock is a recurrence.Clock standing still at now
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+// RunClock checks the due date of the next occurrence of a recurring todo
+// completed at the time of the clock of the storage. newStore returns a
+// storage spawning occurrences at the time of clock
+func RunClock(t *testing.T, newStore func(t *testing.T, clock recurrence.Clock) storage.Interface) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone America/New_York: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		rule string
+		due  *time.Time
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "on time",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			due:  ptr(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			now:  time.Date(2024, time.January, 1, 17, 0, 0, 0, time.UTC),
+			want: time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "late, missed occurrences are skipped",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			due:  ptr(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			now:  time.Date(2024, time.January, 17, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2024, time.January, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "late at the month end",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31",
+			due:  ptr(time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)),
+			now:  time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "without due date from today of the clock",
+			rule: "FREQ=DAILY",
+			now:  time.Date(2024, time.March, 10, 23, 30, 0, 0, newYork),
+			want: time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fixture{t: t, ctx: context.Background(), store: newStore(t, fakeClock{now: tt.now})}
+			project := f.project(f.user().ID)
+			todo := f.newTodo(project, "To do")
+			todo.Recurrence = tt.rule
+			todo.DueDate = tt.due
+			f.no(f.store.Todo().Create(f.ctx, &todo, false), "creating recurring todo")
+			// completing twice spawns once
+			for _, column := range []string{"Done", "To do", "Done"} {
+				_, err := f.store.Todo().Move(f.ctx, todo.ID, &model.TodoMoveDTO{Column: column})
+				f.no(err, "moving recurring todo to "+column)
+			}
+
+			todos, err := f.store.Todo().GetAll(f.ctx, project.CreatedBy, false)
+			f.no(err, "getting todos")
+			if len(todos) != 2 {
+				t.Fatalf("got %d todos, want the todo and one occurrence", len(todos))
+			}
+			next := todos[0]
+			if next.ID == todo.ID {
+				next = todos[1]
+			}
+			if next.DueDate == nil || !next.DueDate.Equal(tt.want) {
+				t.Fatalf("got occurrence due %v, want %s", next.DueDate, tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,251 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// farFuture purges everything in the trash
+var farFuture = time.Now().AddDate(100, 0, 0)
+
+var errAbort = errors.New("abort transaction")
+
+func testActivity(f *fixture) {
+	actor := f.user()
+	project := f.project(actor.ID)
+	ctx := storage.WithActor(f.ctx, actor.ID)
+	todo := f.newTodo(project, "To do")
+	f.no(f.store.Todo().Create(ctx, &todo, false), "creating todo")
+	since, err := f.store.Activity().LastID(f.ctx, project.ID)
+	f.no(err, "getting last activity id")
+	_, err = f.store.Todo().Move(ctx, todo.ID, &model.TodoMoveDTO{Column: "In progress"})
+	f.no(err, "moving todo")
+
+	// the project, its 3 columns, the todo and its move
+	activity, total, err := f.store.Activity().GetByProject(f.ctx, project.ID, 2, 0)
+	f.no(err, "getting activity")
+	if total != 6 || len(activity) != 2 {
+		f.t.Fatalf("got %d of %d activity records, want 2 of 6", len(activity), total)
+	}
+	move := activity[0]
+	if move.Entity != storage.EntityTodo || move.Action != storage.ActionMove || move.EntityID != todo.ID {
+		f.t.Fatalf("got latest activity %s.%s of %s, want the move of the todo", move.Entity, move.Action, move.EntityID)
+	}
+	if move.ActorID == nil || *move.ActorID != actor.ID {
+		f.t.Fatalf("got actor %v, want %s", move.ActorID, actor.ID)
+	}
+	if change, ok := move.Changes["column"]; !ok || change.Before != "To do" || change.After != "In progress" {
+		f.t.Fatalf("got changes %v, want column from %q to %q", move.Changes, "To do", "In progress")
+	}
+
+	newer, err := f.store.Activity().GetSince(f.ctx, project.ID, since, 10)
+	f.no(err, "getting activity since")
+	if len(newer) != 1 || newer[0].ID != move.ID {
+		f.t.Fatalf("got %d activity records since %d, want the move only", len(newer), since)
+	}
+	last, err := f.store.Activity().LastID(f.ctx, project.ID)
+	f.no(err, "getting last activity id")
+	if last != move.ID {
+		f.t.Fatalf("got last activity id %d, want %d", last, move.ID)
+	}
+
+	// the activity of a purged project goes with it, a project created with
+	// the same id starts without it
+	f.no(f.store.Project().Delete(f.ctx, project.ID), "deleting project")
+	_, err = f.store.Project().Purge(f.ctx, farFuture)
+	f.no(err, "purging projects")
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	f.no(f.store.Project().Create(f.ctx, &project, template.ColumnsFor(project.ID)), "creating project with id of purged one")
+	_, total, err = f.store.Activity().GetByProject(f.ctx, project.ID, 10, 0)
+	f.no(err, "getting activity")
+	if total != 4 {
+		f.t.Fatalf("got %d activity records of the new project, want the 4 of its creation", total)
+	}
+}
+
+func testIdempotency(f *fixture) {
+	user := f.user()
+	store := f.store.Idempotency()
+	record := model.IdempotencyRecordDTO{
+		UserID:      user.ID,
+		OrgID:       user.ID,
+		Key:         unique("key"),
+		Fingerprint: "POST /api/todos",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	stored, err := store.Acquire(f.ctx, &record)
+	f.no(err, "acquiring key")
+	if stored != nil {
+		f.t.Fatal("got stored response for a new key")
+	}
+	_, err = store.Acquire(f.ctx, &record)
+	f.is(err, errors2.ErrIdempotencyInProgress, "acquiring key in progress")
+	other := record
+	other.Fingerprint = "POST /api/projects"
+	_, err = store.Acquire(f.ctx, &other)
+	f.is(err, errors2.ErrIdempotencyKeyReuse, "acquiring key for another request")
+
+	f.no(store.Release(f.ctx, &record), "releasing key")
+	_, err = store.Acquire(f.ctx, &record)
+	f.no(err, "acquiring released key")
+
+	record.Status, record.ContentType, record.Body = 201, "application/json", []byte(`{"ok":true}`)
+	f.no(store.Complete(f.ctx, &record), "completing key")
+	stored, err = store.Acquire(f.ctx, &record)
+	f.no(err, "acquiring completed key")
+	if stored == nil || stored.Status != 201 || string(stored.Body) != string(record.Body) {
+		f.t.Fatalf("got stored response %v, want status 201 and the body", stored)
+	}
+	f.no(store.Release(f.ctx, &record), "releasing completed key")
+	if stored, _ = store.Acquire(f.ctx, &record); stored == nil {
+		f.t.Fatal("got completed key released")
+	}
+
+	// the same key of the user in another organization is another request
+	org := model.OrganizationDTO{ID: uuid.New(), Name: unique("org"), CreatedBy: user.ID}
+	f.no(f.store.Organization().Create(f.ctx, &org), "creating organization")
+	inOrg := record
+	inOrg.OrgID, inOrg.Fingerprint = org.ID, "POST /api/projects"
+	stored, err = store.Acquire(f.ctx, &inOrg)
+	f.no(err, "acquiring key in another organization")
+	if stored != nil {
+		f.t.Fatal("got stored response of another organization")
+	}
+	f.no(store.Release(f.ctx, &inOrg), "releasing key in another organization")
+	if stored, _ = store.Acquire(f.ctx, &record); stored == nil {
+		f.t.Fatal("got key released in another organization")
+	}
+}
+
+// testOutbox publishes every pending event, so nothing else may relay the
+// outbox of the storage while it runs
+func testOutbox(f *fixture) {
+	project := f.project(f.user().ID)
+	todo := f.todo(project, "To do")
+	_, err := f.store.Todo().Move(f.ctx, todo.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "moving todo")
+
+	failed := false
+	_, err = f.store.Outbox().Publish(f.ctx, 10, func(_ context.Context, events []model.DomainEventDTO) error {
+		failed = true
+		return errAbort
+	})
+	f.is(err, errAbort, "failing to publish")
+	if !failed {
+		f.t.Fatal("got nothing to publish")
+	}
+
+	var published []model.DomainEventDTO
+	for {
+		n, err := f.store.Outbox().Publish(f.ctx, 10, func(_ context.Context, events []model.DomainEventDTO) error {
+			for _, event := range events {
+				if event.ProjectID == project.ID {
+					published = append(published, event)
+				}
+			}
+			return nil
+		})
+		f.no(err, "publishing events")
+		if n == 0 {
+			break
+		}
+	}
+
+	// the project, its 3 columns, the todo and its move
+	if len(published) != 6 {
+		f.t.Fatalf("got %d events of the project, want 6", len(published))
+	}
+	for i := 1; i < len(published); i++ {
+		if published[i].ID <= published[i-1].ID {
+			f.t.Fatalf("got event %d after %d, want events in order", published[i].ID, published[i-1].ID)
+		}
+	}
+	moved := model.DomainEventType(storage.EntityTodo, storage.ActionMove, nil)
+	if last := published[5]; last.Type != moved || last.AggregateID != todo.ID {
+		f.t.Fatalf("got last event %s of %s, want %s of the todo", last.Type, last.AggregateID, moved)
+	}
+}
+
+func testWithTx(f *fixture) {
+	project := f.project(f.user().ID)
+	rolledBack := f.newTodo(project, "To do")
+	committed := f.newTodo(project, "To do")
+	savepoint := f.newTodo(project, "To do")
+
+	err := f.store.WithTx(f.ctx, func(tx storage.Interface) error {
+		if err := tx.Todo().Create(f.ctx, &rolledBack, false); err != nil {
+			return err
+		}
+		if _, err := tx.Todo().GetByID(f.ctx, rolledBack.ID); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	f.is(err, errAbort, "running failing transaction")
+	_, err = f.store.Todo().GetByID(f.ctx, rolledBack.ID)
+	f.is(err, errors2.ErrGetByID, "getting todo of rolled back transaction")
+
+	err = f.store.WithTx(f.ctx, func(tx storage.Interface) error {
+		if err := tx.Todo().Create(f.ctx, &committed, false); err != nil {
+			return err
+		}
+		err := tx.WithTx(f.ctx, func(nested storage.Interface) error {
+			if err := nested.Todo().Create(f.ctx, &savepoint, false); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			return err
+		}
+		return nil
+	})
+	f.no(err, "running transaction")
+	f.getTodo(committed.ID)
+	_, err = f.store.Todo().GetByID(f.ctx, savepoint.ID)
+	f.is(err, errors2.ErrGetByID, "getting todo of rolled back savepoint")
+}
+
+// testConcurrentCreate races todos for the last places of a column, the WIP
+// limit must hold however the creates interleave
+func testConcurrentCreate(f *fixture) {
+	project := f.project(f.user().ID)
+	limit := 3
+	column := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "Review", Order: 2, WipLimit: &limit}
+	f.no(f.store.Column().CreateColumn(f.ctx, &column), "creating column with WIP limit")
+
+	const racers = 20
+	errs := make([]error, racers)
+	var wg sync.WaitGroup
+	for i := range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			todo := f.newTodo(project, "Review")
+			errs[i] = f.store.Todo().Create(f.ctx, &todo, false)
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, errors2.ErrWipLimitReached):
+			f.t.Fatalf("creating todo: got error %v, want %v", err, errors2.ErrWipLimitReached)
+		}
+	}
+	if created != limit {
+		f.t.Fatalf("got %d todos created, want %d", created, limit)
+	}
+}
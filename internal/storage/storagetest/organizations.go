@@ -0,0 +1,129 @@
+package storagetest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+func testOrganizations(f *fixture) {
+	owner := f.user()
+	orgs := f.store.Organization()
+
+	personal, err := orgs.GetByID(f.ctx, owner.ID)
+	f.no(err, "getting personal organization")
+	if personal.Name != owner.Login || personal.CreatedBy != owner.ID {
+		f.t.Fatalf("got personal organization %q of %s, want %q of %s", personal.Name, personal.CreatedBy, owner.Login, owner.ID)
+	}
+	_, err = orgs.GetByID(f.ctx, uuid.New())
+	f.is(err, errors2.ErrNotFound, "getting missing organization")
+
+	org := model.OrganizationDTO{ID: uuid.New(), Name: unique("org"), CreatedBy: owner.ID}
+	f.no(orgs.Create(f.ctx, &org), "creating organization")
+	if org.Role != model.OrgRoleOwner {
+		f.t.Fatalf("got role %q of the creator, want %q", org.Role, model.OrgRoleOwner)
+	}
+	orgCtx := storage.WithTenant(f.ctx, org.ID)
+
+	member := f.user()
+	f.no(orgs.AddMember(orgCtx, &model.OrganizationMemberDTO{UserID: member.ID, Role: model.OrgRoleMember}), "adding member")
+	f.is(orgs.AddMember(orgCtx, &model.OrganizationMemberDTO{UserID: member.ID, Role: model.OrgRoleAdmin}),
+		errors2.ErrAlreadyExists, "adding member twice")
+	f.is(orgs.AddMember(orgCtx, &model.OrganizationMemberDTO{UserID: uuid.New(), Role: model.OrgRoleMember}),
+		errors2.ErrNotFound, "adding missing user")
+
+	members, err := orgs.GetMembers(orgCtx)
+	f.no(err, "getting members")
+	if len(members) != 2 {
+		f.t.Fatalf("got %d members, want 2", len(members))
+	}
+	got, err := orgs.GetMember(f.ctx, org.ID, member.ID)
+	f.no(err, "getting member")
+	if got.Role != model.OrgRoleMember || got.Login != member.Login {
+		f.t.Fatalf("got member %q with role %q, want %q with role %q", got.Login, got.Role, member.Login, model.OrgRoleMember)
+	}
+	_, err = orgs.GetMember(f.ctx, owner.ID, member.ID)
+	f.is(err, errors2.ErrNotMember, "getting member of another organization")
+	joined, err := orgs.GetByMember(f.ctx, member.ID)
+	f.no(err, "getting organizations of member")
+	if len(joined) != 2 {
+		f.t.Fatalf("got %d organizations of member, want the personal one and the joined one", len(joined))
+	}
+
+	f.is(orgs.UpdateMemberRole(orgCtx, owner.ID, model.OrgRoleAdmin), errors2.ErrLastOwner, "demoting last owner")
+	f.is(orgs.RemoveMember(orgCtx, owner.ID), errors2.ErrLastOwner, "removing last owner")
+	f.no(orgs.UpdateMemberRole(orgCtx, member.ID, model.OrgRoleOwner), "promoting member")
+	f.no(orgs.UpdateMemberRole(orgCtx, owner.ID, model.OrgRoleAdmin), "demoting owner")
+	f.is(orgs.RemoveMember(orgCtx, member.ID), errors2.ErrLastOwner, "removing new last owner")
+	f.no(orgs.RemoveMember(orgCtx, owner.ID), "removing admin")
+	_, err = orgs.GetMember(f.ctx, org.ID, owner.ID)
+	f.is(err, errors2.ErrNotMember, "getting removed member")
+	f.is(orgs.RemoveMember(orgCtx, owner.ID), errors2.ErrNotFound, "removing removed member")
+
+	f.is(orgs.RemoveMember(f.ctx, owner.ID), errors2.ErrPersonalOwner, "removing owner of personal organization")
+	_, err = orgs.GetMembers(context.Background())
+	f.is(err, errors2.ErrNoOrganization, "getting members without organization")
+}
+
+// testTenantIsolation checks that nothing of a project is found, listed or
+// changed from another organization
+func testTenantIsolation(f *fixture) {
+	project := f.project(f.user().ID)
+	todo := f.todo(project, "To do")
+	stranger := f.user()
+	other := storage.WithTenant(f.ctx, stranger.ID)
+
+	_, err := f.store.Project().GetByID(other, project.ID)
+	f.is(err, errors2.ErrNotFound, "getting project of another organization")
+	projects, err := f.store.Project().GetAll(other, true)
+	f.no(err, "getting projects of another organization")
+	if len(projects) != 0 {
+		f.t.Fatalf("got %d projects of another organization, want none", len(projects))
+	}
+	f.is(f.store.Project().UpdateName(other, "stolen", project.ID), errors2.ErrNotFound, "renaming project of another organization")
+	f.is(f.store.Project().Delete(other, project.ID), errors2.ErrNotFound, "deleting project of another organization")
+	columns, err := f.store.Column().GetAllColumns(other, project.ID)
+	f.no(err, "getting columns of another organization")
+	if len(columns) != 0 {
+		f.t.Fatalf("got %d columns of another organization, want none", len(columns))
+	}
+	intruder := f.newTodo(project, "To do")
+	intruder.CreatedBy = stranger.ID
+	f.is(f.store.Todo().Create(other, &intruder, false), errors2.ErrNotFound, "creating todo in project of another organization")
+	namesake := f.newTodo(f.projectIn(other, stranger.ID), "To do")
+	namesake.Name = todo.Name
+	f.no(f.store.Todo().Create(other, &namesake, false), "creating todo with name taken in another organization")
+
+	_, err = f.store.Todo().GetByID(other, todo.ID)
+	f.fails(err, "getting todo of another organization")
+	_, err = f.store.Todo().Move(other, todo.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.is(err, errors2.ErrNotFound, "moving todo of another organization")
+	f.is(f.store.Todo().Delete(other, todo.ID), errors2.ErrNotFound, "deleting todo of another organization")
+	item := model.ChecklistItemDTO{ID: uuid.New(), TodoID: todo.ID, Text: "item"}
+	f.is(f.store.Checklist().Create(other, &item), errors2.ErrNotFound, "adding checklist item to todo of another organization")
+	comment := model.CommentDTO{ID: uuid.New(), TodoID: todo.ID, AuthorID: stranger.ID, Body: "hello"}
+	f.is(f.store.Comment().Create(other, &comment), errors2.ErrNotFound, "commenting todo of another organization")
+	_, total, err := f.store.Activity().GetByProject(other, project.ID, 10, 0)
+	f.no(err, "getting activity of another organization")
+	if total != 0 {
+		f.t.Fatalf("got %d activity records of another organization, want none", total)
+	}
+
+	// todos are assigned to members of the organization only
+	results, err := f.store.Todo().Bulk(f.ctx, project.CreatedBy, []model.TodoBulkOperation{{TodoID: todo.ID, Op: model.BulkAssign, AssigneeID: &stranger.ID}}, false)
+	f.no(err, "assigning todo")
+	if results[0].Status != model.BulkStatusFailed {
+		f.t.Fatalf("got status %q assigning todo to a stranger, want %q", results[0].Status, model.BulkStatusFailed)
+	}
+	f.is(results[0].Err, errors2.ErrAssigneeNotFound, "assigning todo to a stranger")
+
+	_, err = f.store.Project().GetAll(context.Background(), false)
+	f.is(err, errors2.ErrNoOrganization, "getting projects without organization")
+	if got := f.getTodo(todo.ID); got.Name != todo.Name || got.IsCompleted {
+		f.t.Fatalf("got todo %q completed %t, want it untouched", got.Name, got.IsCompleted)
+	}
+}
@@ -0,0 +1,289 @@
+package storagetest
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+func testUsers(f *fixture) {
+	user := f.user()
+
+	duplicate := model.UserDTO{ID: uuid.New(), Login: user.Login, Password: "other"}
+	f.is(f.store.User().Create(f.ctx, &duplicate), errors2.ErrAlreadyExists, "creating user with taken login")
+
+	got, err := f.store.User().GetByLogin(f.ctx, user.Login)
+	f.no(err, "getting user by login")
+	if got.ID != user.ID {
+		f.t.Fatalf("got user %s by login, want %s", got.ID, user.ID)
+	}
+	_, err = f.store.User().GetByLogin(f.ctx, unique("missing"))
+	f.is(err, errors2.ErrGetByLogin, "getting missing user by login")
+	_, err = f.store.User().GetByID(f.ctx, uuid.New())
+	f.is(err, errors2.ErrGetByID, "getting missing user by id")
+
+	f.no(f.store.User().ChangePassword(f.ctx, "changed", user.ID), "changing password")
+	got, err = f.store.User().GetByID(f.ctx, user.ID)
+	f.no(err, "getting user by id")
+	if got.Password != "changed" {
+		f.t.Fatalf("got password %q, want the changed one", got.Password)
+	}
+}
+
+func testProjects(f *fixture) {
+	owner := f.user()
+	project := f.project(owner.ID)
+
+	orphan := model.ProjectDTO{ID: uuid.New(), Name: unique("project"), CreatedBy: uuid.New()}
+	f.fails(f.store.Project().Create(f.ctx, &orphan, nil), "creating project of missing user")
+	duplicate := model.ProjectDTO{ID: project.ID, Name: unique("project"), CreatedBy: owner.ID}
+	f.is(f.store.Project().Create(f.ctx, &duplicate, nil), errors2.ErrAlreadyExists, "creating project with taken id")
+
+	got, err := f.store.Project().GetByID(f.ctx, project.ID)
+	f.no(err, "getting project")
+	f.is(f.store.Project().UpdateName(storage.WithExpectedVersion(f.ctx, got.Version+1), "renamed", project.ID),
+		errors2.ErrVersionMismatch, "renaming project of another version")
+	f.no(f.store.Project().UpdateName(storage.WithExpectedVersion(f.ctx, got.Version), "renamed", project.ID), "renaming project")
+
+	f.no(f.store.Project().Archive(f.ctx, project.ID, true), "archiving project")
+	projects, err := f.store.Project().GetAll(f.ctx, false)
+	f.no(err, "getting projects")
+	if len(projects) != 0 {
+		f.t.Fatalf("got %d projects, want archived project left out", len(projects))
+	}
+	f.no(f.store.Project().Archive(f.ctx, project.ID, false), "unarchiving project")
+
+	f.no(f.store.Project().Delete(f.ctx, project.ID), "deleting project")
+	_, err = f.store.Project().GetByID(f.ctx, project.ID)
+	f.is(err, errors2.ErrNotFound, "getting deleted project")
+	trash, err := f.store.Project().GetTrash(f.ctx, owner.ID)
+	f.no(err, "getting trashed projects")
+	if len(trash) != 1 || trash[0].ID != project.ID {
+		f.t.Fatalf("got trash %v, want the deleted project", trash)
+	}
+
+	f.no(f.store.Project().Restore(f.ctx, project.ID), "restoring project")
+	f.is(f.store.Project().Restore(f.ctx, project.ID), errors2.ErrNotFound, "restoring project not in the trash")
+	got, err = f.store.Project().GetByID(f.ctx, project.ID)
+	f.no(err, "getting restored project")
+	if got.Name != "renamed" {
+		f.t.Fatalf("got project name %q, want %q", got.Name, "renamed")
+	}
+}
+
+func testColumns(f *fixture) {
+	project := f.project(f.user().ID)
+	columns := f.store.Column()
+
+	duplicate := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "Done", Order: 0}
+	f.is(columns.CreateColumn(f.ctx, &duplicate), errors2.ErrAlreadyExists, "creating column with taken name")
+	zero := 0
+	bad := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "Review", WipLimit: &zero}
+	f.fails(columns.CreateColumn(f.ctx, &bad), "creating column with zero WIP limit")
+
+	review := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "Review", Order: 2}
+	f.no(columns.CreateColumn(f.ctx, &review), "creating column")
+	f.columnNames(project.ID, "To do", "In progress", "Review", "Done")
+
+	f.is(columns.ReorderColumns(f.ctx, project.ID, []string{"Done", "To do"}), errors2.ErrColumnsMismatch, "reordering some columns")
+	f.no(columns.ReorderColumns(f.ctx, project.ID, []string{"Done", "Review", "In progress", "To do"}), "reordering columns")
+	f.columnNames(project.ID, "Done", "Review", "In progress", "To do")
+
+	todo := f.todo(project, "Review")
+	f.is(columns.DeleteColumn(f.ctx, "Review", project.ID, "", false), errors2.ErrColumnNotEmpty, "deleting column with todos")
+	f.is(columns.DeleteColumn(f.ctx, "Review", project.ID, "Missing", false), errors2.ErrColumnTarget, "moving todos to missing column")
+	f.no(columns.DeleteColumn(f.ctx, "Review", project.ID, "To do", false), "deleting column moving its todos")
+	if got := f.getTodo(todo.ID); got.Column != "To do" {
+		f.t.Fatalf("got todo in column %q, want %q", got.Column, "To do")
+	}
+	f.columnNames(project.ID, "Done", "In progress", "To do")
+
+	f.no(columns.DeleteColumn(f.ctx, "To do", project.ID, "", true), "deleting column with its todos")
+	_, err := f.store.Todo().GetByID(f.ctx, todo.ID)
+	f.fails(err, "getting todo of deleted column")
+	_, err = columns.GetColumnByName(f.ctx, "To do", project.ID)
+	f.is(err, errors2.ErrNotFound, "getting deleted column")
+}
+
+// testColumnDelete checks what happens to the todos that are not part of a
+// deleted column
+func testColumnDelete(f *fixture) {
+	project := f.project(f.user().ID)
+	columns, todos := f.store.Column(), f.store.Todo()
+	for _, name := range []string{"Review", "QA"} {
+		column := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: name, Order: 2}
+		f.no(columns.CreateColumn(f.ctx, &column), "creating column")
+	}
+	archive := func(todo model.TodoDTO) {
+		f.t.Helper()
+		_, err := todos.Archive(f.ctx, todo.ID, true)
+		f.no(err, "archiving todo")
+	}
+
+	// archived and trashed todos do not keep their column from being deleted
+	review, trashed := f.todo(project, "Review"), f.todo(project, "Review")
+	archive(review)
+	f.no(todos.Delete(f.ctx, trashed.ID), "deleting todo")
+	f.no(columns.DeleteColumn(f.ctx, "Review", project.ID, "", false), "deleting column with an archived and a trashed todo")
+	if got := f.getTodo(review.ID); got.ArchivedAt == nil || got.Column != "" {
+		f.t.Fatalf("got todo archived at %v in column %q, want it archived without column", got.ArchivedAt, got.Column)
+	}
+	unarchived, err := todos.Archive(f.ctx, review.ID, false)
+	f.no(err, "unarchiving todo of deleted column")
+	if unarchived.Column != "To do" {
+		f.t.Fatalf("got todo unarchived into column %q, want the first column", unarchived.Column)
+	}
+	restored, err := todos.Restore(f.ctx, trashed.ID)
+	f.no(err, "restoring todo of deleted column")
+	if restored.Column != "To do" || restored.Position <= unarchived.Position {
+		f.t.Fatalf("got todo restored into column %q at %q, want it after %q in the first column", restored.Column, restored.Position, unarchived.Position)
+	}
+
+	// moving todos leaves the archived ones out, a column created again with
+	// the same name takes them back
+	moved, kept := f.todo(project, "QA"), f.todo(project, "QA")
+	archive(kept)
+	f.no(columns.DeleteColumn(f.ctx, "QA", project.ID, "Done", false), "deleting column moving its todos")
+	if got := f.getTodo(moved.ID); got.Column != "Done" || !got.IsCompleted {
+		f.t.Fatalf("got moved todo in column %q completed %t, want it done", got.Column, got.IsCompleted)
+	}
+	if got := f.getTodo(kept.ID); got.Column != "" || got.IsCompleted {
+		f.t.Fatalf("got archived todo in column %q completed %t, want it left alone", got.Column, got.IsCompleted)
+	}
+	qa := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "QA", Order: 1}
+	f.no(columns.CreateColumn(f.ctx, &qa), "creating column again")
+	unarchived, err = todos.Archive(f.ctx, kept.ID, false)
+	f.no(err, "unarchiving todo of column created again")
+	if unarchived.Column != "QA" {
+		f.t.Fatalf("got todo unarchived into column %q, want %q", unarchived.Column, "QA")
+	}
+
+	// deleting a column with its todos moves them to the trash one by one and
+	// keeps the archived ones
+	progress, gone := f.todo(project, "In progress"), f.todo(project, "In progress")
+	archive(progress)
+	since, err := f.store.Activity().LastID(f.ctx, project.ID)
+	f.no(err, "getting last activity id")
+	f.no(columns.DeleteColumn(f.ctx, "In progress", project.ID, "", true), "deleting column with its todos")
+	if got := f.getTodo(progress.ID); got.ArchivedAt == nil || got.Column != "" {
+		f.t.Fatalf("got todo archived at %v in column %q, want it archived without column", got.ArchivedAt, got.Column)
+	}
+	trash, err := todos.GetTrash(f.ctx, project.CreatedBy)
+	f.no(err, "getting trashed todos")
+	if len(trash) != 1 || trash[0].ID != gone.ID || trash[0].Column != "" {
+		f.t.Fatalf("got trash %v, want the todo of the deleted column", trash)
+	}
+	activity, err := f.store.Activity().GetSince(f.ctx, project.ID, since, 10)
+	f.no(err, "getting activity since")
+	if len(activity) != 2 || activity[0].EntityID != gone.ID || activity[0].Action != storage.ActionDelete {
+		f.t.Fatalf("got %d activity records, want the delete of the todo and then of the column", len(activity))
+	}
+
+	// moving todos logs every move, and the recurring todos completed by it
+	// spawn their next occurrence in the new first column
+	due := time.Date(2100, time.January, 4, 0, 0, 0, 0, time.UTC) // a monday
+	recurring := f.newTodo(project, "To do")
+	recurring.Recurrence = "FREQ=WEEKLY;BYDAY=MO,TH"
+	recurring.DueDate = &due
+	f.no(todos.Create(f.ctx, &recurring, false), "creating recurring todo")
+	since, err = f.store.Activity().LastID(f.ctx, project.ID)
+	f.no(err, "getting last activity id")
+	f.no(columns.DeleteColumn(f.ctx, "To do", project.ID, "Done", false), "deleting first column moving its todos")
+	if got := f.getTodo(recurring.ID); got.Column != "Done" || !got.IsCompleted {
+		f.t.Fatalf("got recurring todo in column %q completed %t, want it done", got.Column, got.IsCompleted)
+	}
+	activity, err = f.store.Activity().GetSince(f.ctx, project.ID, since, 10)
+	f.no(err, "getting activity since")
+	var moves, creates int
+	for _, record := range activity {
+		switch {
+		case record.Entity == storage.EntityTodo && record.Action == storage.ActionMove:
+			moves++
+		case record.Entity == storage.EntityTodo && record.Action == storage.ActionCreate:
+			creates++
+		}
+	}
+	// the unarchived, the restored and the recurring todo
+	if moves != 3 || creates != 1 {
+		f.t.Fatalf("got %d moves and %d created todos, want 3 moves and the occurrence", moves, creates)
+	}
+	all, err := todos.GetAll(f.ctx, project.CreatedBy, false)
+	f.no(err, "getting todos")
+	next := recurrence.OccurrenceName(recurring.Name, time.Date(2100, time.January, 7, 0, 0, 0, 0, time.UTC))
+	spawned := false
+	for _, todo := range all {
+		if todo.Name == next {
+			spawned = true
+			if todo.Column != "QA" || todo.IsCompleted {
+				f.t.Fatalf("got occurrence in column %q completed %t, want it open in the new first column", todo.Column, todo.IsCompleted)
+			}
+		}
+	}
+	if !spawned {
+		f.t.Fatal("got no occurrence of the recurring todo moved to the done column")
+	}
+
+	// a todo without column has nowhere to go back to in a project without columns
+	for _, name := range []string{"QA", "Done"} {
+		f.no(columns.DeleteColumn(f.ctx, name, project.ID, "", true), "deleting column")
+	}
+	_, err = todos.Archive(f.ctx, progress.ID, false)
+	f.is(err, errors2.ErrColumnTarget, "unarchiving todo of project without columns")
+	_, err = todos.Restore(f.ctx, gone.ID)
+	f.is(err, errors2.ErrColumnTarget, "restoring todo of project without columns")
+}
+
+// columnNames fails the check when the columns of the project are not names
+// in this order
+func (f *fixture) columnNames(projectID uuid.UUID, names ...string) {
+	f.t.Helper()
+	columns, err := f.store.Column().GetAllColumns(f.ctx, projectID)
+	f.no(err, "getting columns")
+	got := make([]string, 0, len(columns))
+	for _, column := range columns {
+		got = append(got, column.Name)
+	}
+	if len(got) != len(names) {
+		f.t.Fatalf("got columns %q, want %q", got, names)
+	}
+	for i := range got {
+		if got[i] != names[i] {
+			f.t.Fatalf("got columns %q, want %q", got, names)
+		}
+	}
+}
+
+func testTemplates(f *fixture) {
+	user := f.user()
+	template := model.ProjectTemplateDTO{
+		ID:        uuid.New(),
+		Name:      unique("template"),
+		CreatedBy: user.ID,
+		Columns:   []model.TemplateColumnDTO{{Name: "Backlog"}, {Name: "Shipped", IsDone: true}},
+	}
+	f.no(f.store.Template().Create(f.ctx, &template), "creating template")
+	duplicate := template
+	duplicate.ID = uuid.New()
+	f.is(f.store.Template().Create(f.ctx, &duplicate), errors2.ErrAlreadyExists, "creating template with taken name")
+
+	templates, err := f.store.Template().GetMyTemplates(f.ctx, user.ID)
+	f.no(err, "getting templates")
+	if len(templates) != len(model.BuiltinTemplates)+1 || !templates[0].IsBuiltin() {
+		f.t.Fatalf("got %d templates, want built-in templates first and then the created one", len(templates))
+	}
+	got, err := f.store.Template().GetByID(f.ctx, template.ID)
+	f.no(err, "getting template")
+	if len(got.Columns) != 2 || !got.Columns[1].IsDone {
+		f.t.Fatalf("got template columns %v, want %v", got.Columns, template.Columns)
+	}
+
+	f.is(f.store.Template().Delete(f.ctx, template.ID, uuid.New()), errors2.ErrTemplateNotFound, "deleting template of another user")
+	f.no(f.store.Template().Delete(f.ctx, template.ID, user.ID), "deleting template")
+	_, err = f.store.Template().GetByID(f.ctx, template.ID)
+	f.is(err, errors2.ErrTemplateNotFound, "getting deleted template")
+}
@@ -0,0 +1,156 @@
+// Package storagetest is the contract of storage.Interface: the behaviour
+// handlers and workers rely on, checked the same way for every
+// implementation. An implementation runs it from a test of its own:
+//
+//	func TestStorage(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Interface {
+//			return memory.New(zaptest.NewLogger(t))
+//		})
+//	}
+//
+// Every check creates its own users, projects and todos with unique names,
+// so the storage may be shared between checks, e.g. one Postgres database.
+// Projects of a check belong to the personal organization of its first user
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Run checks the storage returned by newStore against the contract. newStore
+// is called once per check
+func Run(t *testing.T, newStore func(t *testing.T) storage.Interface) {
+	checks := []struct {
+		name string
+		run  func(f *fixture)
+	}{
+		{"Users", testUsers},
+		{"Organizations", testOrganizations},
+		{"TenantIsolation", testTenantIsolation},
+		{"Projects", testProjects},
+		{"Columns", testColumns},
+		{"ColumnDelete", testColumnDelete},
+		{"Templates", testTemplates},
+		{"Todos", testTodos},
+		{"Recurrence", testRecurrence},
+		{"Dependencies", testDependencies},
+		{"TodoTrash", testTodoTrash},
+		{"Bulk", testBulk},
+		{"TodoChildren", testTodoChildren},
+		{"Activity", testActivity},
+		{"Idempotency", testIdempotency},
+		{"Outbox", testOutbox},
+		{"WithTx", testWithTx},
+		{"ConcurrentCreate", testConcurrentCreate},
+	}
+	for _, check := range checks {
+		t.Run(check.name, func(t *testing.T) {
+			check.run(&fixture{t: t, ctx: context.Background(), store: newStore(t)})
+		})
+	}
+}
+
+// fixture creates the entities a check works on
+type fixture struct {
+	t     *testing.T
+	ctx   context.Context
+	store storage.Interface
+}
+
+// user creates a user with a unique login. The personal organization of the
+// first user of the check becomes the tenant of f.ctx
+func (f *fixture) user() model.UserDTO {
+	f.t.Helper()
+	user := model.UserDTO{ID: uuid.New(), Login: unique("user") + "@example.com", Password: "secret"}
+	if err := f.store.User().Create(f.ctx, &user); err != nil {
+		f.t.Fatalf("creating user: %v", err)
+	}
+	if _, ok := storage.TenantFromContext(f.ctx); !ok {
+		f.ctx = storage.WithTenant(f.ctx, user.ID)
+	}
+	return user
+}
+
+// project creates a project of the owner with the columns of the built-in
+// Kanban template: "To do", "In progress" and the done column "Done"
+func (f *fixture) project(owner uuid.UUID) model.ProjectDTO {
+	f.t.Helper()
+	return f.projectIn(f.ctx, owner)
+}
+
+// projectIn creates a project like project does in the organization of ctx
+func (f *fixture) projectIn(ctx context.Context, owner uuid.UUID) model.ProjectDTO {
+	f.t.Helper()
+	project := model.ProjectDTO{ID: uuid.New(), Name: unique("project"), CreatedBy: owner}
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	if err := f.store.Project().Create(ctx, &project, template.ColumnsFor(project.ID)); err != nil {
+		f.t.Fatalf("creating project: %v", err)
+	}
+	return project
+}
+
+// todo creates a todo of the project owner in the column
+func (f *fixture) todo(project model.ProjectDTO, column string) model.TodoDTO {
+	f.t.Helper()
+	todo := f.newTodo(project, column)
+	if err := f.store.Todo().Create(f.ctx, &todo, false); err != nil {
+		f.t.Fatalf("creating todo: %v", err)
+	}
+	return todo
+}
+
+// newTodo returns a todo with a unique name that is not stored yet
+func (f *fixture) newTodo(project model.ProjectDTO, column string) model.TodoDTO {
+	return model.TodoDTO{
+		ID:        uuid.New(),
+		Name:      unique("todo"),
+		ProjectID: project.ID,
+		CreatedBy: project.CreatedBy,
+		Column:    column,
+	}
+}
+
+// getTodo reads the todo back
+func (f *fixture) getTodo(id uuid.UUID) *model.TodoDTO {
+	f.t.Helper()
+	todo, err := f.store.Todo().GetByID(f.ctx, id)
+	if err != nil {
+		f.t.Fatalf("getting todo: %v", err)
+	}
+	return todo
+}
+
+// no fails the check when err is not nil
+func (f *fixture) no(err error, doing string) {
+	f.t.Helper()
+	if err != nil {
+		f.t.Fatalf("%s: %v", doing, err)
+	}
+}
+
+// is fails the check when err is not want
+func (f *fixture) is(err, want error, doing string) {
+	f.t.Helper()
+	if !errors.Is(err, want) {
+		f.t.Fatalf("%s: got error %v, want %v", doing, err, want)
+	}
+}
+
+// fails fails the check when err is nil
+func (f *fixture) fails(err error, doing string) {
+	f.t.Helper()
+	if err == nil {
+		f.t.Fatalf("%s: got no error", doing)
+	}
+}
+
+func unique(prefix string) string {
+	return prefix + "-" + uuid.NewString()
+}
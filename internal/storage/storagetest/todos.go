@@ -0,0 +1,292 @@
+package storagetest
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	errors2 "github.com/todo-enjoers/backend_v1/internal/pkg/errors"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/recurrence"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+func testTodos(f *fixture) {
+	project := f.project(f.user().ID)
+	first := f.todo(project, "To do")
+	second := f.todo(project, "To do")
+
+	duplicate := f.newTodo(project, "To do")
+	duplicate.Name = first.Name
+	f.is(f.store.Todo().Create(f.ctx, &duplicate, false), errors2.ErrAlreadyExists, "creating todo with taken name")
+	elsewhere := f.project(project.CreatedBy)
+	namesake := f.newTodo(elsewhere, "To do")
+	namesake.Name = first.Name
+	f.no(f.store.Todo().Create(f.ctx, &namesake, false), "creating todo with name taken in another project")
+	_, err := f.store.Todo().Patch(f.ctx, namesake.ID, project.CreatedBy, &model.TodoPatchDTO{ProjectID: &project.ID, Column: &first.Column})
+	f.is(err, errors2.ErrAlreadyExists, "moving todo to project where its name is taken")
+	orphan := f.newTodo(project, "To do")
+	orphan.CreatedBy = uuid.New()
+	f.is(f.store.Todo().Create(f.ctx, &orphan, false), errors2.ErrInserting, "creating todo of missing user")
+	lost := f.newTodo(project, "Missing")
+	f.is(f.store.Todo().Create(f.ctx, &lost, false), errors2.ErrNotFound, "creating todo in missing column")
+
+	done := f.todo(project, "Done")
+	if !done.IsCompleted || done.Progress != 100 {
+		f.t.Fatalf("got todo created in done column completed=%t progress=%d, want completed", done.IsCompleted, done.Progress)
+	}
+
+	moved, err := f.store.Todo().Move(f.ctx, second.ID, &model.TodoMoveDTO{Column: "To do", BeforeID: first.ID})
+	f.no(err, "moving todo before another")
+	if moved.Position >= f.getTodo(first.ID).Position {
+		f.t.Fatalf("got position %q, want it before the other todo", moved.Position)
+	}
+	_, err = f.store.Todo().Move(f.ctx, second.ID, &model.TodoMoveDTO{Column: "In progress", AfterID: first.ID})
+	f.is(err, errors2.ErrBadPosition, "moving todo after a todo of another column")
+	moved, err = f.store.Todo().Move(f.ctx, second.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "moving todo to done column")
+	if !moved.IsCompleted {
+		f.t.Fatal("got todo moved to done column not completed")
+	}
+
+	limit := 1
+	limited := model.ColumDTO{ID: uuid.New(), ProjectId: project.ID, Name: "Review", Order: 2, WipLimit: &limit}
+	f.no(f.store.Column().CreateColumn(f.ctx, &limited), "creating column with WIP limit")
+	f.todo(project, "Review")
+	extra := f.newTodo(project, "Review")
+	f.is(f.store.Todo().Create(f.ctx, &extra, false), errors2.ErrWipLimitReached, "creating todo over WIP limit")
+	f.no(f.store.Todo().Create(f.ctx, &extra, true), "forcing todo over WIP limit")
+
+	name := unique("patched")
+	labels := []string{"b", "a", "b"}
+	patched, err := f.store.Todo().Patch(f.ctx, first.ID, project.CreatedBy, &model.TodoPatchDTO{Name: &name, Labels: &labels})
+	f.no(err, "patching todo")
+	if patched.Name != name || len(patched.Labels) != 2 {
+		f.t.Fatalf("got patched todo %q with labels %q, want %q with deduplicated labels", patched.Name, patched.Labels, name)
+	}
+
+	todos, err := f.store.Todo().GetAll(f.ctx, project.CreatedBy, false)
+	f.no(err, "getting todos")
+	if len(todos) != 6 {
+		f.t.Fatalf("got %d todos, want 6", len(todos))
+	}
+}
+
+// testRecurrence checks that a recurring todo spawns exactly one next
+// occurrence however often it is completed. The due date lies far ahead, so
+// the occurrence does not depend on the clock of the storage
+func testRecurrence(f *fixture) {
+	project := f.project(f.user().ID)
+	due := time.Date(2100, time.January, 4, 0, 0, 0, 0, time.UTC) // a monday
+	todo := f.newTodo(project, "To do")
+	todo.Recurrence = "FREQ=WEEKLY;BYDAY=MO,TH"
+	todo.DueDate = &due
+	f.no(f.store.Todo().Create(f.ctx, &todo, false), "creating recurring todo")
+
+	_, err := f.store.Todo().Move(f.ctx, todo.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "completing recurring todo")
+	_, err = f.store.Todo().Move(f.ctx, todo.ID, &model.TodoMoveDTO{Column: "To do"})
+	f.no(err, "reopening recurring todo")
+	_, err = f.store.Todo().Move(f.ctx, todo.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "completing recurring todo again")
+	results, err := f.store.Todo().Bulk(f.ctx, project.CreatedBy, []model.TodoBulkOperation{{TodoID: todo.ID, Op: model.BulkComplete}}, false)
+	f.no(err, "completing recurring todo in bulk")
+	f.no(results[0].Err, "completing recurring todo in bulk")
+
+	todos, err := f.store.Todo().GetAll(f.ctx, project.CreatedBy, false)
+	f.no(err, "getting todos")
+	var spawned []model.TodoDTO
+	for _, other := range todos {
+		if other.ID != todo.ID {
+			spawned = append(spawned, other)
+		}
+	}
+	if len(spawned) != 1 {
+		f.t.Fatalf("got %d occurrences after completing the todo three times, want 1", len(spawned))
+	}
+	next := time.Date(2100, time.January, 7, 0, 0, 0, 0, time.UTC)
+	got := spawned[0]
+	if got.DueDate == nil || !got.DueDate.Equal(next) || got.Name != recurrence.OccurrenceName(todo.Name, next) ||
+		got.Column != "To do" || got.IsCompleted || got.Recurrence != todo.Recurrence {
+		f.t.Fatalf("got occurrence %q due %v in %q, want %q due %s in \"To do\"",
+			got.Name, got.DueDate, got.Column, recurrence.OccurrenceName(todo.Name, next), next.Format(time.DateOnly))
+	}
+}
+
+func testDependencies(f *fixture) {
+	project := f.project(f.user().ID)
+	blocker := f.todo(project, "To do")
+	blocked := f.todo(project, "To do")
+	other := f.todo(f.project(project.CreatedBy), "To do")
+
+	f.is(f.store.Todo().AddDependency(f.ctx, blocked.ID, blocked.ID), errors2.ErrBadDependency, "making todo block itself")
+	f.is(f.store.Todo().AddDependency(f.ctx, blocked.ID, other.ID), errors2.ErrBadDependency, "adding blocker from another project")
+	f.no(f.store.Todo().AddDependency(f.ctx, blocked.ID, blocker.ID), "adding blocker")
+	f.is(f.store.Todo().AddDependency(f.ctx, blocker.ID, blocked.ID), errors2.ErrDependencyCycle, "adding cyclic blocker")
+
+	got := f.getTodo(blocked.ID)
+	if len(got.BlockedBy) != 1 || got.BlockedBy[0] != blocker.ID {
+		f.t.Fatalf("got blocked by %v, want %s", got.BlockedBy, blocker.ID)
+	}
+	_, err := f.store.Todo().Move(f.ctx, blocked.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.is(err, errors2.ErrBlocked, "completing blocked todo")
+
+	_, err = f.store.Todo().Move(f.ctx, blocker.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "completing blocker")
+	_, err = f.store.Todo().Move(f.ctx, blocked.ID, &model.TodoMoveDTO{Column: "Done"})
+	f.no(err, "completing unblocked todo")
+
+	f.no(f.store.Todo().RemoveDependency(f.ctx, blocked.ID, blocker.ID), "removing blocker")
+	if got = f.getTodo(blocker.ID); len(got.Blocks) != 0 {
+		f.t.Fatalf("got blocks %v after removing dependency, want none", got.Blocks)
+	}
+}
+
+func testTodoTrash(f *fixture) {
+	project := f.project(f.user().ID)
+	todo := f.todo(project, "To do")
+
+	archived, err := f.store.Todo().Archive(f.ctx, todo.ID, true)
+	f.no(err, "archiving todo")
+	if archived.ArchivedAt == nil {
+		f.t.Fatal("got archived todo without archived_at")
+	}
+	todos, err := f.store.Todo().GetAll(f.ctx, project.CreatedBy, false)
+	f.no(err, "getting todos")
+	if len(todos) != 0 {
+		f.t.Fatalf("got %d todos, want archived todo left out", len(todos))
+	}
+	_, err = f.store.Todo().Archive(f.ctx, todo.ID, false)
+	f.no(err, "unarchiving todo")
+
+	f.is(f.store.Todo().Update(storage.WithExpectedVersion(f.ctx, archived.Version+10), &todo, todo.ID, false),
+		errors2.ErrVersionMismatch, "updating todo of another version")
+	f.no(f.store.Todo().Delete(f.ctx, todo.ID), "deleting todo")
+	_, err = f.store.Todo().GetByID(f.ctx, todo.ID)
+	f.is(err, errors2.ErrGetByID, "getting deleted todo")
+	trash, err := f.store.Todo().GetTrash(f.ctx, project.CreatedBy)
+	f.no(err, "getting trashed todos")
+	if len(trash) != 1 || trash[0].ID != todo.ID {
+		f.t.Fatalf("got trash %v, want the deleted todo", trash)
+	}
+
+	_, err = f.store.Todo().Restore(f.ctx, todo.ID)
+	f.no(err, "restoring todo")
+	_, err = f.store.Todo().Restore(f.ctx, todo.ID)
+	f.is(err, errors2.ErrNotFound, "restoring todo not in the trash")
+	f.getTodo(todo.ID)
+}
+
+func testBulk(f *fixture) {
+	project := f.project(f.user().ID)
+	first := f.todo(project, "To do")
+	second := f.todo(project, "To do")
+	stranger := f.user()
+
+	ops := []model.TodoBulkOperation{
+		{TodoID: first.ID, Op: model.BulkComplete},
+		{TodoID: second.ID, Op: model.BulkMove, Column: "Missing"},
+	}
+	results, err := f.store.Todo().Bulk(f.ctx, project.CreatedBy, ops, true)
+	f.no(err, "running all-or-nothing bulk")
+	if results[0].Status != model.BulkStatusRolledBack || results[1].Status != model.BulkStatusFailed {
+		f.t.Fatalf("got statuses %q and %q, want %q and %q",
+			results[0].Status, results[1].Status, model.BulkStatusRolledBack, model.BulkStatusFailed)
+	}
+	if f.getTodo(first.ID).IsCompleted {
+		f.t.Fatal("got todo of rolled back bulk completed")
+	}
+
+	results, err = f.store.Todo().Bulk(f.ctx, project.CreatedBy, ops, false)
+	f.no(err, "running best-effort bulk")
+	if results[0].Status != model.BulkStatusOK || results[1].Status != model.BulkStatusFailed {
+		f.t.Fatalf("got statuses %q and %q, want %q and %q",
+			results[0].Status, results[1].Status, model.BulkStatusOK, model.BulkStatusFailed)
+	}
+	if !f.getTodo(first.ID).IsCompleted {
+		f.t.Fatal("got todo of best-effort bulk not completed")
+	}
+
+	results, err = f.store.Todo().Bulk(f.ctx, stranger.ID, []model.TodoBulkOperation{{TodoID: second.ID, Op: model.BulkDelete}}, false)
+	f.no(err, "running bulk of a stranger")
+	f.is(results[0].Err, errors2.ErrNotAccessible, "deleting todo of another user in bulk")
+
+	missing := uuid.New()
+	results, err = f.store.Todo().Bulk(f.ctx, project.CreatedBy, []model.TodoBulkOperation{{TodoID: second.ID, Op: model.BulkAssign, AssigneeID: &missing}}, false)
+	f.no(err, "running bulk assign")
+	f.is(results[0].Err, errors2.ErrAssigneeNotFound, "assigning todo to missing user")
+}
+
+// testTodoChildren checks checklist items, comments and attachments, which
+// can not outlive their todo
+func testTodoChildren(f *fixture) {
+	author := f.user()
+	project := f.project(author.ID)
+	todo := f.todo(project, "To do")
+
+	item := model.ChecklistItemDTO{ID: uuid.New(), TodoID: uuid.New(), Text: "missing todo"}
+	f.is(f.store.Checklist().Create(f.ctx, &item), errors2.ErrNotFound, "creating checklist item of missing todo")
+	for _, done := range []bool{true, false} {
+		item = model.ChecklistItemDTO{ID: uuid.New(), TodoID: todo.ID, Text: "step", IsDone: done}
+		f.no(f.store.Checklist().Create(f.ctx, &item), "creating checklist item")
+	}
+	if progress := f.getTodo(todo.ID).Progress; progress != 50 {
+		f.t.Fatalf("got progress %d, want 50", progress)
+	}
+
+	comment := model.CommentDTO{ID: uuid.New(), TodoID: todo.ID, AuthorID: author.ID, Body: "ping @" + author.Login + ", @" + author.Login + " and @" + unique("nobody") + "@example.com"}
+	f.no(f.store.Comment().Create(f.ctx, &comment), "creating comment")
+	if len(comment.Mentions) != 1 || comment.Mentions[0] != author.ID {
+		f.t.Fatalf("got mentions %v, want the author once", comment.Mentions)
+	}
+	orphan := model.CommentDTO{ID: uuid.New(), TodoID: todo.ID, AuthorID: uuid.New(), Body: "who am I"}
+	f.is(f.store.Comment().Create(f.ctx, &orphan), errors2.ErrNotFound, "creating comment of missing user")
+
+	attachment := model.AttachmentDTO{
+		ID:          uuid.New(),
+		TodoID:      todo.ID,
+		UploadedBy:  author.ID,
+		FileName:    "notes.txt",
+		ContentType: "text/plain",
+		Size:        5,
+		StorageKey:  unique("blob"),
+	}
+	f.no(f.store.Attachment().Create(f.ctx, &attachment), "creating attachment")
+	lost := attachment
+	lost.ID, lost.TodoID, lost.StorageKey = uuid.New(), uuid.New(), unique("blob")
+	f.is(f.store.Attachment().Create(f.ctx, &lost), errors2.ErrNotFound, "creating attachment of missing todo")
+
+	f.no(f.store.Comment().Delete(f.ctx, comment.ID, todo.ID), "deleting comment")
+	_, err := f.store.Comment().GetByID(f.ctx, comment.ID, todo.ID)
+	f.is(err, errors2.ErrNotFound, "getting deleted comment")
+	_, total, err := f.store.Comment().GetAll(f.ctx, todo.ID, 10, 0)
+	f.no(err, "getting comments")
+	if total != 0 {
+		f.t.Fatalf("got %d comments, want deleted comment left out", total)
+	}
+
+	kept := attachment
+	kept.ID, kept.TodoID, kept.StorageKey = uuid.New(), f.todo(project, "To do").ID, unique("blob")
+	f.no(f.store.Attachment().Create(f.ctx, &kept), "creating attachment of todo left alone")
+	trashedProject := f.project(author.ID)
+	ofProject := attachment
+	ofProject.ID, ofProject.TodoID, ofProject.StorageKey = uuid.New(), f.todo(trashedProject, "To do").ID, unique("blob")
+	f.no(f.store.Attachment().Create(f.ctx, &ofProject), "creating attachment of todo of project to delete")
+	f.no(f.store.Project().Delete(f.ctx, trashedProject.ID), "deleting project")
+
+	f.no(f.store.Todo().Delete(f.ctx, todo.ID), "deleting todo")
+	keys, err := f.store.Attachment().Purge(f.ctx, farFuture)
+	f.no(err, "purging attachments")
+	if !slices.Contains(keys, attachment.StorageKey) || !slices.Contains(keys, ofProject.StorageKey) || slices.Contains(keys, kept.StorageKey) {
+		f.t.Fatalf("got purged blobs %q, want %q and %q of trashed todos only", keys, attachment.StorageKey, ofProject.StorageKey)
+	}
+	_, err = f.store.Attachment().GetByID(f.ctx, kept.ID, kept.TodoID)
+	f.no(err, "getting attachment of todo left alone")
+	_, err = f.store.Todo().Purge(f.ctx, farFuture)
+	f.no(err, "purging todos")
+	_, err = f.store.Checklist().GetByID(f.ctx, item.ID, todo.ID)
+	f.is(err, errors2.ErrNotFound, "getting checklist item of purged todo")
+	_, err = f.store.Attachment().GetByID(f.ctx, attachment.ID, todo.ID)
+	f.is(err, errors2.ErrNotFound, "getting attachment of purged todo")
+}
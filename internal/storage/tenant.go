@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the organization whose data storage
+// operations see. Projects and everything on their boards, e.g. todos,
+// columns and comments, are found only in this organization, and new
+// projects are created in it. Operations on them fail with
+// ErrNoOrganization when the context carries no organization
+func WithTenant(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, orgID)
+}
+
+// TenantFromContext returns the organization set by WithTenant, ok is false
+// when none was set
+func TenantFromContext(ctx context.Context) (orgID uuid.UUID, ok bool) {
+	orgID, ok = ctx.Value(tenantKey{}).(uuid.UUID)
+	return orgID, ok
+}
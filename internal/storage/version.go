@@ -0,0 +1,19 @@
+package storage
+
+import "context"
+
+type versionKey struct{}
+
+// WithExpectedVersion returns a context carrying the version the client
+// expects the changed todo, project or column to have. Updates and deletes
+// fail with ErrVersionMismatch when the stored version differs
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, versionKey{}, version)
+}
+
+// ExpectedVersionFromContext returns the version set by WithExpectedVersion,
+// zero means any version
+func ExpectedVersionFromContext(ctx context.Context) int64 {
+	version, _ := ctx.Value(versionKey{}).(int64)
+	return version
+}
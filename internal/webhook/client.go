@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+)
+
+// ErrPrivateTarget is returned when a webhook URL resolves to an address of
+// the host or of its network
+var ErrPrivateTarget = errors.New("webhook target is not a public address")
+
+// newClient returns the client sending webhook requests. Webhook URLs are
+// given by users, so unless cfg allows private targets the client refuses to
+// connect to loopback, private, link-local and unspecified addresses. The
+// check runs on the address being dialed, after the host name is resolved,
+// so a name resolving to such an address is refused as well. Redirects are
+// never followed, the 3xx response fails the attempt
+func newClient(cfg *config.Webhooks) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout()}
+	if !cfg.AllowPrivateTargets {
+		dialer.Control = checkTarget
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// requests go straight to their target, a proxy would be the address checked
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   cfg.Timeout(),
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkTarget is the Control of the dialer, address is the resolved ip and port
+func checkTarget(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, ip)
+	}
+	return nil
+}
+
+// isPublic reports whether ip is a global unicast address outside of the
+// private ranges. Loopback, link-local, multicast and unspecified addresses
+// are not global unicast
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
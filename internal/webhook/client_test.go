@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/todo-enjoers/backend_v1/internal/model"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.215.14", want: true},
+		{ip: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.1.2.3", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "fd00::1", want: false},
+		// the metadata service of the clouds
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "::", want: false},
+		{ip: "224.0.0.1", want: false},
+		{ip: "::ffff:127.0.0.1", want: false},
+		{ip: "::ffff:10.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("isPublic(%s) = %t, want %t", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestPrivateTargetRefused(t *testing.T) {
+	cfg := testConfig()
+	cfg.AllowPrivateTargets = false
+	f := newFixture(t, cfg, http.StatusOK)
+	f.deliverDue(1)
+
+	if len(f.receiver.requests) != 0 {
+		t.Fatalf("got %d requests on the loopback, want none", len(f.receiver.requests))
+	}
+	delivery := f.stored()
+	if delivery.Status != model.WebhookPending || delivery.LastStatusCode != nil || !strings.Contains(delivery.LastError, ErrPrivateTarget.Error()) {
+		t.Fatalf("got delivery %+v, want it pending with %q", delivery, ErrPrivateTarget)
+	}
+}
+
+func TestRedirectNotFollowed(t *testing.T) {
+	target := &receiver{statuses: []int{http.StatusOK}}
+	srv := httptest.NewServer(target)
+	t.Cleanup(srv.Close)
+
+	f := newFixture(t, testConfig(), http.StatusFound)
+	f.dispatcher.Client.Transport = redirectTo(srv.URL, f.dispatcher.Client.Transport)
+	f.deliverDue(1)
+
+	if len(target.requests) != 0 {
+		t.Fatalf("got %d requests on the redirect target, want none", len(target.requests))
+	}
+	delivery := f.stored()
+	if delivery.Status != model.WebhookPending || delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusFound {
+		t.Fatalf("got delivery %+v, want it pending after the 302", delivery)
+	}
+}
+
+// redirectTo adds a Location to location to the responses of next
+func redirectTo(location string, next http.RoundTripper) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err == nil {
+			resp.Header.Set("Location", location)
+		}
+		return resp, err
+	})
+}
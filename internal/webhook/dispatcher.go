@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/pkg/poller"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+)
+
+// Dispatcher sends the deliveries queued in the webhook outbox. A delivery
+// that is not answered with 2xx is retried with exponential backoff until it
+// runs out of attempts
+type Dispatcher struct {
+	store storage.Interface
+	cfg   *config.Webhooks
+	log   *zap.Logger
+	// Client sends webhook requests, it may be replaced before Run
+	Client *http.Client
+	poller poller.Poller
+}
+
+func New(store storage.Interface, cfg *config.Config, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		cfg:    cfg.Webhooks,
+		log:    log.Named("webhooks"),
+		Client: newClient(cfg.Webhooks),
+	}
+}
+
+func RunDispatcherFx(lc fx.Lifecycle, d *Dispatcher) {
+	lc.Append(fx.Hook{
+		OnStart: d.Run,
+		OnStop:  d.Shutdown,
+	})
+}
+
+// Run starts delivering in the background until Shutdown
+func (d *Dispatcher) Run(_ context.Context) error {
+	if d.cfg.PollInterval() <= 0 {
+		d.log.Info("webhook delivery is disabled")
+		return nil
+	}
+
+	d.poller.Every(d.cfg.PollInterval(), func(ctx context.Context) {
+		if err := poller.Drain(ctx, d.cfg.BatchSize, d.DeliverDue); err != nil {
+			d.log.Error("error while delivering webhooks", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	return d.poller.Shutdown(ctx)
+}
+
+// DeliverDue claims one batch of due deliveries, sends them concurrently and
+// records the outcomes. It returns how many deliveries were claimed
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	// a claimed delivery is hidden for twice the timeout, so that it is not
+	// sent again while its attempt is being recorded
+	jobs, err := d.store.Webhook().ClaimDue(ctx, 2*d.cfg.Timeout(), d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(job *model.WebhookJobDTO) {
+			defer wg.Done()
+			d.deliver(ctx, job)
+		}(&jobs[i])
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// deliver makes one attempt of the delivery and records it
+func (d *Dispatcher) deliver(ctx context.Context, job *model.WebhookJobDTO) {
+	attempt := model.WebhookAttemptDTO{
+		DeliveryID:  job.Delivery.ID,
+		AttemptedAt: time.Now(),
+	}
+	statusCode, err := d.send(ctx, job, attempt.AttemptedAt)
+	attempt.DurationMs = time.Since(attempt.AttemptedAt).Milliseconds()
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	status := model.WebhookDelivered
+	var next *time.Time
+	if err != nil {
+		attempt.Error = err.Error()
+		attempts := job.Delivery.Attempts + 1
+		if attempts >= d.cfg.MaxAttempts {
+			status = model.WebhookFailed
+		} else {
+			status = model.WebhookPending
+			at := time.Now().Add(d.cfg.Backoff(attempts))
+			next = &at
+		}
+	}
+
+	if err = d.store.Webhook().RecordAttempt(ctx, &attempt, status, next); err != nil {
+		d.log.Error("error while recording webhook attempt",
+			zap.String("delivery_id", job.Delivery.ID.String()), zap.Error(err))
+		return
+	}
+	if status == model.WebhookFailed {
+		d.log.Warn("webhook delivery failed",
+			zap.String("delivery_id", job.Delivery.ID.String()), zap.String("url", job.URL))
+	}
+}
+
+// send posts the payload of the delivery and returns the status code of the
+// response. Any response other than 2xx is an error
+func (d *Dispatcher) send(ctx context.Context, job *model.WebhookJobDTO, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, job.Delivery.Event)
+	req.Header.Set(HeaderDelivery, job.Delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, timestamp, job.Delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain a little of the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,288 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+	"github.com/todo-enjoers/backend_v1/internal/model"
+	"github.com/todo-enjoers/backend_v1/internal/storage"
+	"github.com/todo-enjoers/backend_v1/internal/storage/memory"
+)
+
+const testSecret = "webhook-secret"
+
+// rescheduling records the retry schedule the dispatcher asks for and makes
+// the delivery due right away, so that every attempt can be made without
+// waiting out the backoff
+type rescheduling struct {
+	storage.Interface
+	webhooks *schedule
+}
+
+func (s *rescheduling) Webhook() storage.WebhookStorage {
+	return s.webhooks
+}
+
+type schedule struct {
+	storage.WebhookStorage
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (s *schedule) RecordAttempt(ctx context.Context, attempt *model.WebhookAttemptDTO, status string, nextAttemptAt *time.Time) error {
+	if nextAttemptAt != nil {
+		s.mu.Lock()
+		s.delays = append(s.delays, nextAttemptAt.Sub(attempt.AttemptedAt))
+		s.mu.Unlock()
+		now := time.Now()
+		nextAttemptAt = &now
+	}
+	return s.WebhookStorage.RecordAttempt(ctx, attempt, status, nextAttemptAt)
+}
+
+// received is a request that reached the receiver of the webhook
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver answers every webhook request with the next of statuses, the last
+// one is repeated
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []received
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+	r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+	w.WriteHeader(status)
+}
+
+// fixture is a project with a webhook on the receiver and one queued delivery
+type fixture struct {
+	t          *testing.T
+	ctx        context.Context
+	store      *rescheduling
+	dispatcher *Dispatcher
+	receiver   *receiver
+	webhook    model.WebhookDTO
+	delivery   model.WebhookDeliveryDTO
+}
+
+func newFixture(t *testing.T, cfg *config.Webhooks, statuses ...int) *fixture {
+	t.Helper()
+	mem := memory.New(zap.NewNop())
+	store := &rescheduling{Interface: mem, webhooks: &schedule{WebhookStorage: mem.Webhook()}}
+	rcv := &receiver{statuses: statuses}
+	srv := httptest.NewServer(rcv)
+	t.Cleanup(srv.Close)
+
+	user := model.UserDTO{ID: uuid.New(), Login: "owner@example.com", Password: "secret"}
+	if err := mem.User().Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	ctx := storage.WithTenant(context.Background(), user.ID)
+	project := model.ProjectDTO{ID: uuid.New(), Name: "Board", CreatedBy: user.ID}
+	template, _ := model.GetBuiltinTemplate(model.BuiltinTemplates[0].ID)
+	if err := mem.Project().Create(ctx, &project, template.ColumnsFor(project.ID)); err != nil {
+		t.Fatal(err)
+	}
+	webhook := model.WebhookDTO{ID: uuid.New(), ProjectID: project.ID, URL: srv.URL, Secret: testSecret, CreatedBy: user.ID}
+	if err := mem.Webhook().Create(ctx, &webhook); err != nil {
+		t.Fatal(err)
+	}
+	todo := model.TodoDTO{ID: uuid.New(), Name: "Ship it", ProjectID: project.ID, CreatedBy: user.ID, Column: "To do"}
+	if err := mem.Todo().Create(ctx, &todo, false); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, _, err := mem.Webhook().GetDeliveries(ctx, webhook.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries queued for a new todo, want 1", len(deliveries))
+	}
+
+	d := New(store, &config.Config{Webhooks: cfg}, zap.NewNop())
+	return &fixture{
+		t:          t,
+		ctx:        ctx,
+		store:      store,
+		dispatcher: d,
+		receiver:   rcv,
+		webhook:    webhook,
+		delivery:   deliveries[0],
+	}
+}
+
+// deliverDue runs one batch and checks how many deliveries it claimed
+func (f *fixture) deliverDue(want int) {
+	f.t.Helper()
+	n, err := f.dispatcher.DeliverDue(context.Background())
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	if n != want {
+		f.t.Fatalf("got %d deliveries claimed, want %d", n, want)
+	}
+}
+
+// stored returns the delivery as the outbox keeps it
+func (f *fixture) stored() *model.WebhookDeliveryDTO {
+	f.t.Helper()
+	delivery, err := f.store.Webhook().GetDelivery(f.ctx, f.delivery.ID, f.webhook.ID)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return delivery
+}
+
+func testConfig() *config.Webhooks {
+	return &config.Webhooks{
+		TimeoutSeconds:    5,
+		BatchSize:         10,
+		MaxAttempts:       4,
+		BackoffSeconds:    30,
+		MaxBackoffMinutes: 1,
+		// the receiver listens on the loopback
+		AllowPrivateTargets: true,
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	f := newFixture(t, testConfig(), http.StatusNoContent)
+	f.deliverDue(1)
+
+	if len(f.receiver.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(f.receiver.requests))
+	}
+	req := f.receiver.requests[0]
+	if got := req.header.Get(HeaderDelivery); got != f.delivery.ID.String() {
+		t.Errorf("got %s %q, want %q", HeaderDelivery, got, f.delivery.ID)
+	}
+	if got := req.header.Get(HeaderEvent); got != f.delivery.Event {
+		t.Errorf("got %s %q, want %q", HeaderEvent, got, f.delivery.Event)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", got)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Errorf("got %s %q, want the time of the attempt", HeaderTimestamp, req.header.Get(HeaderTimestamp))
+	}
+	signature := req.header.Get(HeaderSignature)
+	if !Verify(testSecret, timestamp, req.body, signature) {
+		t.Errorf("got %s %q, want the HMAC of the timestamp and the body", HeaderSignature, signature)
+	}
+	if Verify("another-secret", timestamp, req.body, signature) {
+		t.Error("signature verifies with another secret")
+	}
+	var payload model.WebhookPayload
+	if err = json.Unmarshal(req.body, &payload); err != nil || payload.Event != f.delivery.Event {
+		t.Errorf("got body %s, want the payload of event %q", req.body, f.delivery.Event)
+	}
+
+	delivery := f.stored()
+	if delivery.Status != model.WebhookDelivered || delivery.Attempts != 1 || delivery.DeliveredAt == nil || delivery.NextAttemptAt != nil {
+		t.Fatalf("got delivery %+v, want it delivered on the first attempt", delivery)
+	}
+	if len(delivery.History) != 1 || *delivery.History[0].StatusCode != http.StatusNoContent || delivery.History[0].Error != "" {
+		t.Fatalf("got attempts %+v, want one answered with 204", delivery.History)
+	}
+	f.deliverDue(0)
+}
+
+func TestRetryThenDeliver(t *testing.T) {
+	f := newFixture(t, testConfig(), http.StatusBadGateway, http.StatusOK)
+	f.deliverDue(1)
+
+	delivery := f.stored()
+	if delivery.Status != model.WebhookPending || delivery.Attempts != 1 || *delivery.LastStatusCode != http.StatusBadGateway {
+		t.Fatalf("got delivery %+v, want it pending after a 502", delivery)
+	}
+	f.deliverDue(1)
+
+	delivery = f.stored()
+	if delivery.Status != model.WebhookDelivered || delivery.Attempts != 2 || *delivery.LastStatusCode != http.StatusOK || delivery.LastError != "" {
+		t.Fatalf("got delivery %+v, want it delivered on the second attempt", delivery)
+	}
+	// a retry is signed again, with the time of its attempt
+	for i, req := range f.receiver.requests {
+		timestamp, _ := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+		if !Verify(testSecret, timestamp, req.body, req.header.Get(HeaderSignature)) {
+			t.Errorf("attempt %d is not signed", i+1)
+		}
+	}
+}
+
+func TestGiveUpAfterMaxAttempts(t *testing.T) {
+	cfg := testConfig()
+	f := newFixture(t, cfg, http.StatusInternalServerError)
+	for range cfg.MaxAttempts {
+		f.deliverDue(1)
+	}
+	f.deliverDue(0)
+
+	if len(f.receiver.requests) != cfg.MaxAttempts {
+		t.Fatalf("got %d requests, want %d", len(f.receiver.requests), cfg.MaxAttempts)
+	}
+	// the backoff doubles up to its limit, the last attempt is not retried
+	want := []time.Duration{30 * time.Second, time.Minute, time.Minute}
+	delays := f.store.webhooks.delays
+	if len(delays) != len(want) {
+		t.Fatalf("got %d retries scheduled, want %d", len(delays), len(want))
+	}
+	for i, delay := range delays {
+		if delay < want[i] || delay > want[i]+5*time.Second {
+			t.Errorf("retry %d scheduled after %s, want %s", i+1, delay, want[i])
+		}
+	}
+
+	delivery := f.stored()
+	if delivery.Status != model.WebhookFailed || delivery.Attempts != cfg.MaxAttempts || delivery.NextAttemptAt != nil || delivery.DeliveredAt != nil {
+		t.Fatalf("got delivery %+v, want it failed after %d attempts", delivery, cfg.MaxAttempts)
+	}
+	if len(delivery.History) != cfg.MaxAttempts {
+		t.Fatalf("got %d attempts recorded, want %d", len(delivery.History), cfg.MaxAttempts)
+	}
+	for i, attempt := range delivery.History {
+		if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusInternalServerError || attempt.Error == "" {
+			t.Errorf("got attempt %d %+v, want it failed with 500", i+1, attempt)
+		}
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	f := newFixture(t, testConfig(), http.StatusOK)
+	f.dispatcher.Client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, io.ErrUnexpectedEOF
+	})}
+	f.deliverDue(1)
+
+	delivery := f.stored()
+	if delivery.Status != model.WebhookPending || delivery.LastStatusCode != nil || delivery.LastError == "" {
+		t.Fatalf("got delivery %+v, want it pending with the error and no status code", delivery)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
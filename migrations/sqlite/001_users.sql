@@ -0,0 +1,10 @@
+CREATE TABLE users
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "login" TEXT NOT NULL UNIQUE,
+    "encrypted_password" TEXT NOT NULL
+);
+
+---- create above / drop below ----
+
+DROP TABLE users;
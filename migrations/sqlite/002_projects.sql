@@ -0,0 +1,50 @@
+-- versions are bumped by triggers when any other column of the row changes,
+-- the version is the ETag of the row
+CREATE TABLE projects
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL,
+    "created_by" TEXT NOT NULL,
+    "deleted_at" TIMESTAMP,
+    "archived_at" TIMESTAMP,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    FOREIGN KEY ("created_by") REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX projects_created_by_index ON projects(created_by);
+
+CREATE TRIGGER projects_bump_version AFTER UPDATE ON projects
+    FOR EACH ROW WHEN OLD.version = NEW.version AND (
+        OLD.name IS NOT NEW.name OR OLD.created_by IS NOT NEW.created_by OR
+        OLD.deleted_at IS NOT NEW.deleted_at OR OLD.archived_at IS NOT NEW.archived_at)
+BEGIN
+    UPDATE projects SET version = OLD.version + 1 WHERE id = OLD.id;
+END;
+
+-- unlike Postgres, SQLite checks unique constraints row by row, so the order
+-- of columns is kept unique by the storage alone
+CREATE TABLE project_columns
+(
+    "id" TEXT NOT NULL UNIQUE,
+    "project_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "order" INTEGER NOT NULL,
+    "wip_limit" INTEGER CHECK (wip_limit > 0),
+    "is_done" BOOLEAN NOT NULL DEFAULT FALSE,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER project_columns_bump_version AFTER UPDATE ON project_columns
+    FOR EACH ROW WHEN OLD.version = NEW.version AND (
+        OLD.project_id IS NOT NEW.project_id OR OLD.name IS NOT NEW.name OR OLD."order" IS NOT NEW."order" OR
+        OLD.wip_limit IS NOT NEW.wip_limit OR OLD.is_done IS NOT NEW.is_done)
+BEGIN
+    UPDATE project_columns SET version = OLD.version + 1 WHERE id = OLD.id;
+END;
+
+---- create above / drop below ----
+
+DROP TABLE project_columns;
+DROP TABLE projects;
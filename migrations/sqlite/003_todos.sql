@@ -0,0 +1,116 @@
+-- labels is a JSON array of strings
+CREATE TABLE todos
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL UNIQUE,
+    "description" TEXT NOT NULL,
+    "is_completed" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_by" TEXT NOT NULL,
+    "project_id" TEXT NOT NULL,
+    "column_id" TEXT NOT NULL,
+    "position" TEXT NOT NULL DEFAULT '',
+    "recurrence" TEXT NOT NULL DEFAULT '',
+    "due_date" DATE,
+    "spawned_from" TEXT UNIQUE,
+    "deleted_at" TIMESTAMP,
+    "archived_at" TIMESTAMP,
+    "labels" TEXT NOT NULL DEFAULT '[]',
+    "assignee_id" TEXT,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (column_id) REFERENCES project_columns(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (spawned_from) REFERENCES todos(id) ON DELETE SET NULL,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX todos_created_by_index ON todos(created_by);
+CREATE INDEX todos_column_position_index ON todos(column_id, "position");
+
+CREATE TRIGGER todos_bump_version AFTER UPDATE ON todos
+    FOR EACH ROW WHEN OLD.version = NEW.version AND (
+        OLD.name IS NOT NEW.name OR OLD.description IS NOT NEW.description OR
+        OLD.is_completed IS NOT NEW.is_completed OR OLD.created_by IS NOT NEW.created_by OR
+        OLD.project_id IS NOT NEW.project_id OR OLD.column_id IS NOT NEW.column_id OR
+        OLD."position" IS NOT NEW."position" OR OLD.recurrence IS NOT NEW.recurrence OR
+        OLD.due_date IS NOT NEW.due_date OR OLD.spawned_from IS NOT NEW.spawned_from OR
+        OLD.deleted_at IS NOT NEW.deleted_at OR OLD.archived_at IS NOT NEW.archived_at OR
+        OLD.labels IS NOT NEW.labels OR OLD.assignee_id IS NOT NEW.assignee_id)
+BEGIN
+    UPDATE todos SET version = OLD.version + 1 WHERE id = OLD.id;
+END;
+
+CREATE TABLE todo_dependencies
+(
+    "blocker_id" TEXT NOT NULL,
+    "blocked_id" TEXT NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id),
+    FOREIGN KEY (blocker_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX todo_dependencies_blocked_id_index ON todo_dependencies(blocked_id);
+
+CREATE TABLE todo_checklist_items
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "todo_id" TEXT NOT NULL,
+    "text" TEXT NOT NULL,
+    "is_done" BOOLEAN NOT NULL DEFAULT FALSE,
+    "position" TEXT NOT NULL,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX todo_checklist_items_todo_id_index ON todo_checklist_items(todo_id, "position");
+
+CREATE TABLE todo_comments
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "todo_id" TEXT NOT NULL,
+    "author_id" TEXT NOT NULL,
+    "body" TEXT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NOT NULL,
+    "deleted_at" TIMESTAMP,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX todo_comments_todo_id_index ON todo_comments(todo_id, created_at);
+
+CREATE TABLE comment_mentions
+(
+    "comment_id" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES todo_comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX comment_mentions_user_id_index ON comment_mentions(user_id);
+
+CREATE TABLE todo_attachments
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "todo_id" TEXT NOT NULL,
+    "uploaded_by" TEXT NOT NULL,
+    "file_name" TEXT NOT NULL,
+    "content_type" TEXT NOT NULL,
+    "size" INTEGER NOT NULL,
+    "storage_key" TEXT NOT NULL UNIQUE,
+    "created_at" TIMESTAMP NOT NULL,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX todo_attachments_todo_id_index ON todo_attachments(todo_id, created_at);
+
+---- create above / drop below ----
+
+DROP TABLE todo_attachments;
+DROP TABLE comment_mentions;
+DROP TABLE todo_comments;
+DROP TABLE todo_checklist_items;
+DROP TABLE todo_dependencies;
+DROP TABLE todos;
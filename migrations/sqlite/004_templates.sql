@@ -0,0 +1,14 @@
+-- columns is a JSON array of the template columns
+CREATE TABLE project_templates
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL,
+    "created_by" TEXT NOT NULL,
+    "columns" TEXT NOT NULL,
+    UNIQUE (created_by, name),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+---- create above / drop below ----
+
+DROP TABLE project_templates;
@@ -0,0 +1,37 @@
+-- activity_log has no foreign keys, records outlive the entities they
+-- describe. changes is a JSON object
+CREATE TABLE activity_log
+(
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "project_id" TEXT NOT NULL,
+    "actor_id" TEXT,
+    "entity" TEXT NOT NULL,
+    "entity_id" TEXT NOT NULL,
+    "action" TEXT NOT NULL,
+    "changes" TEXT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL
+);
+
+CREATE INDEX activity_log_project_id_index ON activity_log(project_id, id DESC);
+
+-- status is NULL while the first request with the key is running
+CREATE TABLE idempotency_keys
+(
+    "user_id" TEXT NOT NULL,
+    "key" TEXT NOT NULL,
+    "fingerprint" TEXT NOT NULL,
+    "status" INTEGER,
+    "content_type" TEXT NOT NULL DEFAULT '',
+    "body" BLOB,
+    "created_at" TIMESTAMP NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys(expires_at);
+
+---- create above / drop below ----
+
+DROP TABLE idempotency_keys;
+DROP TABLE activity_log;
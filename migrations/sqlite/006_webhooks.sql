@@ -0,0 +1,57 @@
+-- webhook_deliveries is the outbox of webhooks: deliveries are added in the
+-- transaction of the change and sent by the dispatcher. A pending delivery
+-- is due at next_attempt_at, webhook_delivery_attempts logs every attempt.
+-- events is a JSON array of event names
+CREATE TABLE webhooks
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "project_id" TEXT NOT NULL,
+    "url" TEXT NOT NULL,
+    "secret" TEXT NOT NULL,
+    "events" TEXT NOT NULL DEFAULT '[]',
+    "created_by" TEXT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhooks_project_id_index ON webhooks(project_id);
+
+CREATE TABLE webhook_deliveries
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "webhook_id" TEXT NOT NULL,
+    "activity_id" INTEGER NOT NULL,
+    "event" TEXT NOT NULL,
+    "payload" TEXT NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'pending',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP,
+    "last_status_code" INTEGER,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL,
+    "delivered_at" TIMESTAMP,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_due_index ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_id_index ON webhook_deliveries(webhook_id, created_at DESC);
+
+CREATE TABLE webhook_delivery_attempts
+(
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "delivery_id" TEXT NOT NULL,
+    "attempted_at" TIMESTAMP NOT NULL,
+    "status_code" INTEGER,
+    "error" TEXT NOT NULL DEFAULT '',
+    "duration_ms" INTEGER NOT NULL,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_id_index ON webhook_delivery_attempts(delivery_id, id);
+
+---- create above / drop below ----
+
+DROP TABLE webhook_delivery_attempts;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,26 @@
+-- outbox_events keeps domain events written in the transaction of the
+-- change until the relay publishes them. Events have no foreign keys, so
+-- that they outlive purged projects. changes and state are JSON objects
+CREATE TABLE outbox_events
+(
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "type" TEXT NOT NULL,
+    "aggregate_type" TEXT NOT NULL,
+    "aggregate_id" TEXT NOT NULL,
+    "project_id" TEXT NOT NULL,
+    "actor_id" TEXT,
+    "changes" TEXT NOT NULL DEFAULT '{}',
+    "state" TEXT,
+    "occurred_at" TIMESTAMP NOT NULL,
+    "published_at" TIMESTAMP,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP NOT NULL,
+    "last_error" TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX outbox_events_unpublished_index ON outbox_events(id) WHERE published_at IS NULL;
+CREATE INDEX outbox_events_published_at_index ON outbox_events(published_at) WHERE published_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP TABLE outbox_events;
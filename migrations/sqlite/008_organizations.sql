@@ -0,0 +1,44 @@
+-- projects belong to organizations. Every user owns a personal organization
+-- with the id of the user, existing projects move to the one of their creator
+CREATE TABLE organizations
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL,
+    "created_by" TEXT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    FOREIGN KEY ("created_by") REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE organization_members
+(
+    "org_id" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "role" TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    "joined_at" TIMESTAMP NOT NULL,
+    PRIMARY KEY (org_id, user_id),
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX organization_members_user_id_index ON organization_members(user_id);
+
+INSERT INTO organizations (id, name, created_by, created_at)
+SELECT id, login, id, strftime('%Y-%m-%d %H:%M:%S.000000000+00:00', 'now') FROM users;
+
+INSERT INTO organization_members (org_id, user_id, role, joined_at)
+SELECT id, id, 'owner', created_at FROM organizations;
+
+-- SQLite can not add a NOT NULL column without a default, the storage
+-- always sets it
+ALTER TABLE projects ADD COLUMN "org_id" TEXT REFERENCES organizations(id) ON DELETE CASCADE;
+
+UPDATE projects SET org_id = created_by;
+
+CREATE INDEX projects_org_id_index ON projects(org_id);
+
+---- create above / drop below ----
+
+DROP INDEX projects_org_id_index;
+ALTER TABLE projects DROP COLUMN org_id;
+DROP TABLE organization_members;
+DROP TABLE organizations;
@@ -0,0 +1,49 @@
+-- idempotency keys are scoped to the organization too. SQLite can not change
+-- the primary key of a table, so it is copied, existing keys were sent in the
+-- personal organization of their user
+CREATE TABLE idempotency_keys_by_org
+(
+    "user_id" TEXT NOT NULL,
+    "org_id" TEXT NOT NULL,
+    "key" TEXT NOT NULL,
+    "fingerprint" TEXT NOT NULL,
+    "status" INTEGER,
+    "content_type" TEXT NOT NULL DEFAULT '',
+    "body" BLOB,
+    "created_at" TIMESTAMP NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, org_id, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO idempotency_keys_by_org (user_id, org_id, key, fingerprint, status, content_type, body, created_at, expires_at)
+SELECT user_id, user_id, key, fingerprint, status, content_type, body, created_at, expires_at FROM idempotency_keys;
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_by_org RENAME TO idempotency_keys;
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys(expires_at);
+
+---- create above / drop below ----
+
+-- keys of other organizations are dropped, they would collide
+CREATE TABLE idempotency_keys_by_user
+(
+    "user_id" TEXT NOT NULL,
+    "key" TEXT NOT NULL,
+    "fingerprint" TEXT NOT NULL,
+    "status" INTEGER,
+    "content_type" TEXT NOT NULL DEFAULT '',
+    "body" BLOB,
+    "created_at" TIMESTAMP NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO idempotency_keys_by_user (user_id, key, fingerprint, status, content_type, body, created_at, expires_at)
+SELECT user_id, key, fingerprint, status, content_type, body, created_at, expires_at FROM idempotency_keys
+WHERE org_id = user_id;
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_by_user RENAME TO idempotency_keys;
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys(expires_at);
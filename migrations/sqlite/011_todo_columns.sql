@@ -0,0 +1,112 @@
+-- a todo that is archived or in the trash when its column is deleted loses
+-- its column, last_column keeps the name to put it back into. SQLite can not
+-- drop the constraint of a column, so the table is rebuilt like in 010
+
+CREATE TABLE todos_rebuilt
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL,
+    "description" TEXT NOT NULL,
+    "is_completed" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_by" TEXT NOT NULL,
+    "project_id" TEXT NOT NULL,
+    "column_id" TEXT,
+    "position" TEXT NOT NULL DEFAULT '',
+    "recurrence" TEXT NOT NULL DEFAULT '',
+    "due_date" DATE,
+    "spawned_from" TEXT UNIQUE,
+    "deleted_at" TIMESTAMP,
+    "archived_at" TIMESTAMP,
+    "labels" TEXT NOT NULL DEFAULT '[]',
+    "assignee_id" TEXT,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "last_column" TEXT,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (column_id) REFERENCES project_columns(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (spawned_from) REFERENCES todos(id) ON DELETE SET NULL,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+INSERT INTO todos_rebuilt (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date, spawned_from, deleted_at, archived_at, labels, assignee_id, version)
+SELECT id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date, spawned_from, deleted_at, archived_at, labels, assignee_id, version FROM todos;
+
+DROP TABLE todos;
+ALTER TABLE todos_rebuilt RENAME TO todos;
+
+CREATE INDEX todos_created_by_index ON todos(created_by);
+CREATE INDEX todos_column_position_index ON todos(column_id, "position");
+CREATE UNIQUE INDEX todos_project_id_name_index ON todos(project_id, name);
+
+CREATE TRIGGER todos_bump_version AFTER UPDATE ON todos
+    FOR EACH ROW WHEN OLD.version = NEW.version AND (
+        OLD.name IS NOT NEW.name OR OLD.description IS NOT NEW.description OR
+        OLD.is_completed IS NOT NEW.is_completed OR OLD.created_by IS NOT NEW.created_by OR
+        OLD.project_id IS NOT NEW.project_id OR OLD.column_id IS NOT NEW.column_id OR
+        OLD."position" IS NOT NEW."position" OR OLD.recurrence IS NOT NEW.recurrence OR
+        OLD.due_date IS NOT NEW.due_date OR OLD.spawned_from IS NOT NEW.spawned_from OR
+        OLD.deleted_at IS NOT NEW.deleted_at OR OLD.archived_at IS NOT NEW.archived_at OR
+        OLD.labels IS NOT NEW.labels OR OLD.assignee_id IS NOT NEW.assignee_id OR
+        OLD.last_column IS NOT NEW.last_column)
+BEGIN
+    UPDATE todos SET version = OLD.version + 1 WHERE id = OLD.id;
+END;
+
+---- create above / drop below ----
+
+-- detached todos go back to a column of their project, those of projects
+-- without columns are lost
+
+CREATE TABLE todos_rebuilt
+(
+    "id" TEXT PRIMARY KEY NOT NULL,
+    "name" TEXT NOT NULL,
+    "description" TEXT NOT NULL,
+    "is_completed" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_by" TEXT NOT NULL,
+    "project_id" TEXT NOT NULL,
+    "column_id" TEXT NOT NULL,
+    "position" TEXT NOT NULL DEFAULT '',
+    "recurrence" TEXT NOT NULL DEFAULT '',
+    "due_date" DATE,
+    "spawned_from" TEXT UNIQUE,
+    "deleted_at" TIMESTAMP,
+    "archived_at" TIMESTAMP,
+    "labels" TEXT NOT NULL DEFAULT '[]',
+    "assignee_id" TEXT,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (column_id) REFERENCES project_columns(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (spawned_from) REFERENCES todos(id) ON DELETE SET NULL,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+INSERT INTO todos_rebuilt (id, name, description, is_completed, created_by, project_id, column_id, "position", recurrence, due_date, spawned_from, deleted_at, archived_at, labels, assignee_id, version)
+SELECT id, name, description, is_completed, created_by, project_id,
+       COALESCE(column_id,
+                (SELECT c.id FROM project_columns AS c WHERE c.project_id = todos.project_id AND c.name = todos.last_column),
+                (SELECT c.id FROM project_columns AS c WHERE c.project_id = todos.project_id ORDER BY c."order" LIMIT 1)),
+       "position", recurrence, due_date, spawned_from, deleted_at, archived_at, labels, assignee_id, version
+FROM todos
+WHERE column_id IS NOT NULL OR EXISTS (SELECT 1 FROM project_columns AS c WHERE c.project_id = todos.project_id);
+
+DROP TABLE todos;
+ALTER TABLE todos_rebuilt RENAME TO todos;
+
+CREATE INDEX todos_created_by_index ON todos(created_by);
+CREATE INDEX todos_column_position_index ON todos(column_id, "position");
+CREATE UNIQUE INDEX todos_project_id_name_index ON todos(project_id, name);
+
+CREATE TRIGGER todos_bump_version AFTER UPDATE ON todos
+    FOR EACH ROW WHEN OLD.version = NEW.version AND (
+        OLD.name IS NOT NEW.name OR OLD.description IS NOT NEW.description OR
+        OLD.is_completed IS NOT NEW.is_completed OR OLD.created_by IS NOT NEW.created_by OR
+        OLD.project_id IS NOT NEW.project_id OR OLD.column_id IS NOT NEW.column_id OR
+        OLD."position" IS NOT NEW."position" OR OLD.recurrence IS NOT NEW.recurrence OR
+        OLD.due_date IS NOT NEW.due_date OR OLD.spawned_from IS NOT NEW.spawned_from OR
+        OLD.deleted_at IS NOT NEW.deleted_at OR OLD.archived_at IS NOT NEW.archived_at OR
+        OLD.labels IS NOT NEW.labels OR OLD.assignee_id IS NOT NEW.assignee_id)
+BEGIN
+    UPDATE todos SET version = OLD.version + 1 WHERE id = OLD.id;
+END;
@@ -0,0 +1,10 @@
+// Package sqlite holds the migrations of the SQLite storage, they create the
+// schema the pgx storage creates on its own
+package sqlite
+
+import (
+	"embed"
+)
+
+//go:embed *.sql
+var Migrations embed.FS
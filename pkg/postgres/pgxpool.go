@@ -18,10 +18,39 @@ const (
 
 // New opens new postgres connection, configures it and return prepared client.
 func New(cfg *config.Config, log *zap.Logger) (*pgxpool.Pool, error) {
-	var pool *pgxpool.Pool
 	log.Info("initializing postgres client")
 
-	c, err := pgxpool.ParseConfig(cfg.Postgres.GetURI())
+	pool, err := newPool(cfg.Postgres.GetURI())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("created postgres client")
+	return pool, nil
+}
+
+// NewReplicas opens a pool for every read replica configured in cfg, none
+// when no replica is configured.
+func NewReplicas(cfg *config.Config, log *zap.Logger) ([]*pgxpool.Pool, error) {
+	uris := cfg.Postgres.GetReplicaURIs()
+	replicas := make([]*pgxpool.Pool, 0, len(uris))
+	for i, uri := range uris {
+		log.Info("initializing postgres replica client", zap.Int("replica", i))
+
+		pool, err := newPool(uri)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, pool)
+	}
+	return replicas, nil
+}
+
+func newPool(uri string) (*pgxpool.Pool, error) {
+	c, err := pgxpool.ParseConfig(uri)
 	if err != nil {
 		return nil, fmt.Errorf("error while parsing db uri: %w", err)
 	}
@@ -38,11 +67,9 @@ func New(cfg *config.Config, log *zap.Logger) (*pgxpool.Pool, error) {
 		return nil
 	}
 
-	pool, err = pgxpool.NewWithConfig(context.Background(), c)
+	pool, err := pgxpool.NewWithConfig(context.Background(), c)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: init pgxpool: %w", err)
 	}
-
-	log.Info("created postgres client")
 	return pool, nil
 }
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+
+	"github.com/todo-enjoers/backend_v1/internal/config"
+)
+
+// New opens the sqlite database file configured in cfg, creating it and its
+// directory when missing.
+func New(cfg *config.Config, log *zap.Logger) (*sql.DB, error) {
+	log.Info("initializing sqlite client")
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Storage.SQLite.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("sqlite: create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", cfg.Storage.SQLite.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: open database: %w", err)
+	}
+	if err = db.PingContext(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite: ping database: %w", err)
+	}
+
+	log.Info("created sqlite client")
+	return db, nil
+}